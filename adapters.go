@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// CronSchedule is the schedule interface used by github.com/robfig/cron and
+// its forks, which is the same as Schedule.
+type CronSchedule = Schedule
+
+// QuartzTrigger is the trigger interface used by github.com/reugn/go-quartz.
+// NextFireTime returns the next fire time, in Unix nanoseconds, after prev.
+type QuartzTrigger interface {
+	NextFireTime(prev int64) (int64, error)
+	Description() string
+}
+
+var errNoNextRun = errors.New("schedule has no next run")
+
+type quartzSchedule struct {
+	t QuartzTrigger
+}
+
+func (q quartzSchedule) nextRun(now time.Time) (time.Duration, error) {
+	date, err := q.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+// Next implements Schedule.
+func (q quartzSchedule) Next(after time.Time) time.Time {
+	date, _ := q.next(after)
+	return date
+}
+
+func (q quartzSchedule) next(after time.Time) (time.Time, error) {
+	fire, err := q.t.NextFireTime(after.UnixNano())
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, fire), nil
+}
+
+func (q quartzSchedule) description() string {
+	return q.t.Description()
+}
+
+// FromCron creates a job driven by a schedule from the robfig/cron ecosystem,
+// e.g. the result of cron.ParseStandard. It is the same as On.
+func FromCron(s CronSchedule) *Job {
+	return On(s)
+}
+
+// FromQuartz creates a job driven by a go-quartz style trigger. The job stops
+// once the trigger returns an error.
+func FromQuartz(t QuartzTrigger) *Job {
+	if t == nil {
+		return &Job{err: errors.New("nil schedule")}
+	}
+	return &Job{schedule: quartzSchedule{t: t}}
+}
+
+// Next implements CronSchedule, so the job's schedule can drive a robfig/cron
+// runner. It returns the zero time if the job has no valid schedule.
+func (j *Job) Next(t time.Time) time.Time {
+	schedule := j.current()
+	if j.err != nil || schedule == nil {
+		return time.Time{}
+	}
+	date, err := schedule.next(t)
+	if err == nil {
+		date, err = j.offHoliday(schedule, date)
+	}
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// NextFireTime implements QuartzTrigger, so the job's schedule can be used as a
+// go-quartz trigger.
+func (j *Job) NextFireTime(prev int64) (int64, error) {
+	if j.err != nil {
+		return 0, j.err
+	}
+	schedule := j.current()
+	if schedule == nil {
+		return 0, errNoNextRun
+	}
+	date, err := schedule.next(time.Unix(0, prev))
+	if err == nil {
+		date, err = j.offHoliday(schedule, date)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return date.UnixNano(), nil
+}
+
+// Description implements QuartzTrigger.
+func (j *Job) Description() string {
+	j.RLock()
+	defer j.RUnlock()
+
+	return j.describe()
+}
+
+// describe works like Description for callers holding the job's lock.
+func (j *Job) describe() string {
+	if j.schedule == nil {
+		return ""
+	}
+	return j.schedule.description()
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type everyMinute struct{}
+
+func (everyMinute) Next(t time.Time) time.Time {
+	return t.Truncate(time.Minute).Add(time.Minute)
+}
+
+type never struct{}
+
+func (never) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+type quartzEvery struct {
+	d time.Duration
+}
+
+func (q quartzEvery) NextFireTime(prev int64) (int64, error) {
+	return prev + int64(q.d), nil
+}
+
+func (q quartzEvery) Description() string {
+	return "quartz every " + q.d.String()
+}
+
+type quartzDone struct{}
+
+func (quartzDone) NextFireTime(int64) (int64, error) {
+	return 0, errors.New("trigger expired")
+}
+
+func (quartzDone) Description() string {
+	return "expired"
+}
+
+func TestFromCron(t *testing.T) {
+	job, err := FromCron(everyMinute{}).Run(test)
+	assert.Nil(t, err)
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual)
+	assert.Equal(t, 0, runTime.Second())
+	assert.True(t, actual <= time.Minute)
+}
+
+func TestFromCronNoNextRun(t *testing.T) {
+	job, err := FromCron(never{}).Run(test)
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+}
+
+func TestFromQuartz(t *testing.T) {
+	job, err := FromQuartz(quartzEvery{d: time.Hour}).Run(test)
+	assert.Nil(t, err)
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	assert.InDelta(t, float64(time.Hour), float64(actual), float64(time.Second))
+	assert.Equal(t, "quartz every 1h0m0s", job.Description())
+}
+
+func TestFromQuartzError(t *testing.T) {
+	job, err := FromQuartz(quartzDone{}).Run(test)
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+}
+
+func TestFromNil(t *testing.T) {
+	_, err := FromCron(nil).Run(test)
+	assert.NotNil(t, err)
+	_, err = FromQuartz(nil).Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestJobAsCronSchedule(t *testing.T) {
+	var s CronSchedule = Every().Monday().At("08:30")
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2015, 6, 8, 8, 30, 0, 0, time.Local), s.Next(from))
+	// June 8, 2015 was a Monday: a run later that day comes first.
+	monday := time.Date(2015, 6, 8, 5, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2015, 6, 8, 8, 30, 0, 0, time.Local), s.Next(monday))
+	assert.Equal(t, time.Date(2015, 6, 15, 8, 30, 0, 0, time.Local), s.Next(monday.Add(3*time.Hour+30*time.Minute)))
+
+	s = Every(90).Minutes()
+	assert.Equal(t, from.Add(90*time.Minute), s.Next(from))
+
+	s = Every(1).Day()
+	assert.True(t, s.Next(from).IsZero())
+}
+
+func TestJobAsQuartzTrigger(t *testing.T) {
+	var q QuartzTrigger = Every().Day().At("10:00")
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local)
+	next, err := q.NextFireTime(from.UnixNano())
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2015, 6, 4, 10, 0, 0, 0, time.Local).UnixNano(), next)
+	assert.Equal(t, "every day at 10:00:00", q.Description())
+}
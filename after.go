@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// delay runs once, d after the job is started. See After.
+type delay struct {
+	d time.Duration
+	// at is when the run is due, set when the job is started. Next may read
+	// it from any goroutine.
+	at time.Time
+	sync.Mutex
+}
+
+// After defines a job that runs once, d after it is started with Run, and then
+// stops and leaves its scheduler, if any: After(10 * time.Minute).Run(fn). A
+// job stopped before it is due never runs.
+func After(d time.Duration) *Job {
+	if d < 0 {
+		return &Job{err: errors.New("bad delay")}
+	}
+	return &Job{schedule: &delay{d: d}}
+}
+
+func (d *delay) nextRun(now time.Time) (time.Duration, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.at.IsZero() {
+		d.at = now.Add(d.d)
+		return d.d, nil
+	}
+	if now.Before(d.at) {
+		return d.at.Sub(now), nil
+	}
+	return 0, errNoNextRun
+}
+
+// next assumes a job that is not started yet starts at after.
+func (d *delay) next(after time.Time) (time.Time, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	if d.at.IsZero() {
+		return after.Add(d.d), nil
+	}
+	if after.Before(d.at) {
+		return d.at, nil
+	}
+	return time.Time{}, errNoNextRun
+}
+
+// Next implements Schedule.
+func (d *delay) Next(after time.Time) time.Time {
+	date, _ := d.next(after)
+	return date
+}
+
+func (d *delay) description() string {
+	return "once after " + d.d.String()
+}
+
+// ends reports whether a job leaves its scheduler once its schedule has no
+// more runs, as those of After, Once and OnDates do.
+func ends(s scheduled) bool {
+	switch s.(type) {
+	case *delay, *once, dates:
+		return true
+	}
+	return false
+}
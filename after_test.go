@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAfter(t *testing.T) {
+	now := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	job := After(10 * time.Minute)
+	assert.Nil(t, job.err)
+	assert.Equal(t, now.Add(10*time.Minute), job.Next(now))
+	assert.Equal(t, "once after 10m0s", job.Description())
+	assert.Equal(t, "once after 10m", job.String())
+
+	next, err := job.schedule.nextRun(now)
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, next)
+	next, err = job.schedule.nextRun(now.Add(4 * time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 6*time.Minute, next, "an early run leaves the due one")
+	_, err = job.schedule.nextRun(now.Add(10 * time.Minute))
+	assert.Equal(t, errNoNextRun, err)
+	assert.True(t, job.Next(now.Add(10*time.Minute)).IsZero())
+
+	assert.NotNil(t, After(-time.Second).err)
+}
+
+func TestAfterRun(t *testing.T) {
+	s := NewScheduler()
+	runs := make(chan bool, 2)
+	job, err := s.After(50 * time.Millisecond).Run(func() { runs <- true })
+	assert.Nil(t, err)
+	assert.Equal(t, []*Job{job}, s.Jobs())
+	<-runs
+	for len(s.Jobs()) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-runs:
+		t.Fatal("ran twice")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	canceled, err := s.After(10 * time.Millisecond).Run(func() { runs <- true })
+	assert.Nil(t, err)
+	canceled.Stop()
+	select {
+	case <-runs:
+		t.Fatal("ran after Stop")
+	case <-time.After(30 * time.Millisecond):
+	}
+}
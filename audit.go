@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Audited actions, see AuditEntry.
+const (
+	AuditPause      = "pause"
+	AuditResume     = "resume"
+	AuditReschedule = "reschedule"
+	AuditTrigger    = "trigger"
+	AuditRemove     = "remove"
+)
+
+// AuditEntry records a change made to a job of a scheduler, see AuditSink.
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// Actor is who made the change, see WithActor. It is empty for changes
+	// made through the methods of Job, rather than those of Scheduler that
+	// take a context.
+	Actor string `json:"actor,omitempty"`
+	// Action is AuditPause, AuditResume, AuditReschedule, AuditTrigger or
+	// AuditRemove.
+	Action string `json:"action"`
+	// Job is the name of the job, and ID its stable ID, see WithID.
+	Job string `json:"job"`
+	ID  string `json:"id,omitempty"`
+	// Detail is the new schedule of a job rescheduled, or the tag of jobs
+	// removed with RemoveByTag.
+	Detail string `json:"detail,omitempty"`
+}
+
+// AuditSink records the changes made to a scheduler's jobs, see
+// WithAuditSink, e.g. to prove when and why automated jobs were changed.
+// Audit must be safe for concurrent use.
+type AuditSink interface {
+	Audit(entry AuditEntry) error
+}
+
+// WithAuditSink sets the sink that records every Pause, Resume, Reschedule,
+// Trigger and removal of the scheduler's jobs. Errors of the sink are
+// logged, see WithDefaultLogger.
+func (s *Scheduler) WithAuditSink(sink AuditSink) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.auditSink = sink
+	return s
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx that makes the changes done with it, e.g.
+// by PauseJob, on behalf of actor, such as the user of an admin API.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor set on ctx with WithActor, or "".
+func ActorFrom(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// audit records a change of the job in its scheduler's audit sink, if any.
+func (j *Job) audit(actor, action, detail string) {
+	if j.scheduler == nil {
+		return
+	}
+	s := j.scheduler
+	s.Lock()
+	sink := s.auditSink
+	s.Unlock()
+	if sink == nil {
+		return
+	}
+	j.RLock()
+	entry := AuditEntry{Time: j.now(), Actor: actor, Action: action, Job: j.name, ID: j.id, Detail: detail}
+	j.RUnlock()
+	if err := sink.Audit(entry); err != nil {
+		s.logError("audit failed", err)
+	}
+}
+
+// PauseJob pauses the scheduler's job with the given name, see Job.Pause, on
+// behalf of the actor of ctx, see WithActor.
+func (s *Scheduler) PauseJob(ctx context.Context, name string) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	j.pause()
+	j.audit(ActorFrom(ctx), AuditPause, "")
+	return nil
+}
+
+// ResumeJob resumes the scheduler's job with the given name, see Job.Resume,
+// on behalf of the actor of ctx.
+func (s *Scheduler) ResumeJob(ctx context.Context, name string) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	j.resume()
+	j.audit(ActorFrom(ctx), AuditResume, "")
+	return nil
+}
+
+// TriggerJob requests a run now of the scheduler's job with the given name,
+// see Job.Trigger, on behalf of the actor of ctx. It fails if a run was
+// already requested and not yet started.
+func (s *Scheduler) TriggerJob(ctx context.Context, name string) error {
+	return s.TriggerJobWith(ctx, name, nil)
+}
+
+// TriggerJobWith works like TriggerJob and passes payload to the run it
+// requests, see Job.TriggerWith.
+func (s *Scheduler) TriggerJobWith(ctx context.Context, name string, payload []byte) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	if !j.triggerWith(payload) {
+		return errors.New("run already requested")
+	}
+	j.audit(ActorFrom(ctx), AuditTrigger, "")
+	return nil
+}
+
+// RescheduleJob replaces the schedule of the scheduler's job with the given
+// name, see Job.Reschedule, on behalf of the actor of ctx.
+func (s *Scheduler) RescheduleJob(ctx context.Context, name string, schedule Schedule) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	if err := j.reschedule(schedule); err != nil {
+		return err
+	}
+	j.audit(ActorFrom(ctx), AuditReschedule, humanize(schedule))
+	return nil
+}
+
+// RemoveJob stops the scheduler's job with the given name and removes it from
+// the scheduler and its groups, on behalf of the actor of ctx.
+func (s *Scheduler) RemoveJob(ctx context.Context, name string) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	j.Stop()
+	s.unregister(j)
+	j.audit(ActorFrom(ctx), AuditRemove, "")
+	return nil
+}
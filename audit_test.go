@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryAudit struct {
+	entries []AuditEntry
+	sync.Mutex
+}
+
+func (m *memoryAudit) Audit(entry AuditEntry) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryAudit) actions() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	var actions []string
+	for _, e := range m.entries {
+		actions = append(actions, e.Actor+" "+e.Action+" "+e.Job+" "+e.Detail)
+	}
+	return actions
+}
+
+func TestAudit(t *testing.T) {
+	sink := &memoryAudit{}
+	s := NewScheduler().WithAuditSink(sink)
+	job, err := s.Every(1).Hours().NotImmediately().Named("report").Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	ctx := WithActor(context.Background(), "alice")
+	assert.Equal(t, "alice", ActorFrom(ctx))
+	assert.Nil(t, s.PauseJob(ctx, "report"))
+	assert.True(t, job.IsPaused())
+	assert.Nil(t, s.ResumeJob(ctx, "report"))
+	assert.Nil(t, s.RescheduleJob(ctx, "report", Every(2).Hours().NotImmediately()))
+	job.Pause()
+	assert.Nil(t, s.TriggerJob(ctx, "report"))
+	assert.Nil(t, s.RemoveJob(ctx, "report"))
+	assert.Nil(t, s.Job("report"))
+	assert.NotNil(t, s.PauseJob(ctx, "report"))
+
+	assert.Equal(t, []string{
+		"alice pause report ",
+		"alice resume report ",
+		"alice reschedule report every 2h",
+		" pause report ",
+		"alice trigger report ",
+		"alice remove report ",
+	}, sink.actions())
+}
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// backoff waits longer after each run, starting at initial and growing by
+// factor up to max. See Backoff.
+type backoff struct {
+	initial time.Duration
+	max     time.Duration
+	factor  float64
+	done    bool
+	// wait is the wait before the next run. Reset may change it from any
+	// goroutine while the scheduling goroutine reads it.
+	wait time.Duration
+	sync.Mutex
+}
+
+// Backoff defines a job that runs immediately and then waits initial, and
+// factor times longer after each run up to max: Backoff(time.Second,
+// time.Minute, 2) runs after 1s, 2s, 4s and so on, then every minute. It suits
+// jobs that poll until something happens, which call Reset when it does.
+// NotImmediately skips the first run.
+func Backoff(initial, max time.Duration, factor float64) *Job {
+	switch {
+	case initial <= 0:
+		return &Job{err: errors.New("cannot set backoff with 0")}
+	case max < initial:
+		return &Job{err: errors.New("backoff max below initial")}
+	case factor < 1:
+		return &Job{err: errors.New("bad backoff factor")}
+	}
+	return &Job{schedule: &backoff{initial: initial, max: max, factor: factor, wait: initial}}
+}
+
+func (b *backoff) nextRun(now time.Time) (time.Duration, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	if !b.done {
+		b.done = true
+		return 0, nil
+	}
+	wait := b.wait
+	if grown := float64(wait) * b.factor; grown < float64(b.max) {
+		b.wait = time.Duration(grown)
+	} else {
+		b.wait = b.max
+	}
+	return wait, nil
+}
+
+// next assumes the current wait, as later ones depend on how many runs came
+// before.
+func (b *backoff) next(after time.Time) (time.Time, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	return after.Add(b.wait), nil
+}
+
+// Next implements Schedule.
+func (b *backoff) Next(after time.Time) time.Time {
+	date, _ := b.next(after)
+	return date
+}
+
+func (b *backoff) description() string {
+	return "backoff from " + b.initial.String() + " to " + b.max.String() + " by " + strconv.FormatFloat(b.factor, 'f', -1, 64)
+}
+
+func (b *backoff) reset() {
+	b.Lock()
+	defer b.Unlock()
+
+	b.wait = b.initial
+}
+
+// Reset sets a Backoff job back to its initial interval, so its next run is
+// due that long from now. It may be called from any goroutine, including the
+// job's own runs, and does nothing to jobs with other schedules.
+func (j *Job) Reset() {
+	b, ok := j.current().(*backoff)
+	if !ok {
+		return
+	}
+	b.reset()
+	// Wake the scheduling goroutine, if it is waiting, to recompute the next
+	// run. Otherwise it is about to, and sees the reset then.
+	select {
+	case j.changes <- change{schedule: b}:
+	default:
+	}
+}
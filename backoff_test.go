@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	now := time.Date(2015, 6, 3, 2, 0, 0, 0, time.UTC)
+	job := Backoff(time.Second, 5*time.Second, 2)
+	assert.Nil(t, job.err)
+	assert.Equal(t, "backoff from 1s to 5s by 2", job.Description())
+
+	var waits []time.Duration
+	for i := 0; i < 6; i++ {
+		next, err := job.schedule.nextRun(now)
+		assert.Nil(t, err)
+		waits = append(waits, next)
+	}
+	assert.Equal(t, []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}, waits)
+	assert.Equal(t, now.Add(5*time.Second), job.Next(now))
+
+	job.Reset()
+	next, _ := job.schedule.nextRun(now)
+	assert.Equal(t, time.Second, next)
+	next, _ = job.schedule.nextRun(now)
+	assert.Equal(t, 2*time.Second, next)
+
+	assert.NotNil(t, Backoff(0, time.Second, 2).err)
+	assert.NotNil(t, Backoff(time.Second, time.Millisecond, 2).err)
+	assert.NotNil(t, Backoff(time.Second, time.Minute, 0.5).err)
+}
+
+func TestBackoffResetWakesJob(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 2, 0, 0, 0, time.UTC)}
+	job, err := Backoff(time.Hour, 24*time.Hour, 2).NotImmediately().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, clock.t.Add(time.Hour), job.State().NextRun)
+	job.SkipWait <- true
+	for !job.State().NextRun.Equal(clock.t.Add(2 * time.Hour)) {
+		time.Sleep(time.Millisecond)
+	}
+
+	job.Reset()
+	for !job.State().NextRun.Equal(clock.t.Add(time.Hour)) {
+		time.Sleep(time.Millisecond)
+	}
+	Every(1).Hours().Reset()
+}
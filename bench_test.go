@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func stopAll(jobs []*Job) {
+	for _, job := range jobs {
+		job.Quit <- true
+	}
+}
+
+// BenchmarkRegister measures the cost of registering a lightweight job.
+func BenchmarkRegister(b *testing.B) {
+	b.ReportAllocs()
+	jobs := make([]*Job, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		job, err := Every(1).Hours().NotImmediately().Run(test)
+		if err != nil {
+			b.Fatal(err)
+		}
+		jobs = append(jobs, job)
+	}
+	b.StopTimer()
+	stopAll(jobs)
+}
+
+// BenchmarkDispatch measures the cost of a single wakeup and run.
+func BenchmarkDispatch(b *testing.B) {
+	b.ReportAllocs()
+	done := make(chan bool)
+	job, err := Every(1).Hours().NotImmediately().Run(func() { done <- true })
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { job.Quit <- true }()
+	for i := 0; i < b.N; i++ {
+		job.SkipWait <- true
+		<-done
+	}
+}
+
+// Benchmark100kJobs reports the memory held per idle job with 100k jobs
+// registered, and how late a due job is dispatched under that load.
+func Benchmark100kJobs(b *testing.B) {
+	bench100kJobs(b, nil)
+}
+
+// Benchmark100kJobsDispatcher works like Benchmark100kJobs for a scheduler
+// with a dispatcher, see WithDispatcher.
+func Benchmark100kJobsDispatcher(b *testing.B) {
+	bench100kJobs(b, NewScheduler().WithDispatcher())
+}
+
+func bench100kJobs(b *testing.B, s *Scheduler) {
+	const n = 100000
+	every := Every
+	if s != nil {
+		every = s.Every
+	}
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		jobs := make([]*Job, 0, n)
+		for k := 0; k < n; k++ {
+			job, err := every(1).Hours().NotImmediately().Run(test)
+			if err != nil {
+				b.Fatal(err)
+			}
+			jobs = append(jobs, job)
+		}
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		b.ReportMetric(float64(after.Sys-before.Sys)/n, "sys-bytes/job")
+
+		fired := make(chan time.Time, 1)
+		start := time.Now()
+		probe, err := every(10).Seconds().Run(func() { fired <- time.Now() })
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ReportMetric(float64((<-fired).Sub(start).Nanoseconds()), "wakeup-ns")
+		probe.Quit <- true
+		stopAll(jobs)
+	}
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import "time"
+
+// Clock is the source of time used by a job. The default clock follows the
+// wall clock; tests may use schedulertest.Clock to move time forward at will.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of *time.Timer a job needs from its Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// RunTracker may be implemented by a Clock that needs to know when the runs it
+// triggered have finished. RunStarted is called from the scheduling goroutine
+// before a run starts and RunFinished once the job function returns.
+type RunTracker interface {
+	RunStarted()
+	RunFinished()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// WithClock sets the clock the job is scheduled against. It must be called
+// before Run.
+func (j *Job) WithClock(c Clock) *Job {
+	j.clock = c
+	j.running, _ = c.(RunTracker)
+	return j
+}
+
+func (j *Job) getClock() Clock {
+	if j.clock == nil {
+		return realClock{}
+	}
+	return j.clock
+}
+
+func (j *Job) now() time.Time {
+	return j.getClock().Now()
+}
+
+// wallCheckInterval is the longest a job waiting for a calendar time sleeps
+// without checking the wall clock, see untilDue.
+const wallCheckInterval = time.Minute
+
+// followsWall reports if the job runs at wall clock times that a change of the
+// system clock, such as an NTP step, would move. Timers measure monotonic time
+// and miss such changes, and interval schedules do not care about them.
+func (j *Job) followsWall() bool {
+	if j.clock != nil {
+		return false
+	}
+	switch s := j.schedule.(type) {
+	case *recurrent:
+		return s.phased
+	case *backoff, *delay:
+		return false
+	}
+	return true
+}
+
+// wait returns how long to sleep before the next run, which is d away, is
+// checked again.
+func (j *Job) wait(d time.Duration) time.Duration {
+	if j.followsWall() && d > wallCheckInterval {
+		return wallCheckInterval
+	}
+	return d
+}
+
+// untilDue returns how much longer the job must sleep before its next run is
+// due by the wall clock, or 0 if it is due. Sleeps are capped by wait, so a
+// jump of the wall clock while the job sleeps is noticed within
+// wallCheckInterval: a jump backwards delays the run to its wall clock time,
+// and a jump forwards past it starts the run.
+func (j *Job) untilDue() time.Duration {
+	if !j.followsWall() {
+		return 0
+	}
+	j.RLock()
+	due := j.nextAt.Round(0)
+	j.RUnlock()
+	if d := due.Sub(time.Now().Round(0)); d > 0 {
+		return j.wait(d)
+	}
+	return 0
+}
@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntilDue(t *testing.T) {
+	job := Every().Day().At("08:30")
+	job.nextAt = time.Now().Round(0).Add(10 * time.Second)
+	d := job.untilDue()
+	assert.True(t, d > 9*time.Second && d <= 10*time.Second)
+
+	job.nextAt = time.Now().Round(0).Add(time.Hour)
+	assert.Equal(t, wallCheckInterval, job.untilDue())
+
+	job.nextAt = time.Now().Round(0).Add(-time.Second)
+	assert.Equal(t, time.Duration(0), job.untilDue())
+}
+
+func TestUntilDueIgnored(t *testing.T) {
+	for _, job := range []*Job{
+		Every(1).Hours(),
+		Every().Day().WithClock(fixedClock{time.Now()}),
+	} {
+		job.nextAt = time.Now().Add(time.Hour)
+		assert.Equal(t, time.Duration(0), job.untilDue())
+		assert.Equal(t, time.Hour, job.wait(time.Hour))
+	}
+}
+
+func TestWaitCapped(t *testing.T) {
+	assert.Equal(t, wallCheckInterval, Every().Monday().wait(48*time.Hour))
+	assert.Equal(t, time.Second, Every().Monday().wait(time.Second))
+}
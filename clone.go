@@ -0,0 +1,125 @@
+package scheduler
+
+import "time"
+
+// Clone returns a new job with the schedule and options of j, such as its
+// name, scheduler, retries, timeout and hooks, ready to be configured further
+// and run with a function of its own. The state of j, its runs, stats and
+// errors, is not copied. Clones of a job that has already run do not run
+// immediately, as if NotImmediately was set.
+func (j *Job) Clone() *Job {
+	j.RLock()
+	defer j.RUnlock()
+
+	c := &Job{
+		deadline:  j.deadline,
+		err:       j.err,
+		inline:    j.inline,
+		clock:     j.clock,
+		running:   j.running,
+		scheduler: j.scheduler,
+		queue:     j.queue,
+		overflow:  j.overflow,
+		attempts:  j.attempts,
+		delay:     j.delay,
+		exhausted: j.exhausted,
+		onSuccess: j.onSuccess,
+		onFailure: j.onFailure,
+		breakAt:   j.breakAt,
+		cooloff:   j.cooloff,
+		onBreak:   j.onBreak,
+		onStatus:  j.onStatus,
+		timeout:   j.timeout,
+		maxErrors: j.maxErrors,
+		name:      j.name,
+		holidays:  j.holidays,
+		shift:     j.shift,
+		logger:    j.logger,
+		exclusive: j.exclusive,
+		skipLate:  j.skipLate,
+		onOverrun: j.onOverrun,
+		stallTime: j.stallTime,
+		onStall:   j.onStall,
+		recovers:  j.recovers,
+		onPanic:   j.onPanic,
+		panicOff:  j.panicOff,
+		onCrash:   j.onCrash,
+		rerun:     j.rerun,
+	}
+	if j.schedule != nil {
+		c.schedule = cloneSchedule(j.schedule)
+	}
+	return c
+}
+
+// cloneSchedule returns a copy of a schedule that a job can change, on its own
+// as it runs or through builder methods, without affecting the original.
+// Schedules that never change are shared.
+func cloneSchedule(s scheduled) scheduled {
+	switch s := s.(type) {
+	case *recurrent:
+		c := *s
+		c.epoch = time.Time{}
+		return &c
+	case *backoff:
+		s.Lock()
+		defer s.Unlock()
+		return &backoff{initial: s.initial, max: s.max, factor: s.factor, done: s.done, wait: s.initial}
+	case *delay:
+		return &delay{d: s.d}
+	case *once:
+		return &once{at: s.at, late: s.late}
+	case *daily:
+		c := *s
+		return &c
+	case *weekly:
+		c := *s
+		return &c
+	case *everyWeeks:
+		c := *s
+		return &c
+	case rota:
+		c := make(rota, len(s))
+		for i, w := range s {
+			day := *w
+			c[i] = &day
+		}
+		return c
+	case *monthly:
+		c := *s
+		return &c
+	case *monthlyWeekday:
+		c := *s
+		return &c
+	case *cron:
+		c := *s
+		return &c
+	}
+	return s
+}
+
+// Template is a configured job that can be instantiated many times, e.g. once
+// per tenant, with different names and functions:
+//
+//	poll := scheduler.NewTemplate(s.Every(5).Minutes().Retry(3, time.Second).Timeout(time.Minute))
+//	for _, t := range tenants {
+//		poll.New().Named("poll-" + t.ID).RunErr(t.Poll)
+//	}
+//
+// Changes to the job given to NewTemplate after the call do not affect the
+// template. A template may be used from several goroutines.
+type Template struct {
+	job *Job
+}
+
+// NewTemplate returns a template of the job j, which should not be run
+// itself. The error of a badly configured j is returned when the jobs of the
+// template are run.
+func NewTemplate(j *Job) *Template {
+	return &Template{job: j.Clone()}
+}
+
+// New returns a new job configured as the template.
+func (t *Template) New() *Job {
+	return t.job.Clone()
+}
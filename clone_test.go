@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	s := NewScheduler()
+	succeeded := make(chan string, 2)
+	base := s.Every(1).Hours().Retry(2, time.Millisecond).Timeout(time.Minute).OnSuccess(func(info RunInfo) {
+		succeeded <- info.Job.Name()
+	})
+	a := base.Clone().Named("a")
+	b := base.Clone().Named("b")
+	assert.Equal(t, "", base.Name())
+
+	fails := 1
+	_, err := a.RunErr(func(context.Context) error {
+		if fails > 0 {
+			fails--
+			return errors.New("boom")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	defer a.Stop()
+	assert.Equal(t, "a", <-succeeded)
+	_, err = b.Run(test)
+	assert.Nil(t, err)
+	defer b.Stop()
+	assert.Equal(t, "b", <-succeeded)
+	assert.Equal(t, []*Job{a, b}, s.Jobs())
+	assert.Equal(t, time.Minute, b.timeout)
+
+	// The clone of a running job has its own schedule, and runs only once due.
+	c := a.Clone()
+	assert.NotSame(t, a.schedule, c.schedule)
+	assert.Equal(t, 0, c.Stats().Runs)
+	wait, err := c.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour, wait)
+
+	assert.NotNil(t, Every(1, 2).Clone().err)
+}
+
+func TestTemplate(t *testing.T) {
+	job := Every().Day().At("08:00")
+	tmpl := NewTemplate(job)
+	job.At("09:00")
+	madrid := tmpl.New().Timezone("Europe/Madrid")
+	assert.Equal(t, "every day at 08:00:00", tmpl.New().Description())
+	assert.NotEqual(t, madrid.schedule, tmpl.New().schedule)
+
+	_, err := NewTemplate(Every(1).Day()).New().Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestScheduleOfCopies(t *testing.T) {
+	template := Backoff(time.Second, time.Minute, 2)
+	a, err := NewJob(template, test)
+	assert.Nil(t, err)
+	defer a.Stop()
+	b, err := NewJob(template, test)
+	assert.Nil(t, err)
+	defer b.Stop()
+	assert.NotSame(t, template.current(), a.current())
+	assert.NotSame(t, a.current(), b.current())
+
+	assert.Nil(t, b.Reschedule(a))
+	assert.NotSame(t, a.current(), b.current())
+}
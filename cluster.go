@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"context"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// clusterReplicas is how many points each member gets on the hash ring, which
+// evens out the share of jobs each one owns.
+const clusterReplicas = 64
+
+// Cluster splits the named jobs of several schedulers, one per node, so that
+// each job runs on the one node that owns it. Ownership is decided by
+// consistent hashing over job names, so when a node joins or leaves only the
+// jobs it gains or loses move. Every node must schedule the same jobs and be
+// told the same members, either with SetMembers or from a Membership, see
+// Join.
+type Cluster struct {
+	self    string
+	members []string
+	hashes  []uint32
+	owners  map[uint32]string
+	sync.RWMutex
+}
+
+// NewCluster returns the cluster as seen by the node called self, which is its
+// only member until SetMembers is called.
+func NewCluster(self string) *Cluster {
+	c := &Cluster{self: self}
+	c.SetMembers(self)
+	return c
+}
+
+// SetMembers replaces the members of the cluster, e.g. whenever service
+// discovery reports a change. Jobs move to their new owners from their next
+// run; runs in progress are not interrupted. A node that is not a member owns
+// no jobs.
+func (c *Cluster) SetMembers(members ...string) {
+	hashes := make([]uint32, 0, len(members)*clusterReplicas)
+	owners := make(map[uint32]string, len(members)*clusterReplicas)
+	for _, m := range members {
+		for i := 0; i < clusterReplicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(m + "#" + strconv.Itoa(i)))
+			hashes = append(hashes, h)
+			owners[h] = m
+		}
+	}
+	sort.Slice(hashes, func(i, k int) bool { return hashes[i] < hashes[k] })
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.members = append([]string(nil), members...)
+	c.hashes = hashes
+	c.owners = owners
+}
+
+// Members returns the members of the cluster.
+func (c *Cluster) Members() []string {
+	c.RLock()
+	defer c.RUnlock()
+
+	return append([]string(nil), c.members...)
+}
+
+// Owner returns the member that owns the job with the given name, or "" if
+// the cluster has no members.
+func (c *Cluster) Owner(name string) string {
+	c.RLock()
+	defer c.RUnlock()
+
+	if len(c.hashes) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(name))
+	i := sort.Search(len(c.hashes), func(i int) bool { return c.hashes[i] >= h })
+	if i == len(c.hashes) {
+		i = 0
+	}
+	return c.owners[c.hashes[i]]
+}
+
+// Owns reports whether this node owns the job with the given name.
+func (c *Cluster) Owns(name string) bool {
+	return c.Owner(name) == c.self
+}
+
+// Membership tells the nodes of a cluster about each other, e.g. through the
+// backend of a Store or Locker. Join registers the node called self for as
+// long as ctx is not done, or until the node can no longer stay registered,
+// and calls members with all the nodes registered, including self, on every
+// change, starting with the ones registered when it joins. Nodes that stop
+// without leaving must drop out of members after a while, e.g. once a lease
+// of theirs expires.
+type Membership interface {
+	Join(ctx context.Context, self string, members func([]string)) error
+}
+
+// Join registers the node in m and rebalances the jobs of the cluster on every
+// change to its members until ctx is done. It returns the error that made m
+// give up, if any; the node keeps the last members it was told.
+func (c *Cluster) Join(ctx context.Context, m Membership) error {
+	return m.Join(ctx, c.self, func(members []string) { c.SetMembers(members...) })
+}
+
+// WithCluster makes the scheduler run only the named jobs that its node owns
+// in the cluster; the runs of the others are skipped. Jobs without a name run
+// on every node.
+func (s *Scheduler) WithCluster(c *Cluster) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.cluster = c
+	return s
+}
+
+// runs reports whether the scheduler runs the job with the given name now,
+// i.e. it is not on standby and owns the job if it is clustered.
+func (s *Scheduler) runs(name string) bool {
+	s.Lock()
+	standby, c := s.standby, s.cluster
+	s.Unlock()
+
+	return !standby && (c == nil || name == "" || c.Owns(name))
+}
@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterOwnership(t *testing.T) {
+	nodes := []*Cluster{NewCluster("a"), NewCluster("b"), NewCluster("c")}
+	for _, c := range nodes {
+		c.SetMembers("a", "b", "c")
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, nodes[0].Members())
+
+	owners := make(map[string]string)
+	count := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		name := fmt.Sprintf("job-%d", i)
+		owned := 0
+		for _, c := range nodes {
+			if c.Owns(name) {
+				owned++
+			}
+		}
+		assert.Equal(t, 1, owned, name)
+		owners[name] = nodes[0].Owner(name)
+		count[owners[name]]++
+	}
+	for _, node := range []string{"a", "b", "c"} {
+		assert.True(t, count[node] > 50, "node %s owns %d jobs", node, count[node])
+	}
+
+	// A joining node only takes jobs from the others.
+	nodes[0].SetMembers("a", "b", "c", "d")
+	moved := 0
+	for name, owner := range owners {
+		if now := nodes[0].Owner(name); now != owner {
+			assert.Equal(t, "d", now)
+			moved++
+		}
+	}
+	assert.True(t, moved > 0 && moved < 150, "%d jobs moved", moved)
+
+	nodes[0].SetMembers()
+	assert.Equal(t, "", nodes[0].Owner("job-1"))
+	assert.False(t, nodes[0].Owns("job-1"))
+}
+
+func TestWithCluster(t *testing.T) {
+	c := NewCluster("a")
+	assert.True(t, c.Owns("report"))
+	s := NewScheduler().WithCluster(c)
+
+	ran := make(chan string, 10)
+	report, err := s.Every(1).Hours().NotImmediately().Named("report").Run(func() { ran <- "report" })
+	assert.Nil(t, err)
+	defer report.Stop()
+	unnamed, err := s.Every(1).Hours().NotImmediately().Run(func() { ran <- "unnamed" })
+	assert.Nil(t, err)
+	defer unnamed.Stop()
+
+	c.SetMembers("b")
+	report.SkipWait <- true
+	unnamed.SkipWait <- true
+	assert.Equal(t, "unnamed", <-ran)
+	select {
+	case name := <-ran:
+		t.Error("job of another node ran:", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, Stats{Skipped: 1}, report.Stats())
+
+	c.SetMembers("a")
+	report.SkipWait <- true
+	assert.Equal(t, "report", <-ran)
+}
+
+// memoryMembership registers the nodes of a cluster in memory.
+type memoryMembership struct {
+	nodes map[string]func([]string)
+	mu    sync.Mutex
+}
+
+func (m *memoryMembership) Join(ctx context.Context, self string, members func([]string)) error {
+	m.mu.Lock()
+	m.nodes[self] = members
+	m.notify()
+	m.mu.Unlock()
+
+	<-ctx.Done()
+	m.mu.Lock()
+	delete(m.nodes, self)
+	m.notify()
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memoryMembership) notify() {
+	var names []string
+	for name := range m.nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, members := range m.nodes {
+		members(names)
+	}
+}
+
+func TestClusterJoin(t *testing.T) {
+	m := &memoryMembership{nodes: make(map[string]func([]string))}
+	a, b := NewCluster("a"), NewCluster("b")
+	ctxA, leaveA := context.WithCancel(context.Background())
+	doneA := make(chan error)
+	go func() { doneA <- a.Join(ctxA, m) }()
+	for len(a.Members()) != 1 || a.Members()[0] != "a" {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctxB, leaveB := context.WithCancel(context.Background())
+	defer leaveB()
+	go b.Join(ctxB, m)
+	for len(a.Members()) != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, []string{"a", "b"}, a.Members())
+	assert.Equal(t, []string{"a", "b"}, b.Members())
+
+	leaveA()
+	assert.Nil(t, <-doneA)
+	for len(b.Members()) != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, []string{"b"}, b.Members())
+	assert.True(t, b.Owns("report"))
+}
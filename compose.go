@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// maxIntersectSteps bounds the search for an instant shared by all the
+// schedules of an intersection.
+const maxIntersectSteps = 10000
+
+type union []Schedule
+
+// Union returns a schedule that fires whenever any of the given schedules
+// fires, e.g. Union(Every().Monday().At("08:00"), Every().Friday().At("14:00")).
+func Union(schedules ...Schedule) Schedule {
+	return union(schedules)
+}
+
+func (u union) Next(after time.Time) time.Time {
+	var earliest time.Time
+	for _, s := range u {
+		date := s.Next(after)
+		if !date.IsZero() && (earliest.IsZero() || date.Before(earliest)) {
+			earliest = date
+		}
+	}
+	return earliest
+}
+
+func (u union) nextRun(now time.Time) (time.Duration, error) {
+	return nextRunOf(u, now)
+}
+
+func (u union) next(after time.Time) (time.Time, error) {
+	return nextOf(u, after)
+}
+
+func (u union) description() string {
+	return describeAll(u, " or ")
+}
+
+type intersection []Schedule
+
+// Intersect returns a schedule that fires only at the instants at which all of
+// the given schedules fire, e.g. Intersect(Every(10).Minutes(), weekdays) with
+// weekdays a Window from 9:00 to 17:00, Monday to Friday. Interval schedules
+// are aligned to multiples of their period since midnight, so every 10 minutes
+// means at :00, :10, :20 and so on; periods of a day or longer are aligned to
+// the Unix epoch instead. The intersection has no next run if none is found
+// within a reasonable search.
+func Intersect(schedules ...Schedule) Schedule {
+	aligned := make(intersection, len(schedules))
+	for i, s := range schedules {
+		aligned[i] = align(s)
+	}
+	return aligned
+}
+
+// align turns interval schedules, which count from the previous run, into
+// fixed instants so they can be intersected.
+func align(s Schedule) Schedule {
+	if j, ok := s.(*Job); ok && j.err == nil {
+		if r, ok := j.schedule.(*recurrent); ok {
+			s = r
+		}
+	}
+	if r, ok := s.(*recurrent); ok && r.units > 0 && r.period > 0 && !r.phased {
+		return alignedInterval(time.Duration(r.units) * r.period)
+	}
+	return s
+}
+
+func (in intersection) Next(after time.Time) time.Time {
+	if len(in) == 0 {
+		return time.Time{}
+	}
+	t := after
+	for i := 0; i < maxIntersectSteps; i++ {
+		// Leapfrog: the latest of the next instants is the earliest that
+		// could be shared; accept it if every schedule fires there too.
+		var latest time.Time
+		for _, s := range in {
+			date := s.Next(t)
+			if date.IsZero() {
+				return time.Time{}
+			}
+			if date.After(latest) {
+				latest = date
+			}
+		}
+		shared := true
+		for _, s := range in {
+			if !s.Next(latest.Add(-time.Nanosecond)).Equal(latest) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			return latest
+		}
+		t = latest.Add(-time.Nanosecond)
+	}
+	return time.Time{}
+}
+
+func (in intersection) nextRun(now time.Time) (time.Duration, error) {
+	return nextRunOf(in, now)
+}
+
+func (in intersection) next(after time.Time) (time.Time, error) {
+	return nextOf(in, after)
+}
+
+func (in intersection) description() string {
+	return describeAll(in, " and ")
+}
+
+type alignedInterval time.Duration
+
+func (a alignedInterval) Next(after time.Time) time.Time {
+	if time.Duration(a) >= 24*time.Hour {
+		return after.Truncate(time.Duration(a)).Add(time.Duration(a))
+	}
+	year, month, day := after.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, after.Location())
+	date := midnight.Add((after.Sub(midnight)/time.Duration(a) + 1) * time.Duration(a))
+	if tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, after.Location()); !date.Before(tomorrow) {
+		return tomorrow
+	}
+	return date
+}
+
+func (a alignedInterval) description() string {
+	return "every " + time.Duration(a).String()
+}
+
+type window struct {
+	from daily
+	to   daily
+	days [7]bool
+}
+
+// Window returns a schedule that fires at every instant from one time of day
+// up to, but not including, another, given as in At, on the given weekdays or
+// every day if none are given. A window ending at or before its start runs
+// past midnight. It is meant to restrict other schedules with Intersect;
+// jobs on a Window, or on a Union with one, that is not inside an Intersect
+// fail with an error.
+func Window(from, to string, days ...time.Weekday) (Schedule, error) {
+	w := &window{}
+	for _, t := range []struct {
+		str string
+		d   *daily
+	}{{from, &w.from}, {to, &w.to}} {
+		hour, min, sec, nsec, err := parseTime(t.str)
+		if err != nil {
+			return nil, err
+		}
+		t.d.setTime(hour, min, sec, nsec)
+	}
+	for _, day := range days {
+		if day < time.Sunday || day > time.Saturday {
+			return nil, errors.New("bad weekday")
+		}
+		w.days[day] = true
+	}
+	if len(days) == 0 {
+		w.days = [7]bool{true, true, true, true, true, true, true}
+	}
+	return w, nil
+}
+
+func (w *window) Next(after time.Time) time.Time {
+	t := after.Add(time.Nanosecond).In(w.from.location())
+	year, month, day := t.Date()
+	// Start the day before, whose window may run past midnight.
+	for i := -1; i < 8; i++ {
+		start := w.from.on(year, month, day+i)
+		if !w.days[start.Weekday()] {
+			continue
+		}
+		end := w.to.on(year, month, day+i)
+		if !end.After(start) {
+			end = w.to.on(year, month, day+i+1)
+		}
+		if t.Before(start) {
+			return start
+		}
+		if t.Before(end) {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// unbounded reports whether s fires at every instant of a window, which a job
+// would run in a tight loop: a Window, or a Union with one, not restricted by
+// Intersect.
+func unbounded(s Schedule) bool {
+	switch s := s.(type) {
+	case *window:
+		return true
+	case union:
+		for _, u := range s {
+			if unbounded(u) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (w *window) description() string {
+	var days []string
+	for day, ok := range w.days {
+		if ok {
+			days = append(days, time.Weekday(day).String())
+		}
+	}
+	if len(days) == 7 {
+		days = []string{"every day"}
+	}
+	return "from " + w.from.timeString() + " to " + w.to.timeString() + " on " + strings.Join(days, ", ")
+}
+
+func nextOf(s Schedule, after time.Time) (time.Time, error) {
+	date := s.Next(after)
+	if date.IsZero() {
+		return time.Time{}, errNoNextRun
+	}
+	return date, nil
+}
+
+func nextRunOf(s Schedule, now time.Time) (time.Duration, error) {
+	date, err := nextOf(s, now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+func describe(s Schedule) string {
+	switch d := s.(type) {
+	case interface{ description() string }:
+		return d.description()
+	case *Job:
+		return d.Description()
+	}
+	return "custom schedule"
+}
+
+func describeAll(schedules []Schedule, sep string) string {
+	parts := make([]string, len(schedules))
+	for i, s := range schedules {
+		parts[i] = "(" + describe(s) + ")"
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var weekdays9to5, _ = Window("09:00", "17:00", time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+
+func TestUnion(t *testing.T) {
+	s := Union(Every().Monday().At("08:00"), Every().Friday().At("14:00"))
+	assert.Equal(t, date(2015, 6, 5, 14, 0), s.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 6, 8, 8, 0), s.Next(date(2015, 6, 5, 14, 0)))
+	assert.True(t, Union().Next(date(2015, 6, 3, 12, 0)).IsZero())
+
+	job, err := On(s).Run(test)
+	assert.Nil(t, err)
+	assert.Equal(t, "(every Monday at 08:00:00) or (every Friday at 14:00:00)", job.Description())
+}
+
+func TestIntersectIntervalWindow(t *testing.T) {
+	s := Intersect(Every(10).Minutes(), weekdays9to5)
+	assert.Equal(t, date(2015, 6, 3, 12, 10), s.Next(date(2015, 6, 3, 12, 3)))
+	assert.Equal(t, date(2015, 6, 4, 9, 0), s.Next(date(2015, 6, 3, 16, 50)))
+	assert.Equal(t, date(2015, 6, 8, 9, 0), s.Next(date(2015, 6, 5, 16, 55)))
+	assert.Equal(t, date(2015, 6, 8, 9, 0), s.Next(date(2015, 6, 6, 12, 0)))
+}
+
+func TestIntersectCalendarWindow(t *testing.T) {
+	s := Intersect(Every().Day().At("08:30"), weekdays9to5)
+	assert.True(t, s.Next(date(2015, 6, 3, 12, 0)).IsZero())
+
+	s = Intersect(Every().Day().At("10:00"), weekdays9to5)
+	assert.Equal(t, date(2015, 6, 8, 10, 0), s.Next(date(2015, 6, 5, 10, 0)))
+}
+
+func TestIntersectCalendars(t *testing.T) {
+	// First Mondays of the month that are also the 1st: June 2015, February 2016.
+	s := Intersect(Every().Month().OnDay(1), Every().Monday())
+	assert.Equal(t, date(2015, 6, 1, 0, 0), s.Next(date(2015, 1, 1, 0, 0)))
+	assert.Equal(t, date(2016, 2, 1, 0, 0), s.Next(date(2015, 6, 1, 0, 0)))
+}
+
+func TestWindowPastMidnight(t *testing.T) {
+	s, err := Window("22:00", "06:00")
+	assert.Nil(t, err)
+	from := date(2015, 6, 3, 12, 0)
+	assert.Equal(t, date(2015, 6, 3, 22, 0), s.Next(from))
+	inside := date(2015, 6, 4, 3, 0)
+	assert.Equal(t, inside.Add(time.Nanosecond), s.Next(inside))
+	assert.Equal(t, date(2015, 6, 4, 22, 0), s.Next(date(2015, 6, 4, 6, 0)))
+
+	s, err = Window("22:00", "06:00", time.Saturday)
+	assert.Nil(t, err)
+	assert.Equal(t, date(2015, 6, 7, 3, 0).Add(time.Nanosecond), s.Next(date(2015, 6, 7, 3, 0)))
+}
+
+func TestBadWindow(t *testing.T) {
+	_, err := Window("25:00", "06:00")
+	assert.NotNil(t, err)
+	_, err = Window("22:00", "06:00", time.Weekday(7))
+	assert.NotNil(t, err)
+}
+
+func TestWindowNeedsIntersect(t *testing.T) {
+	_, err := On(weekdays9to5).Run(test)
+	assert.EqualError(t, err, "window needs Intersect")
+	_, err = On(Union(Every().Monday().At("08:00"), weekdays9to5)).Run(test)
+	assert.EqualError(t, err, "window needs Intersect")
+	job, err := On(Union(Every().Monday().At("08:00"), Intersect(Every(1).Hours(), weekdays9to5))).Run(test)
+	assert.Nil(t, err)
+	job.Stop()
+}
+
+func TestIntersectRun(t *testing.T) {
+	job, err := On(Intersect(Every(1).Hours(), Every(90).Minutes())).Run(test)
+	assert.Nil(t, err)
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual)
+	assert.Equal(t, 0, runTime.Minute())
+	assert.Equal(t, 0, runTime.Hour()%3)
+}
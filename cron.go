@@ -0,0 +1,301 @@
+package scheduler
+
+import (
+	"errors"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronDays bounds the search for the next day matching a cron spec, which
+// is long enough for February 29 even across a century without one.
+const maxCronDays = 366 * 8
+
+// cronField describes one of the five fields of a cron spec. H picks its
+// values from min to hashMax, which keeps hashed days of the month valid in
+// every month.
+type cronField struct {
+	name    string
+	min     int
+	max     int
+	hashMax int
+	names   map[string]int
+}
+
+var cronFields = [5]cronField{
+	{name: "minute", min: 0, max: 59, hashMax: 59},
+	{name: "hour", min: 0, max: 23, hashMax: 23},
+	{name: "day of month", min: 1, max: 31, hashMax: 28},
+	{name: "month", min: 1, max: 12, hashMax: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "day of week", min: 0, max: 7, hashMax: 6, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+// cronTerm is one comma separated term of a field: the values from lo to hi
+// every step. Hashed terms start at an offset given by the key instead, and
+// without a step stand for a single value.
+type cronTerm struct {
+	lo, hi, step int
+	hash         bool
+}
+
+type cron struct {
+	spec  string
+	loc   *time.Location
+	terms [5][]cronTerm
+	// anyDay is set if the day of month or day of week field is "*", in
+	// which case days must match both, as in cron, and either otherwise.
+	anyDay bool
+	key    string
+	// sets holds the values of each field, resolved for key, as bits.
+	sets [5]uint64
+}
+
+// Cron returns the schedule of a five field cron spec: minute, hour, day of
+// month, month and day of week, e.g. "30 8 * * mon-fri". Fields take lists,
+// ranges and steps, and names for months and days. The spec may be preceded
+// by CRON_TZ=<zone> to run in that location rather than the local one.
+//
+// H, as in Jenkins, stands for a value picked by hashing a key, so that jobs
+// with the same spec do not all run at once: "H/15 * * * *" runs every 15
+// minutes at some minute from 0 to 14, and "H H(0-5) * * *" once a day in the
+// small hours. The key is the spec itself unless set with SpreadBy, e.g. to
+// the name of the service instance.
+//
+// Errors are returned as *ParseError. Each call returns a new schedule, which
+// should be used by a single job.
+func Cron(spec string) (Schedule, error) {
+	p := &parser{spec: spec, tokens: tokenize(spec)}
+	c := &cron{spec: strings.TrimSpace(spec), loc: time.Local}
+	tokens := p.tokens
+	if len(tokens) > 0 && strings.HasPrefix(tokens[0].text, "CRON_TZ=") {
+		loc, err := loadLocation(strings.TrimPrefix(tokens[0].text, "CRON_TZ="))
+		if err != nil {
+			p.failAt(tokens[0], "unknown location")
+			return nil, p.err
+		}
+		c.loc = loc
+		tokens = tokens[1:]
+	}
+	if len(tokens) != len(cronFields) {
+		p.fail(0, "cron spec needs 5 fields, has "+strconv.Itoa(len(tokens)))
+		return nil, p.err
+	}
+	for i, tok := range tokens {
+		c.terms[i] = p.cronTerms(tok, cronFields[i])
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	c.anyDay = strings.HasPrefix(tokens[2].text, "*") || strings.HasPrefix(tokens[4].text, "*")
+	c.spread(c.spec)
+	if _, err := c.next(time.Now()); err != nil {
+		p.fail(tokens[2].pos, "no such day")
+		return nil, p.err
+	}
+	return c, nil
+}
+
+// cronTerms parses the terms of a field.
+func (p *parser) cronTerms(tok token, f cronField) []cronTerm {
+	var terms []cronTerm
+	for _, str := range strings.Split(strings.ToLower(tok.text), ",") {
+		t, ok := f.term(str)
+		if !ok {
+			p.failAt(tok, "bad "+f.name)
+			return nil
+		}
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// term parses a term such as "*", "5", "1-5", "*/15", "H" or "H(0-29)/10".
+func (f cronField) term(str string) (cronTerm, bool) {
+	base, step := str, 0
+	if i := strings.IndexByte(str, '/'); i >= 0 {
+		n, err := strconv.Atoi(str[i+1:])
+		if err != nil || n < 1 || n > f.max {
+			return cronTerm{}, false
+		}
+		base, step = str[:i], n
+	}
+	t := cronTerm{lo: f.min, hi: f.max, step: 1}
+	switch {
+	case base == "*":
+		if f.max == 7 {
+			t.hi = 6
+		}
+	case base == "h":
+		t.hash, t.hi, t.step = true, f.hashMax, 0
+	case strings.HasPrefix(base, "h(") && strings.HasSuffix(base, ")"):
+		lo, hi, ok := f.span(base[2 : len(base)-1])
+		if !ok || !strings.Contains(base, "-") {
+			return cronTerm{}, false
+		}
+		t.hash, t.lo, t.hi, t.step = true, lo, hi, 0
+	default:
+		lo, hi, ok := f.span(base)
+		if !ok {
+			return cronTerm{}, false
+		}
+		t.lo, t.hi = lo, hi
+		if hi == lo && step > 0 {
+			t.hi = f.max
+		}
+	}
+	if step > 0 {
+		t.step = step
+	}
+	return t, true
+}
+
+// span parses a value or a range of values of the field.
+func (f cronField) span(str string) (int, int, bool) {
+	from, to := str, str
+	if i := strings.IndexByte(str, '-'); i >= 0 {
+		from, to = str[:i], str[i+1:]
+	}
+	lo, ok := f.value(from)
+	if !ok {
+		return 0, 0, false
+	}
+	hi, ok := f.value(to)
+	return lo, hi, ok && lo <= hi
+}
+
+func (f cronField) value(str string) (int, bool) {
+	if n, ok := f.names[str]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(str)
+	return n, err == nil && n >= f.min && n <= f.max
+}
+
+// spread resolves the fields of the spec, picking the values of H terms by
+// hashing key.
+func (c *cron) spread(key string) {
+	c.key = key
+	for i, terms := range c.terms {
+		h := crc32.ChecksumIEEE([]byte(key + "/" + cronFields[i].name))
+		var set uint64
+		for _, t := range terms {
+			start := t.lo
+			switch {
+			case t.hash && t.step == 0:
+				set |= 1 << uint(t.lo+int(h%uint32(t.hi-t.lo+1)))
+				continue
+			case t.hash:
+				start += int(h % uint32(t.step))
+			}
+			for v := start; v <= t.hi; v += t.step {
+				set |= 1 << uint(v)
+			}
+		}
+		c.sets[i] = set
+	}
+	// Sunday may be written as 0 or 7.
+	if c.sets[4]&(1<<7) != 0 {
+		c.sets[4] |= 1
+	}
+}
+
+func (c *cron) has(field, value int) bool {
+	return c.sets[field]&(1<<uint(value)) != 0
+}
+
+func (c *cron) matchDay(date time.Time) bool {
+	if !c.has(3, int(date.Month())) {
+		return false
+	}
+	dom, dow := c.has(2, date.Day()), c.has(4, int(date.Weekday()))
+	if c.anyDay {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (c *cron) next(after time.Time) (time.Time, error) {
+	after = after.In(c.loc)
+	year, month, day := after.Date()
+	for i := 0; i <= maxCronDays; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, c.loc)
+		if !c.matchDay(date) {
+			continue
+		}
+		y, m, d := date.Date()
+		for hour := 0; hour < 24; hour++ {
+			if !c.has(1, hour) {
+				continue
+			}
+			for min := 0; min < 60; min++ {
+				if !c.has(0, min) {
+					continue
+				}
+				t := time.Date(y, m, d, hour, min, 0, 0, c.loc)
+				// Skip times that do not exist when the clocks go forward.
+				if t.Hour() != hour || t.Minute() != min {
+					continue
+				}
+				if t.After(after) {
+					return t, nil
+				}
+			}
+		}
+	}
+	return time.Time{}, errNoNextRun
+}
+
+func (c *cron) nextRun(now time.Time) (time.Duration, error) {
+	date, err := c.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+// Next implements Schedule.
+func (c *cron) Next(after time.Time) time.Time {
+	date, _ := c.next(after)
+	return date
+}
+
+func (c *cron) description() string {
+	return "cron " + c.spec
+}
+
+// SpreadBy offsets the job within its period by a hash of key, so that jobs
+// with the same schedule but different keys, such as the instances of a
+// service, do not all run at once. It sets the key for the H fields of a Cron
+// schedule, and the phase of jobs recurring every n seconds, minutes or
+// hours, which then run at a fixed offset into each period counted from
+// midnight, like AtSecond: Every(15).Minutes().SpreadBy(host) runs at some
+// second of the first 15 minutes of each hour and every 15 minutes after
+// that. The offset stays the same across restarts as long as the key does.
+func (j *Job) SpreadBy(key string) *Job {
+	if j.err != nil {
+		return j
+	}
+	switch s := j.schedule.(type) {
+	case *cron:
+		s.spread(key)
+	case *recurrent:
+		every := time.Duration(s.units) * s.period
+		if s.phased || s.adapt != nil || every < time.Second {
+			j.err = errors.New("bad function chaining")
+			return j
+		}
+		h := crc32.ChecksumIEEE([]byte(key))
+		s.phased = true
+		s.done = true
+		s.offset = time.Duration(int64(h)%int64(every/time.Second)) * time.Second
+	default:
+		j.err = errors.New("bad function chaining")
+	}
+	return j
+}
@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cronNext(t *testing.T, spec string, after time.Time, n int) []time.Time {
+	s, err := Cron(spec)
+	if !assert.Nil(t, err) {
+		return nil
+	}
+	var dates []time.Time
+	for i := 0; i < n; i++ {
+		after = s.Next(after)
+		dates = append(dates, after)
+	}
+	return dates
+}
+
+func TestCron(t *testing.T) {
+	from := time.Date(2015, 6, 3, 12, 10, 30, 0, time.UTC)
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2015, 6, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec string
+		want []time.Time
+	}{
+		{"CRON_TZ=UTC */20 * * * *", []time.Time{at(3, 12, 20), at(3, 12, 40), at(3, 13, 0)}},
+		{"CRON_TZ=UTC 30 8 * * mon-fri", []time.Time{at(4, 8, 30), at(5, 8, 30), at(8, 8, 30)}},
+		{"CRON_TZ=UTC 0 0 1,15 * *", []time.Time{at(15, 0, 0), time.Date(2015, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2015, 7, 15, 0, 0, 0, 0, time.UTC)}},
+		// Both days given: either matches.
+		{"CRON_TZ=UTC 0 9 10 * 0", []time.Time{at(7, 9, 0), at(10, 9, 0), at(14, 9, 0)}},
+		{"CRON_TZ=UTC 5/30 12 * jun 7", []time.Time{at(7, 12, 5), at(7, 12, 35), at(14, 12, 5)}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, cronNext(t, test.spec, from, 3), test.spec)
+	}
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	assert.Nil(t, err)
+	assert.Equal(t, []time.Time{time.Date(2015, 6, 4, 8, 0, 0, 0, madrid)}, cronNext(t, "CRON_TZ=Europe/Madrid 0 8 * * *", from, 1))
+	// 02:30 does not exist when the clocks go forward.
+	spring := time.Date(2015, 3, 28, 12, 0, 0, 0, madrid)
+	assert.Equal(t, []time.Time{time.Date(2015, 3, 30, 2, 30, 0, 0, madrid)}, cronNext(t, "CRON_TZ=Europe/Madrid 30 2 * * *", spring, 1))
+
+	s, err := Cron("0 12 * * *")
+	assert.Nil(t, err)
+	assert.Equal(t, "cron 0 12 * * *", On(s).Description())
+}
+
+func TestCronErrors(t *testing.T) {
+	for spec, pos := range map[string]int{
+		"":                          0,
+		"* * * *":                   0,
+		"60 * * * *":                0,
+		"* 1-0 * * *":               2,
+		"* * * foo *":               6,
+		"* * * * */0":               8,
+		"* * H(5) * *":              4,
+		"0 0 30 2 *":                4,
+		"CRON_TZ=Nowhere * * * * *": 0,
+	} {
+		_, err := Cron(spec)
+		if perr, ok := err.(*ParseError); assert.True(t, ok, spec) {
+			assert.Equal(t, pos, perr.Pos, spec)
+		}
+	}
+}
+
+func TestCronHash(t *testing.T) {
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)
+	minutes := make(map[int]bool)
+	for _, key := range []string{"web-1", "web-2", "web-3", "web-4"} {
+		s, err := Cron("CRON_TZ=UTC H/15 * * * *")
+		assert.Nil(t, err)
+		job := On(s).SpreadBy(key)
+		first := job.Next(from)
+		assert.True(t, first.Minute() < 15)
+		assert.Equal(t, first.Add(15*time.Minute), job.Next(first))
+		minutes[first.Minute()] = true
+
+		again, _ := Cron("CRON_TZ=UTC H/15 * * * *")
+		assert.Equal(t, first, On(again).SpreadBy(key).Next(from))
+	}
+	assert.True(t, len(minutes) > 1)
+
+	s, err := Cron("CRON_TZ=UTC H H(0-5) * * *")
+	assert.Nil(t, err)
+	date := s.Next(from)
+	assert.True(t, date.Hour() <= 5)
+	assert.Equal(t, date.Add(24*time.Hour), s.Next(date))
+}
+
+func TestSpreadBy(t *testing.T) {
+	job := Every(15).Minutes().SpreadBy("web-1")
+	assert.Nil(t, job.err)
+	offset := job.schedule.(*recurrent).offset
+	assert.True(t, offset >= 0 && offset < 15*time.Minute)
+	assert.Equal(t, offset, Every(15).Minutes().SpreadBy("web-1").schedule.(*recurrent).offset)
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, from.Add(offset), job.Next(from.Add(-time.Nanosecond)))
+
+	assert.NotNil(t, Every().Day().SpreadBy("web-1").err)
+	assert.NotNil(t, Every(1).Hours().AtMinute(5).SpreadBy("web-1").err)
+
+	spread, err := NewJob(Every(1).Hours(), test, WithSpread("web-1"))
+	assert.Nil(t, err)
+	spread.Stop()
+}
@@ -0,0 +1,161 @@
+// Package dashboard serves a web page showing the jobs of a running scheduler,
+// with their next and last runs and recent failures, and buttons to pause,
+// resume and trigger them:
+//
+//	http.Handle("/jobs/", http.StripPrefix("/jobs", dashboard.New(s)))
+//
+// The page refreshes itself every few seconds. The same rows are served as
+// JSON at jobs.json. Changes are made through the scheduler's methods that
+// take a context, so they are recorded in its audit sink on behalf of the
+// actor of the request, see Handler.Actor. The handler does no
+// authentication of its own: wrap it in the application's.
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/carlescere/scheduler"
+)
+
+// recentErrors is how many failures of each job the page shows.
+const recentErrors = 3
+
+//go:embed dashboard.html
+var page string
+
+var tmpl = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(page))
+
+// Handler serves the dashboard of a scheduler.
+type Handler struct {
+	s   *scheduler.Scheduler
+	mux *http.ServeMux
+	// Actor returns who makes a change requested with r, for the audit sink
+	// of the scheduler. By default it is "dashboard".
+	Actor func(r *http.Request) string
+	// Refresh is how often the page reloads itself, or never if it is 0.
+	Refresh time.Duration
+}
+
+// New returns the dashboard of s.
+func New(s *scheduler.Scheduler) *Handler {
+	h := &Handler{s: s, mux: http.NewServeMux(), Refresh: 5 * time.Second}
+	h.mux.HandleFunc("GET /{$}", h.index)
+	h.mux.HandleFunc("GET /jobs.json", h.list)
+	h.mux.HandleFunc("POST /pause", h.change(s.PauseJob))
+	h.mux.HandleFunc("POST /resume", h.change(s.ResumeJob))
+	h.mux.HandleFunc("POST /trigger", h.change(s.TriggerJob))
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Job is a row of the dashboard.
+type Job struct {
+	Name     string    `json:"name"`
+	Schedule string    `json:"schedule"`
+	Status   string    `json:"status"`
+	Paused   bool      `json:"paused"`
+	LastRun  time.Time `json:"lastRun,omitzero"`
+	NextRun  time.Time `json:"nextRun,omitzero"`
+	Runs     int       `json:"runs"`
+	Failed   int       `json:"failed"`
+	Errors   []Error   `json:"errors,omitempty"`
+}
+
+// Error is a recent failure of a job.
+type Error struct {
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+// jobs returns the rows of the dashboard.
+func (h *Handler) jobs() []Job {
+	var jobs []Job
+	for _, j := range h.s.Jobs() {
+		state := j.State()
+		row := Job{
+			Name:     state.Name,
+			Schedule: j.String(),
+			Status:   j.Status().String(),
+			Paused:   j.IsPaused(),
+			LastRun:  state.LastRun,
+			NextRun:  state.NextRun,
+			Runs:     state.Runs,
+			Failed:   state.Failed,
+		}
+		for _, err := range j.Errors(recentErrors) {
+			row.Errors = append(row.Errors, Error{Time: err.Time, Error: err.Error()})
+		}
+		jobs = append(jobs, row)
+	}
+	return jobs
+}
+
+func (h *Handler) index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := tmpl.Execute(w, struct {
+		Jobs    []Job
+		Refresh int
+	}{h.jobs(), int(h.Refresh / time.Second)})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.jobs())
+}
+
+// change returns a handler that applies f to the job named by the form field
+// job and sends the browser back to the page.
+func (h *Handler) change(f func(ctx context.Context, name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin request", http.StatusForbidden)
+			return
+		}
+		actor := "dashboard"
+		if h.Actor != nil {
+			actor = h.Actor(r)
+		}
+		name := r.FormValue("job")
+		if h.s.Job(name) == nil {
+			http.Error(w, "no job named "+name, http.StatusNotFound)
+			return
+		}
+		ctx := scheduler.WithActor(r.Context(), actor)
+		if err := f(ctx, name); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Redirect(w, r, "./", http.StatusSeeOther)
+	}
+}
+
+// sameOrigin rejects requests made by the pages of other sites, which
+// browsers mark with their Origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
@@ -0,0 +1,79 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/carlescere/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+type audit struct {
+	actors []string
+	sync.Mutex
+}
+
+func (a *audit) Audit(e scheduler.AuditEntry) error {
+	a.Lock()
+	defer a.Unlock()
+
+	a.actors = append(a.actors, e.Actor+" "+e.Action)
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	sink := &audit{}
+	s := scheduler.NewScheduler().WithAuditSink(sink)
+	j, err := s.Every(1).Hours().NotImmediately().Named("report").Run(func() {})
+	assert.Nil(t, err)
+	defer j.Stop()
+	h := New(s)
+	h.Actor = func(r *http.Request) string { return r.Header.Get("X-User") }
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	post := func(path, job, origin string) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(url.Values{"job": {job}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-User", "alice")
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	w := get("/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "report")
+	assert.Contains(t, w.Body.String(), `action="pause"`)
+
+	assert.Equal(t, http.StatusSeeOther, post("/pause", "report", ""))
+	assert.True(t, j.IsPaused())
+	assert.Contains(t, get("/").Body.String(), `action="resume"`)
+	assert.Equal(t, http.StatusSeeOther, post("/resume", "report", "http://example.com"))
+	assert.False(t, j.IsPaused())
+	assert.Equal(t, http.StatusForbidden, post("/pause", "report", "http://evil.example"))
+	assert.Equal(t, http.StatusNotFound, post("/trigger", "nope", ""))
+	assert.Equal(t, http.StatusMethodNotAllowed, get("/pause").Code)
+	assert.Equal(t, []string{"alice pause", "alice resume"}, sink.actors)
+
+	body := get("/jobs.json").Body.String()
+	assert.Contains(t, body, `"nextRun":`)
+	assert.NotContains(t, body, `"lastRun":`)
+	var jobs []Job
+	assert.Nil(t, json.Unmarshal([]byte(body), &jobs))
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "report", jobs[0].Name)
+	assert.Equal(t, "scheduled", jobs[0].Status)
+	assert.False(t, jobs[0].NextRun.IsZero())
+}
@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// dates runs at each of a sorted list of instants.
+type dates []time.Time
+
+// OnDates defines a job that runs once at each of the given instants that is
+// still to come, and then stops and leaves its scheduler, if any, e.g. for
+// one-off migrations or embargo lifts. Run returns an error if all of them are
+// past.
+func OnDates(times ...time.Time) *Job {
+	d := make(dates, len(times))
+	copy(d, times)
+	sort.Slice(d, func(i, j int) bool { return d[i].Before(d[j]) })
+	return &Job{schedule: d}
+}
+
+func (d dates) next(after time.Time) (time.Time, error) {
+	i := sort.Search(len(d), func(i int) bool { return d[i].After(after) })
+	if i == len(d) {
+		return time.Time{}, errNoNextRun
+	}
+	return d[i], nil
+}
+
+func (d dates) nextRun(now time.Time) (time.Duration, error) {
+	date, err := d.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+// Next implements Schedule.
+func (d dates) Next(after time.Time) time.Time {
+	date, _ := d.next(after)
+	return date
+}
+
+func (d dates) description() string {
+	if len(d) == 1 {
+		return "on " + d[0].Format(time.RFC3339)
+	}
+	return "on " + strconv.Itoa(len(d)) + " dates"
+}
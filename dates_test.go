@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnDates(t *testing.T) {
+	job := OnDates(date(2015, 6, 10, 9, 0), date(2015, 6, 1, 9, 0), date(2015, 7, 1, 0, 0))
+	assert.Equal(t, date(2015, 6, 1, 9, 0), job.Next(date(2015, 5, 1, 0, 0)))
+	assert.Equal(t, date(2015, 6, 10, 9, 0), job.Next(date(2015, 6, 1, 9, 0)))
+	assert.Equal(t, date(2015, 7, 1, 0, 0), job.Next(date(2015, 6, 10, 9, 0)))
+	assert.True(t, job.Next(date(2015, 7, 1, 0, 0)).IsZero())
+	assert.Equal(t, "on 3 dates", job.Description())
+}
+
+func TestOnDatesRun(t *testing.T) {
+	runs := make(chan bool, 2)
+	now := time.Now()
+	job, err := OnDates(now.Add(-time.Hour), now.Add(10*time.Millisecond), now.Add(20*time.Millisecond)).Run(func() { runs <- true })
+	assert.Nil(t, err)
+	<-runs
+	<-runs
+	for {
+		job.RLock()
+		exited := job.exited
+		job.RUnlock()
+		if exited {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, job.Healthy())
+	assert.Equal(t, Completed, job.Status())
+
+	s := NewScheduler()
+	job, err = s.OnDates(time.Now().Add(10 * time.Millisecond)).Run(func() { runs <- true })
+	assert.Nil(t, err)
+	assert.Equal(t, 1, s.Len())
+	<-runs
+	for s.Len() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for job.Status() != Completed {
+		time.Sleep(time.Millisecond)
+	}
+
+	_, err = OnDates(now.Add(-time.Hour)).Run(test)
+	assert.NotNil(t, err)
+	_, err = OnDates().Run(test)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"log/slog"
+	"time"
+)
+
+// SchedulerOption configures a scheduler created with NewScheduler.
+type SchedulerOption func(*Scheduler)
+
+// defaults are the settings of the jobs of a scheduler that do not set their
+// own, see NewScheduler.
+type defaults struct {
+	loc       *time.Location
+	logger    *slog.Logger
+	onFailure func(RunInfo, error)
+	timeout   time.Duration
+}
+
+// WithDefaultLocation sets the location daily, weekly and monthly jobs of the
+// scheduler are interpreted in unless they set one, see Timezone.
+func WithDefaultLocation(loc *time.Location) SchedulerOption {
+	return func(s *Scheduler) {
+		s.defaults.loc = loc
+	}
+}
+
+// WithDefaultLogger sets the logger of the jobs of the scheduler that do not
+// set one, see Job.Logger.
+func WithDefaultLogger(l *slog.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.defaults.logger = l
+	}
+}
+
+// WithDefaultErrorHandler sets the function called with the error of each
+// failed run of the jobs of the scheduler that do not set one with OnFailure.
+func WithDefaultErrorHandler(h func(RunInfo, error)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.defaults.onFailure = h
+	}
+}
+
+// WithDefaultTimeout limits how long each run of the jobs of the scheduler
+// may take, unless they set a timeout of their own. See Timeout.
+func WithDefaultTimeout(d time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.defaults.timeout = d
+	}
+}
+
+// applyDefaults gives the job the defaults of its scheduler for the settings
+// it does not set itself.
+func (j *Job) applyDefaults() {
+	if j.scheduler == nil {
+		return
+	}
+	s := j.scheduler
+	s.Lock()
+	d := s.defaults
+	s.Unlock()
+
+	j.Lock()
+	defer j.Unlock()
+	if c, ok := j.schedule.(calendar); ok && d.loc != nil && c.clock().loc == nil {
+		c.clock().loc = d.loc
+	}
+	if j.logger == nil {
+		j.logger = d.logger
+	}
+	if j.onFailure == nil {
+		j.onFailure = d.onFailure
+	}
+	if j.timeout == 0 && d.timeout > 0 {
+		j.timeout = d.timeout
+	}
+}
+
+// Logger sets a logger for the errors of the job: those that keep Run from
+// starting it or stop it later on, and the errors of failed runs.
+func (j *Job) Logger(l *slog.Logger) *Job {
+	j.logger = l
+	return j
+}
+
+// log logs an error of the job, if it has a logger.
+func (j *Job) log(err error) {
+	if j.logger != nil {
+		j.logger.Error("job failed", "job", j.Description(), "err", err)
+	}
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerDefaults(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	handled := make(chan error, 1)
+	s := NewScheduler(WithDefaultLocation(loc), WithDefaultLogger(logger),
+		WithDefaultTimeout(time.Minute),
+		WithDefaultErrorHandler(func(_ RunInfo, err error) { handled <- err }))
+
+	daily, err := s.Every().Day().At("08:00").Run(test)
+	assert.Nil(t, err)
+	defer daily.Stop()
+	from := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2015, 6, 2, 8, 0, 0, 0, loc), daily.Next(from))
+	assert.Equal(t, time.Minute, daily.timeout)
+
+	failing, err := s.Every(1).Hours().RunErr(func(context.Context) error {
+		return errors.New("boom")
+	})
+	assert.Nil(t, err)
+	defer failing.Stop()
+	assert.EqualError(t, <-handled, "boom")
+	assert.Contains(t, buf.String(), "err=boom")
+
+	own, err := s.Every().Day().At("08:00").Timezone("UTC").Timeout(time.Second).
+		Logger(slog.New(slog.DiscardHandler)).
+		OnFailure(func(RunInfo, error) {}).Run(test)
+	assert.Nil(t, err)
+	defer own.Stop()
+	assert.Equal(t, time.Date(2015, 6, 2, 8, 0, 0, 0, time.UTC), own.Next(from))
+	assert.Equal(t, time.Second, own.timeout)
+
+	_, err = Every().Day().At("08:00").Run(test)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,321 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// shardSize is how many jobs each goroutine of a dispatcher drives.
+const shardSize = 256
+
+// WithDispatcher makes the scheduler drive the jobs started from then on from
+// shared goroutines, each waiting on a few hundred jobs with a single timer,
+// rather than from a goroutine and a timer per job, so a scheduler can hold
+// 100k jobs or more in little memory. Inline jobs and jobs with a clock of
+// their own, see WithClock, keep a goroutine each. Jobs that share a goroutine
+// also share the callbacks it calls, those of OnStatusChange, Queue and
+// SkipAfterOverrun: they must not block or Reschedule the job.
+func (s *Scheduler) WithDispatcher() *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.shared == nil {
+		s.shared = &dispatcher{}
+	}
+	return s
+}
+
+// dispatcher drives the jobs of a scheduler from shared goroutines, see
+// WithDispatcher. It starts a goroutine for every shardSize jobs, which exits
+// once its jobs are gone.
+type dispatcher struct {
+	shards []*shard
+	sync.Mutex
+}
+
+// dispatcher returns the dispatcher that drives the job, if its scheduler has
+// one and the job can share it.
+func (j *Job) dispatcher() *dispatcher {
+	if j.scheduler == nil || j.inline || j.clock != nil {
+		return nil
+	}
+	s := j.scheduler
+	s.Lock()
+	defer s.Unlock()
+
+	return s.shared
+}
+
+// add hands a started job over to a shard with room for it.
+func (d *dispatcher) add(e *entry) {
+	d.Lock()
+	var sh *shard
+	for _, s := range d.shards {
+		if s.size < shardSize {
+			sh = s
+			break
+		}
+	}
+	if sh == nil {
+		sh = &shard{d: d, wake: make(chan struct{}, 1)}
+		d.shards = append(d.shards, sh)
+		go sh.run()
+	}
+	sh.size++
+	d.Unlock()
+
+	sh.Lock()
+	sh.added = append(sh.added, e)
+	sh.Unlock()
+	select {
+	case sh.wake <- struct{}{}:
+	default:
+	}
+}
+
+// entry is a job driven by a shard.
+type entry struct {
+	job    *Job
+	parent context.Context
+	cancel context.CancelFunc
+	// at is when the shard wakes the job, either for its next run or to
+	// check the wall clock, see untilDue.
+	at    time.Time
+	index int
+	slot  int
+}
+
+// shard is a goroutine of a dispatcher and the jobs it drives. It does for
+// each of them what the scheduling goroutine of a job does on its own, see
+// RunErr, waiting on the channels of all of them at once.
+type shard struct {
+	d *dispatcher
+	// size counts the jobs handed over to the shard and not yet gone; it is
+	// guarded by the dispatcher.
+	size int
+	// added holds the jobs handed over and not yet adopted by the goroutine.
+	added []*entry
+	wake  chan struct{}
+	// The rest is owned by the goroutine.
+	entries []*entry
+	due     dueHeap
+	timer   *time.Timer
+	cases   []reflect.SelectCase
+	sync.Mutex
+}
+
+// The cases of a shard's select: wake, the timer, then these for each job.
+const (
+	caseQuit = iota
+	caseParent
+	caseSkipWait
+	caseChanges
+	casesPerJob
+)
+
+func (sh *shard) run() {
+	sh.timer = time.NewTimer(time.Hour)
+	defer sh.timer.Stop()
+	for {
+		if !sh.adopt() {
+			return
+		}
+		sh.arm()
+		chosen, recv, _ := reflect.Select(sh.cases)
+		switch chosen {
+		case 0:
+		case 1:
+			sh.expire()
+		default:
+			e := sh.entries[(chosen-2)/casesPerJob]
+			sh.handle(e, (chosen-2)%casesPerJob, recv)
+		}
+	}
+}
+
+// adopt takes in the jobs handed over to the shard. It returns false, once the
+// shard has retired from the dispatcher, if it has no jobs left.
+func (sh *shard) adopt() bool {
+	sh.Lock()
+	added := sh.added
+	sh.added = nil
+	sh.Unlock()
+	if sh.cases == nil {
+		sh.cases = []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sh.wake)},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(sh.timer.C)},
+		}
+	}
+	for _, e := range added {
+		e.slot = len(sh.entries)
+		sh.entries = append(sh.entries, e)
+		heap.Push(&sh.due, e)
+		sh.cases = append(sh.cases,
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e.job.Quit)},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e.parent.Done())},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e.job.SkipWait)},
+			reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(e.job.changes)})
+	}
+	if len(sh.entries) > 0 {
+		return true
+	}
+	d := sh.d
+	d.Lock()
+	defer d.Unlock()
+
+	if sh.size > 0 {
+		// A job is being handed over.
+		return true
+	}
+	for i, s := range d.shards {
+		if s == sh {
+			d.shards = append(d.shards[:i], d.shards[i+1:]...)
+			break
+		}
+	}
+	return false
+}
+
+// arm sets the shard's timer to wake it when its first job is due.
+func (sh *shard) arm() {
+	if !sh.timer.Stop() {
+		select {
+		case <-sh.timer.C:
+		default:
+		}
+	}
+	if len(sh.due) > 0 {
+		sh.timer.Reset(time.Until(sh.due[0].at))
+	}
+}
+
+// expire starts the runs that are due.
+func (sh *shard) expire() {
+	now := time.Now()
+	for len(sh.due) > 0 && !sh.due[0].at.After(now) {
+		e := sh.due[0]
+		if sh.quitting(e) {
+			continue
+		}
+		j := e.job
+		if d := j.untilDue(); d > 0 {
+			sh.schedule(e, d)
+			continue
+		}
+		j.RLock()
+		due := j.nextAt
+		j.RUnlock()
+		j.dispatch(nil, due)
+		sh.advance(e)
+	}
+}
+
+// handle handles what a job received on one of its channels.
+func (sh *shard) handle(e *entry, c int, recv reflect.Value) {
+	j := e.job
+	switch c {
+	case caseQuit:
+		j.setCause(ErrStopped)
+		sh.remove(e)
+		return
+	case caseParent:
+		j.setCause(context.Cause(e.parent))
+		sh.remove(e)
+		return
+	case caseChanges:
+		next := recv.Interface().(change)
+		j.Lock()
+		j.schedule = next.schedule
+		j.Unlock()
+		if next.done != nil {
+			defer close(next.done)
+		}
+	}
+	if sh.quitting(e) {
+		return
+	}
+	if c == caseSkipWait {
+		j.dispatch(j.takePayload(), time.Time{})
+	}
+	sh.advance(e)
+}
+
+// quitting removes the job and returns true if it was stopped, as a pending
+// Quit wins over anything else the job has to do.
+func (sh *shard) quitting(e *entry) bool {
+	select {
+	case <-e.job.Quit:
+		e.job.setCause(ErrStopped)
+	case <-e.parent.Done():
+		e.job.setCause(context.Cause(e.parent))
+	default:
+		return false
+	}
+	sh.remove(e)
+	return true
+}
+
+// advance schedules the job's next run, or removes the job if it has none.
+func (sh *shard) advance(e *entry) {
+	wait, ok := e.job.advance()
+	if !ok {
+		sh.remove(e)
+		return
+	}
+	sh.schedule(e, wait)
+}
+
+// schedule wakes the job again after d.
+func (sh *shard) schedule(e *entry, d time.Duration) {
+	e.at = time.Now().Add(d)
+	heap.Fix(&sh.due, e.index)
+}
+
+// remove lets the job go, once its scheduling is over.
+func (sh *shard) remove(e *entry) {
+	heap.Remove(&sh.due, e.index)
+	last := len(sh.entries) - 1
+	moved := sh.entries[last]
+	sh.entries[e.slot], moved.slot = moved, e.slot
+	sh.entries = sh.entries[:last]
+	first := 2 + e.slot*casesPerJob
+	copy(sh.cases[first:first+casesPerJob], sh.cases[2+last*casesPerJob:])
+	sh.cases = sh.cases[:2+last*casesPerJob]
+
+	d := sh.d
+	d.Lock()
+	sh.size--
+	d.Unlock()
+
+	e.job.exit()
+	e.cancel()
+	e.job.unsubscribe()
+}
+
+// dueHeap orders the jobs of a shard by when they must be woken.
+type dueHeap []*entry
+
+func (h dueHeap) Len() int           { return len(h) }
+func (h dueHeap) Less(i, k int) bool { return h[i].at.Before(h[k].at) }
+
+func (h dueHeap) Swap(i, k int) {
+	h[i], h[k] = h[k], h[i]
+	h[i].index = i
+	h[k].index = k
+}
+
+func (h *dueHeap) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *dueHeap) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
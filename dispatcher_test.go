@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func shards(s *Scheduler) int {
+	s.shared.Lock()
+	defer s.shared.Unlock()
+
+	return len(s.shared.shards)
+}
+
+func TestWithDispatcher(t *testing.T) {
+	s := NewScheduler().WithDispatcher()
+	before := runtime.NumGoroutine()
+	var jobs []*Job
+	for i := 0; i < 2*shardSize+1; i++ {
+		job, err := s.Every(1).Hours().NotImmediately().Run(test)
+		assert.Nil(t, err)
+		jobs = append(jobs, job)
+	}
+	assert.Equal(t, 3, shards(s))
+	assert.LessOrEqual(t, runtime.NumGoroutine()-before, 3)
+
+	c := make(chan []byte, 2)
+	job, err := s.Every(1).Hours().NotImmediately().RunCtx(func(ctx context.Context) { c <- Payload(ctx) })
+	assert.Nil(t, err)
+	job.SkipWait <- true
+	assert.Nil(t, <-c)
+	assert.True(t, job.TriggerWith([]byte("v1")))
+	assert.Equal(t, []byte("v1"), <-c)
+
+	assert.Nil(t, job.Reschedule(Every(10).Minutes().NotImmediately()))
+	assert.Equal(t, "every 10m0s", job.Description())
+	job.Stop()
+	<-job.ctx.Done()
+	assert.Equal(t, ErrStopped, job.StopCause())
+	assert.Equal(t, Stopped, job.Status())
+
+	for _, job := range jobs {
+		job.Quit <- true
+	}
+	for shards(s) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcherRuns(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s := NewScheduler(WithContext(ctx)).WithDispatcher()
+	ticks := make(chan bool, 3)
+	tick, err := s.EveryDuration(10 * time.Millisecond).Run(func() { ticks <- true })
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		assert.True(t, <-ticks)
+	}
+
+	done := make(chan bool)
+	_, err = s.After(10 * time.Millisecond).Run(func() { done <- true })
+	assert.Nil(t, err)
+	assert.True(t, <-done)
+	for s.Len() > 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel(ErrShutdown)
+	for tick.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, ErrShutdown, tick.StopCause())
+	for shards(s) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDispatcherSkipsInline(t *testing.T) {
+	s := NewScheduler().WithDispatcher()
+	job, err := s.Every(1).Hours().NotImmediately().RunInline(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Nil(t, job.dispatcher())
+	assert.Equal(t, 0, shards(s))
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// exclusions holds a semaphore for each exclusion key, see ExclusiveWith.
+var exclusions struct {
+	keys map[string]chan struct{}
+	sync.Mutex
+}
+
+// exclusion returns the semaphore of key.
+func exclusion(key string) chan struct{} {
+	exclusions.Lock()
+	defer exclusions.Unlock()
+
+	if exclusions.keys == nil {
+		exclusions.keys = make(map[string]chan struct{})
+	}
+	sem, ok := exclusions.keys[key]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		exclusions.keys[key] = sem
+	}
+	return sem
+}
+
+// ExclusiveWith keeps the runs of the job from overlapping those of any other
+// job of the process given the same key, e.g. jobs that lock the same table:
+//
+//	scheduler.Every().Day().At("03:00").ExclusiveWith("db-maintenance").Run(vacuum)
+//	scheduler.Every(1).Hours().ExclusiveWith("db-maintenance").Run(reindex)
+//
+// A run due while another job with the key is running waits for it to finish,
+// as long as the run's context lasts, see Timeout; it is counted as skipped if
+// the context ends first.
+func (j *Job) ExclusiveWith(key string) *Job {
+	if j.err != nil {
+		return j
+	}
+	if key == "" {
+		j.err = errors.New("empty exclusion key")
+		return j
+	}
+	j.exclusive = key
+	return j
+}
+
+// exclude takes the job's exclusion for a run, waiting for other jobs with
+// the same key to finish. It returns false if ctx ends first.
+func (j *Job) exclude(ctx context.Context) (release func(), ok bool) {
+	if j.exclusive == "" {
+		return func() {}, true
+	}
+	sem := exclusion(j.exclusive)
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, true
+	case <-ctx.Done():
+		return nil, false
+	}
+}
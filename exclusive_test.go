@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExclusiveWith(t *testing.T) {
+	var running, overlaps int32
+	run := func() {
+		if atomic.AddInt32(&running, 1) > 1 {
+			atomic.AddInt32(&overlaps, 1)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	}
+	vacuum, err := Every(1).Hours().ExclusiveWith("test-table").Run(run)
+	assert.Nil(t, err)
+	defer vacuum.Stop()
+	reindex, err := Every(1).Hours().ExclusiveWith("test-table").Run(run)
+	assert.Nil(t, err)
+	defer reindex.Stop()
+	for i := 1; i <= 4; i++ {
+		for vacuum.Stats().Finished < i || reindex.Stats().Finished < i {
+			time.Sleep(time.Millisecond)
+		}
+		vacuum.Trigger()
+		reindex.Trigger()
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&overlaps))
+
+	_, err = Every(1).Hours().ExclusiveWith("").Run(test)
+	assert.EqualError(t, err, "empty exclusion key")
+}
+
+func TestExclusiveWithTimeout(t *testing.T) {
+	release := make(chan bool)
+	holder, err := Every(1).Hours().ExclusiveWith("test-timeout").Run(func() { <-release })
+	assert.Nil(t, err)
+	defer holder.Stop()
+	for !holder.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	ran := make(chan bool, 1)
+	waiter, err := Every(1).Hours().ExclusiveWith("test-timeout").Timeout(10 * time.Millisecond).
+		RunCtx(func(context.Context) { ran <- true })
+	assert.Nil(t, err)
+	defer waiter.Stop()
+	for waiter.Stats().Skipped == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	assert.Len(t, ran, 0)
+	assert.Equal(t, 0, waiter.Stats().Runs)
+}
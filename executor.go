@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+)
+
+// JobRef identifies a run handed to an Executor.
+type JobRef struct {
+	// Name is the job's name, set with Named.
+	Name string
+	// Description describes the job's schedule.
+	Description string
+	// Started is when the run started.
+	Started time.Time
+}
+
+// Executor runs jobs outside of the scheduler, e.g. by publishing them to a
+// message queue, starting a subprocess or calling a service. Execute returns
+// once the run is done, or handed off if the executor does not wait for it;
+// its error counts as the run's, so Retry, BreakAfter and the other failure
+// handling still apply.
+type Executor interface {
+	Execute(ctx context.Context, job JobRef) error
+}
+
+// WithExecutor makes the scheduler hand every run of its jobs to e instead of
+// calling their function, which may then be nil:
+//
+//	s := scheduler.NewScheduler().WithExecutor(queue)
+//	s.Every().Day().At("03:00").Named("cleanup").Run(nil)
+func (s *Scheduler) WithExecutor(e Executor) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.executor = e
+	return s
+}
+
+// function returns what a run of the job calls: its scheduler's executor, if
+// there is one, or else its function.
+func (j *Job) function() func(context.Context) error {
+	j.RLock()
+	fn := j.fn
+	j.RUnlock()
+	if j.scheduler == nil {
+		return fn
+	}
+	s := j.scheduler
+	s.Lock()
+	e := s.executor
+	s.Unlock()
+	if e == nil {
+		return fn
+	}
+	j.RLock()
+	ref := JobRef{Name: j.name, Description: j.describe(), Started: j.startedAt}
+	j.RUnlock()
+	return func(ctx context.Context) error {
+		return e.Execute(ctx, ref)
+	}
+}
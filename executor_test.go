@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type executorFunc func(ctx context.Context, job JobRef) error
+
+func (f executorFunc) Execute(ctx context.Context, job JobRef) error {
+	return f(ctx, job)
+}
+
+func TestWithExecutor(t *testing.T) {
+	refs := make(chan JobRef, 10)
+	s := NewScheduler().WithExecutor(executorFunc(func(ctx context.Context, job JobRef) error {
+		refs <- job
+		return errors.New("queue full")
+	}))
+	job, err := s.Every(1).Hours().Named("cleanup").Retry(2, 0).Run(nil)
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	ref := <-refs
+	assert.Equal(t, "cleanup", ref.Name)
+	assert.Equal(t, "every 1h0m0s", ref.Description)
+	assert.False(t, ref.Started.IsZero())
+	assert.Equal(t, ref, <-refs)
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, Stats{Runs: 1, Failed: 1}, counters(job.Stats()))
+}
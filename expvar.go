@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+)
+
+// publishing makes checking for and publishing an expvar one step, as Publish
+// panics if the name is taken.
+var publishing sync.Mutex
+
+// jobVars are the counters published for a job, or summed over all of them.
+type jobVars struct {
+	Runs    int `json:"runs"`
+	Errors  int `json:"errors"`
+	Skipped int `json:"skipped"`
+	Active  int `json:"active"`
+	// Drift is in seconds.
+	Drift float64 `json:"drift"`
+	// P50, P95 and P99 are the percentiles of the durations of the runs, in
+	// seconds. They are only given for jobs, not in total.
+	P50 float64 `json:"p50,omitempty"`
+	P95 float64 `json:"p95,omitempty"`
+	P99 float64 `json:"p99,omitempty"`
+}
+
+func (v *jobVars) add(o jobVars) {
+	v.Runs += o.Runs
+	v.Errors += o.Errors
+	v.Skipped += o.Skipped
+	v.Active += o.Active
+	if o.Drift > v.Drift {
+		v.Drift = o.Drift
+	}
+}
+
+// PublishExpvar publishes the counters of the scheduler's jobs with expvar, as
+// a variable called name that is shown in /debug/vars:
+//
+//	"scheduler": {"runs": 12, "errors": 1, "skipped": 0, "active": 1, "drift": 0.002,
+//		"jobs": {"report": {"runs": 12, "errors": 1, "skipped": 0, "active": 1, "drift": 0.002}}}
+//
+// Runs, errors and skipped runs count as in Stats, active is the number of
+// runs in progress and drift, in seconds, is the Drift of the last run. Jobs
+// that have finished runs also list the P50, P95 and P99 of Stats, in seconds,
+// as p50, p95 and p99. The totals cover every job, with the largest drift, but
+// only named jobs are listed.
+// It fails if a variable with the name is already published.
+func (s *Scheduler) PublishExpvar(name string) error {
+	publishing.Lock()
+	defer publishing.Unlock()
+
+	if expvar.Get(name) != nil {
+		return errors.New("expvar " + name + " already published")
+	}
+	expvar.Publish(name, expvar.Func(s.vars))
+	return nil
+}
+
+func (s *Scheduler) vars() interface{} {
+	var total jobVars
+	jobs := make(map[string]jobVars)
+	for _, j := range s.snapshot() {
+		j.RLock()
+		v := jobVars{Runs: j.stats.Runs, Errors: j.stats.Failed, Skipped: j.stats.Skipped, Drift: j.stats.Drift.Seconds()}
+		if j.isRunning {
+			v.Active = 1 + j.pending
+		}
+		name := j.name
+		j.RUnlock()
+		stats := j.Stats()
+		v.P50, v.P95, v.P99 = stats.P50.Seconds(), stats.P95.Seconds(), stats.P99.Seconds()
+
+		total.add(v)
+		if name != "" {
+			jobs[name] = v
+		}
+	}
+	return struct {
+		jobVars
+		Jobs map[string]jobVars `json:"jobs"`
+	}{total, jobs}
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvarConcurrently(t *testing.T) {
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { errs <- NewScheduler().PublishExpvar("scheduler_race_test") }()
+	}
+	first, second := <-errs, <-errs
+	assert.True(t, (first == nil) != (second == nil))
+}
+
+func TestPublishExpvar(t *testing.T) {
+	s := NewScheduler()
+	assert.Nil(t, s.PublishExpvar("scheduler_test"))
+	assert.NotNil(t, s.PublishExpvar("scheduler_test"))
+
+	release := make(chan bool)
+	report, err := s.Every(1).Hours().Named("report").Run(func() { <-release })
+	assert.Nil(t, err)
+	defer report.Stop()
+	unnamed, err := s.Every(1).Hours().RunErr(func(context.Context) error {
+		return errors.New("boom")
+	})
+	assert.Nil(t, err)
+	defer unnamed.Stop()
+	for !report.IsRunning() || unnamed.Stats().Failed == 0 || unnamed.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+
+	var got map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(expvar.Get("scheduler_test").String()), &got))
+	vars := got["jobs"].(map[string]interface{})["report"].(map[string]interface{})
+	assert.True(t, got["drift"].(float64) >= vars["drift"].(float64))
+	delete(got, "drift")
+	delete(vars, "drift")
+	assert.Equal(t, map[string]interface{}{
+		"runs": 2.0, "errors": 1.0, "skipped": 0.0, "active": 1.0,
+		"jobs": map[string]interface{}{
+			"report": map[string]interface{}{"runs": 1.0, "errors": 0.0, "skipped": 0.0, "active": 1.0},
+		},
+	}, got)
+
+	close(release)
+	for report.Stats().Finished == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, json.Unmarshal([]byte(expvar.Get("scheduler_test").String()), &got))
+	vars = got["jobs"].(map[string]interface{})["report"].(map[string]interface{})
+	assert.Contains(t, vars, "p99")
+	assert.NotContains(t, got, "p99")
+}
@@ -0,0 +1,150 @@
+// Package grpcapi serves the control API of a running scheduler over gRPC, so
+// external tools can list, pause, resume and trigger its named jobs and change
+// their schedules. The service is defined in controlpb/control.proto.
+//
+//	g := grpc.NewServer()
+//	grpcapi.Register(g, s)
+//	g.Serve(listener)
+//
+// Changes are made on behalf of the actor of the request's context, see
+// scheduler.WithActor, and recorded as such by the scheduler's audit sink. An
+// interceptor can set it from the caller's credentials:
+//
+//	g := grpc.NewServer(grpc.UnaryInterceptor(
+//		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+//			return next(scheduler.WithActor(ctx, userOf(ctx)), req)
+//		}))
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/carlescere/scheduler/grpcapi/controlpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements controlpb.SchedulerControlServer for a scheduler.
+type Server struct {
+	controlpb.UnimplementedSchedulerControlServer
+	s *scheduler.Scheduler
+}
+
+// NewServer returns a server controlling s.
+func NewServer(s *scheduler.Scheduler) *Server {
+	return &Server{s: s}
+}
+
+// Register registers a server controlling s with g.
+func Register(g *grpc.Server, s *scheduler.Scheduler) {
+	controlpb.RegisterSchedulerControlServer(g, NewServer(s))
+}
+
+// ListJobs implements controlpb.SchedulerControlServer.
+func (srv *Server) ListJobs(ctx context.Context, req *controlpb.ListJobsRequest) (*controlpb.ListJobsResponse, error) {
+	resp := &controlpb.ListJobsResponse{}
+	for _, j := range srv.s.Jobs() {
+		resp.Jobs = append(resp.Jobs, job(j))
+	}
+	return resp, nil
+}
+
+// GetJob implements controlpb.SchedulerControlServer.
+func (srv *Server) GetJob(ctx context.Context, req *controlpb.JobRequest) (*controlpb.Job, error) {
+	j, err := srv.find(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return job(j), nil
+}
+
+// Pause implements controlpb.SchedulerControlServer.
+func (srv *Server) Pause(ctx context.Context, req *controlpb.JobRequest) (*controlpb.Job, error) {
+	j, err := srv.find(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.s.PauseJob(ctx, j.Name()); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return job(j), nil
+}
+
+// Resume implements controlpb.SchedulerControlServer.
+func (srv *Server) Resume(ctx context.Context, req *controlpb.JobRequest) (*controlpb.Job, error) {
+	j, err := srv.find(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.s.ResumeJob(ctx, j.Name()); err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return job(j), nil
+}
+
+// Trigger implements controlpb.SchedulerControlServer. It fails with
+// FailedPrecondition if a run of the job was already requested.
+func (srv *Server) Trigger(ctx context.Context, req *controlpb.JobRequest) (*controlpb.Job, error) {
+	j, err := srv.find(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.s.TriggerJob(ctx, j.Name()); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return job(j), nil
+}
+
+// UpdateSchedule implements controlpb.SchedulerControlServer.
+func (srv *Server) UpdateSchedule(ctx context.Context, req *controlpb.UpdateScheduleRequest) (*controlpb.Job, error) {
+	j, err := srv.find(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := scheduler.ParseSchedule(req.GetSchedule())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := srv.s.RescheduleJob(ctx, j.Name(), schedule); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return job(j), nil
+}
+
+func (srv *Server) find(name string) (*scheduler.Job, error) {
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "job name required")
+	}
+	j := srv.s.Job(name)
+	if j == nil {
+		return nil, status.Error(codes.NotFound, "no job named "+name)
+	}
+	return j, nil
+}
+
+func job(j *scheduler.Job) *controlpb.Job {
+	state, stats := j.State(), j.Stats()
+	return &controlpb.Job{
+		Name:        state.Name,
+		Description: j.Description(),
+		Paused:      j.IsPaused(),
+		Running:     state.Running,
+		LastRun:     timestamp(state.LastRun),
+		NextRun:     timestamp(state.NextRun),
+		Runs:        int64(stats.Runs),
+		Skipped:     int64(stats.Skipped),
+		Failed:      int64(stats.Failed),
+		LastError:   state.LastError,
+	}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
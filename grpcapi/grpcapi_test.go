@@ -0,0 +1,84 @@
+package grpcapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/carlescere/scheduler"
+	"github.com/carlescere/scheduler/grpcapi/controlpb"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type memoryAudit struct {
+	entries []scheduler.AuditEntry
+	sync.Mutex
+}
+
+func (m *memoryAudit) Audit(entry scheduler.AuditEntry) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryAudit) actions() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	var actions []string
+	for _, e := range m.entries {
+		actions = append(actions, e.Actor+" "+e.Action+" "+e.Job)
+	}
+	return actions
+}
+
+func TestServer(t *testing.T) {
+	sink := &memoryAudit{}
+	s := scheduler.NewScheduler().WithAuditSink(sink)
+	ran := make(chan bool, 1)
+	j, err := s.Every(1).Hours().NotImmediately().Named("report").Run(func() { ran <- true })
+	assert.Nil(t, err)
+	defer j.Stop()
+	srv, ctx := NewServer(s), scheduler.WithActor(context.Background(), "alice")
+
+	list, err := srv.ListJobs(ctx, &controlpb.ListJobsRequest{})
+	assert.Nil(t, err)
+	if assert.Len(t, list.Jobs, 1) {
+		assert.Equal(t, "report", list.Jobs[0].Name)
+		assert.Equal(t, "every 1h0m0s", list.Jobs[0].Description)
+		assert.NotNil(t, list.Jobs[0].NextRun)
+		assert.Nil(t, list.Jobs[0].LastRun)
+	}
+
+	paused, err := srv.Pause(ctx, &controlpb.JobRequest{Name: "report"})
+	assert.Nil(t, err)
+	assert.True(t, paused.Paused)
+	resumed, err := srv.Resume(ctx, &controlpb.JobRequest{Name: "report"})
+	assert.Nil(t, err)
+	assert.False(t, resumed.Paused)
+
+	_, err = srv.Trigger(ctx, &controlpb.JobRequest{Name: "report"})
+	assert.Nil(t, err)
+	assert.True(t, <-ran)
+
+	_, err = srv.UpdateSchedule(ctx, &controlpb.UpdateScheduleRequest{Name: "report", Schedule: "daily 08:30"})
+	assert.Nil(t, err)
+	got, err := srv.GetJob(ctx, &controlpb.JobRequest{Name: "report"})
+	assert.Nil(t, err)
+	assert.Equal(t, "every day at 08:30:00", got.Description)
+
+	_, err = srv.UpdateSchedule(ctx, &controlpb.UpdateScheduleRequest{Name: "report", Schedule: "daily 25:00"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = srv.GetJob(ctx, &controlpb.JobRequest{Name: "cleanup"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, []string{
+		"alice pause report",
+		"alice resume report",
+		"alice trigger report",
+		"alice reschedule report",
+	}, sink.actions())
+}
@@ -0,0 +1,247 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// errorWindow is the number of recent runs over which MaxErrorRate is checked.
+const errorWindow = 20
+
+// outcomes records whether the most recent runs of a job failed.
+type outcomes struct {
+	failed [errorWindow]bool
+	n      int
+	next   int
+}
+
+func (o *outcomes) add(failed bool) {
+	o.failed[o.next] = failed
+	o.next = (o.next + 1) % errorWindow
+	if o.n < errorWindow {
+		o.n++
+	}
+}
+
+// rate returns the share of the recorded runs that failed.
+func (o *outcomes) rate() float64 {
+	if o.n == 0 {
+		return 0
+	}
+	failed := 0
+	for i := 0; i < o.n; i++ {
+		if o.failed[i] {
+			failed++
+		}
+	}
+	return float64(failed) / float64(o.n)
+}
+
+// errorHistory is the number of recent failures kept for Errors.
+const errorHistory = 32
+
+// RunError is the error of a failed run and when it failed.
+type RunError struct {
+	Time time.Time
+	Err  error
+}
+
+func (e RunError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the run.
+func (e RunError) Unwrap() error {
+	return e.Err
+}
+
+// LastError returns the error of the job's last run, or nil if it succeeded.
+// The errors of earlier runs are returned by Errors.
+func (j *Job) LastError() error {
+	j.RLock()
+	defer j.RUnlock()
+
+	return j.lastErr
+}
+
+// Errors returns up to n of the most recent failures of the job, the latest
+// first. Only the last 32 failures are kept.
+func (j *Job) Errors(n int) []RunError {
+	j.RLock()
+	defer j.RUnlock()
+
+	if n > len(j.errs) {
+		n = len(j.errs)
+	}
+	if n <= 0 {
+		return nil
+	}
+	errs := make([]RunError, n)
+	for i := range errs {
+		errs[i] = j.errs[len(j.errs)-1-i]
+	}
+	return errs
+}
+
+// addError records a failure in the job's history. It must be called with the
+// job locked.
+func (j *Job) addError(err error) {
+	if len(j.errs) == errorHistory {
+		copy(j.errs, j.errs[1:])
+		j.errs = j.errs[:errorHistory-1]
+	}
+	j.errs = append(j.errs, RunError{Time: j.now(), Err: err})
+}
+
+// Timeout limits how long each run of the job may take. The context given to
+// RunCtx and RunErr functions expires after d, and Healthy reports runs that
+// go on longer than that.
+func (j *Job) Timeout(d time.Duration) *Job {
+	if j.err != nil {
+		return j
+	}
+	if d <= 0 {
+		j.err = errors.New("bad timeout")
+		return j
+	}
+	j.timeout = d
+	return j
+}
+
+// MaxErrorRate makes Healthy report a job run with RunErr when more than rate,
+// from 0 to 1, of its recent runs failed.
+func (j *Job) MaxErrorRate(rate float64) *Job {
+	if j.err != nil {
+		return j
+	}
+	if rate < 0 || rate > 1 {
+		j.err = errors.New("bad error rate")
+		return j
+	}
+	j.maxErrors = rate
+	return j
+}
+
+// errorsBuffer is how many errors the channel returned by Scheduler.Errors
+// holds before further ones are dropped.
+const errorsBuffer = 64
+
+// JobError is an error of a job of a scheduler, see Scheduler.Errors.
+type JobError struct {
+	Job  *Job
+	Time time.Time
+	Err  error
+}
+
+func (e JobError) Error() string {
+	return "job " + e.Job.Description() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the job.
+func (e JobError) Unwrap() error {
+	return e.Err
+}
+
+// Errors returns a channel that receives the errors of all the scheduler's
+// jobs: those that keep Run from starting a job or stop it later on, and the
+// errors of failed runs. The channel is never closed. Errors are dropped
+// rather than delay the jobs if it is full, so it should be drained
+// continuously.
+func (s *Scheduler) Errors() <-chan JobError {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.errs == nil {
+		s.errs = make(chan JobError, errorsBuffer)
+	}
+	return s.errs
+}
+
+// report logs an error of the job and sends it to its scheduler's Errors
+// channel, if anybody asked for it.
+func (j *Job) report(err error) {
+	j.log(err)
+	if j.scheduler == nil {
+		return
+	}
+	s := j.scheduler
+	s.Lock()
+	errs := s.errs
+	s.Unlock()
+	if errs == nil {
+		return
+	}
+	select {
+	case errs <- JobError{Job: j, Time: j.now(), Err: err}:
+	default:
+	}
+}
+
+// stopped records the error that stopped the job's scheduling goroutine.
+func (j *Job) stopped(err error) {
+	j.report(err)
+	j.setCause(err)
+	err = fmt.Errorf("job %s: %w", j.Description(), err)
+	j.Lock()
+	j.stopErr = err
+	j.Unlock()
+
+	if j.scheduler != nil {
+		j.scheduler.fail(err)
+	}
+}
+
+// Healthy returns an error if the job stopped on a scheduling error, if its
+// current run has gone on past its Timeout or stalled, see StallAfter, or if
+// its recent runs failed more often than MaxErrorRate allows. It returns nil
+// otherwise.
+func (j *Job) Healthy() error {
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.stopErr != nil {
+		return j.stopErr
+	}
+	if j.isRunning && j.timeout > 0 {
+		if d := j.now().Sub(j.startedAt); d > j.timeout {
+			return errors.New("job " + j.describe() + ": run stuck for " + d.String())
+		}
+	}
+	if silent, stalled := j.stalledFor(); stalled {
+		return errors.New("job " + j.describe() + ": run stalled, no heartbeat for " + silent.String())
+	}
+	if j.maxErrors > 0 {
+		if rate := j.outcomes.rate(); rate > j.maxErrors {
+			return errors.New("job " + j.describe() + ": error rate " + percent(rate) + " above " + percent(j.maxErrors))
+		}
+	}
+	return nil
+}
+
+func percent(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', -1, 64) + "%"
+}
+
+// Healthy returns the last error of the scheduler's store or locker, if it
+// has not worked since, or else the first error reported by the Healthy method of the
+// scheduler's jobs, or nil if they are all healthy. It is meant for health
+// endpoints and liveness probes.
+func (s *Scheduler) Healthy() error {
+	s.Lock()
+	err := s.storeErr
+	if err == nil {
+		err = s.lockErr
+	}
+	s.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, j := range s.snapshot() {
+		if err := j.Healthy(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthyStuck(t *testing.T) {
+	s := NewScheduler()
+	release := make(chan bool)
+	defer close(release)
+	var canceled int32
+	job, err := s.Every(1).Hours().Timeout(10 * time.Millisecond).RunCtx(func(ctx context.Context) {
+		<-ctx.Done()
+		atomic.StoreInt32(&canceled, 1)
+		<-release
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Nil(t, s.Healthy())
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&canceled))
+	assert.Contains(t, s.Healthy().Error(), "job every 1h0m0s: run stuck for")
+}
+
+func TestHealthyErrorRate(t *testing.T) {
+	fail := int32(1)
+	runs := make(chan bool)
+	job, err := Every(1).Hours().MaxErrorRate(0.5).RunErr(func(context.Context) error {
+		defer func() { runs <- true }()
+		if atomic.LoadInt32(&fail) == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	<-runs
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.EqualError(t, job.Healthy(), "job every 1h0m0s: error rate 100% above 50%")
+	assert.Equal(t, 1, job.Stats().Failed)
+
+	atomic.StoreInt32(&fail, 0)
+	job.SkipWait <- true
+	<-runs
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, job.Healthy())
+}
+
+func TestHealthyStopped(t *testing.T) {
+	s := NewScheduler()
+	var calls int32
+	_, err := s.bind(FromQuartz(quartzOnce{calls: &calls})).Run(test)
+	assert.Nil(t, err)
+	for s.Healthy() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	assert.EqualError(t, s.Healthy(), "job once: trigger broken")
+}
+
+func TestErrors(t *testing.T) {
+	var n int32
+	job, err := Every(1).Hours().NotImmediately().RunErr(func(context.Context) error {
+		if i := atomic.AddInt32(&n, 1); i <= errorHistory+2 {
+			return fmt.Errorf("failure %d", i)
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Nil(t, job.LastError())
+	assert.Nil(t, job.Errors(5))
+
+	for i := 0; i < errorHistory+2; i++ {
+		job.SkipWait <- true
+		for job.Stats().Failed <= i || job.IsRunning() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	assert.EqualError(t, job.LastError(), fmt.Sprintf("failure %d", errorHistory+2))
+	errs := job.Errors(2)
+	if assert.Len(t, errs, 2) {
+		assert.EqualError(t, errs[0], fmt.Sprintf("failure %d", errorHistory+2))
+		assert.EqualError(t, errs[1].Err, fmt.Sprintf("failure %d", errorHistory+1))
+		assert.False(t, errs[0].Time.Before(errs[1].Time))
+	}
+	all := job.Errors(100)
+	assert.Len(t, all, errorHistory)
+	assert.EqualError(t, all[errorHistory-1], "failure 3")
+
+	job.SkipWait <- true
+	for job.Stats().Runs < errorHistory+3 || job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, job.LastError())
+	assert.Len(t, job.Errors(100), errorHistory)
+}
+
+func TestSchedulerErrors(t *testing.T) {
+	s := NewScheduler()
+	errs := s.Errors()
+	assert.Equal(t, errs, s.Errors())
+
+	_, err := s.Every(1).Hours().At("08:00").Run(test)
+	assert.NotNil(t, err)
+	e := <-errs
+	assert.Equal(t, err, e.Err)
+	assert.False(t, e.Time.IsZero())
+
+	job, err := s.Every(1).Hours().RunErr(func(context.Context) error {
+		return errors.New("boom")
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	e = <-errs
+	assert.Equal(t, job, e.Job)
+	assert.EqualError(t, e, "job every 1h0m0s: boom")
+	assert.True(t, errors.Is(e, e.Err))
+
+	// Errors of jobs of no scheduler go nowhere.
+	_, err = Every(1).Hours().At("08:00").Run(test)
+	assert.NotNil(t, err)
+	assert.Len(t, errs, 0)
+}
+
+func TestBadHealthOptions(t *testing.T) {
+	for _, job := range []*Job{
+		Every(1).Hours().Timeout(0),
+		Every(1).Hours().MaxErrorRate(2),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Heartbeat lets a long run tell the scheduler it is still making progress,
+// see StallAfter. Runs get theirs from HeartbeatFrom.
+type Heartbeat struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	last    time.Time
+	stalled bool
+}
+
+type heartbeatKey struct{}
+
+// HeartbeatFrom returns the heartbeat of the run with context ctx. Runs of
+// jobs without StallAfter get a nil one, whose Beat does nothing, so job
+// functions may beat unconditionally.
+func HeartbeatFrom(ctx context.Context) *Heartbeat {
+	h, _ := ctx.Value(heartbeatKey{}).(*Heartbeat)
+	return h
+}
+
+// Beat records that the run is alive, clearing its stall, if any.
+func (h *Heartbeat) Beat() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.last = h.now()
+	h.stalled = false
+}
+
+// silence returns how long ago the last beat was, and whether the run just
+// became stalled, after d without any.
+func (h *Heartbeat) silence(d time.Duration) (time.Duration, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	silent := h.now().Sub(h.last)
+	if silent < d || h.stalled {
+		return silent, false
+	}
+	h.stalled = true
+	return silent, true
+}
+
+// StallAfter marks a run as stalled when it goes d without calling Beat on
+// its Heartbeat, and calls f with the run so far. A run stalls once until it
+// beats again. Unlike Timeout, it tells slow runs that are still working
+// from hung ones: Healthy reports stalled runs and Stats counts them.
+func (j *Job) StallAfter(d time.Duration, f func(RunInfo)) *Job {
+	if j.err != nil {
+		return j
+	}
+	if d <= 0 {
+		j.err = errors.New("bad stall threshold")
+		return j
+	}
+	j.stallTime = d
+	j.onStall = f
+	return j
+}
+
+// heartbeat gives the run with context ctx a heartbeat watched until the
+// returned function is called, if the job has a stall threshold.
+func (j *Job) heartbeat(ctx context.Context) (context.Context, func()) {
+	j.Lock()
+	d := j.stallTime
+	if d <= 0 {
+		j.Unlock()
+		return ctx, func() {}
+	}
+	h := &Heartbeat{now: j.now, last: j.now()}
+	j.beat = h
+	started, drift := j.startedAt, j.drift
+	j.Unlock()
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		timer := j.getClock().NewTimer(d)
+		defer timer.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-timer.C():
+			}
+			silent, stalled := h.silence(d)
+			if stalled {
+				j.stall(RunInfo{Job: j, Started: started, Duration: j.now().Sub(started), Drift: drift})
+			}
+			if wait := d - silent; wait > 0 && !stalled {
+				timer.Reset(wait)
+			} else {
+				timer.Reset(d)
+			}
+		}
+	}()
+	return context.WithValue(ctx, heartbeatKey{}, h), func() {
+		close(done)
+		<-finished
+		j.Lock()
+		j.beat = nil
+		j.Unlock()
+	}
+}
+
+// stall counts a stalled run and reports it.
+func (j *Job) stall(info RunInfo) {
+	j.Lock()
+	j.record(func(s *Stats) { s.Stalled++ })
+	f := j.onStall
+	j.Unlock()
+	if f != nil {
+		f(info)
+	}
+}
+
+// stalledFor returns how long the current run has gone without a heartbeat,
+// if it is stalled. It must be called with the job locked.
+func (j *Job) stalledFor() (time.Duration, bool) {
+	if j.beat == nil {
+		return 0, false
+	}
+	h := j.beat
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.now().Sub(h.last), h.stalled
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStallAfter(t *testing.T) {
+	stalls := make(chan RunInfo, 10)
+	release := make(chan bool)
+	beating := make(chan bool)
+	job, err := Every(1).Hours().StallAfter(50*time.Millisecond, func(info RunInfo) {
+		stalls <- info
+	}).RunCtx(func(ctx context.Context) {
+		h := HeartbeatFrom(ctx)
+		assert.NotNil(t, h)
+		for i := 0; i < 10; i++ {
+			h.Beat()
+			time.Sleep(5 * time.Millisecond)
+		}
+		beating <- false
+		<-release
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	<-beating
+	select {
+	case <-stalls:
+		t.Fatal("run stalled while beating")
+	default:
+	}
+	info := <-stalls
+	assert.Equal(t, job, info.Job)
+	assert.True(t, info.Duration >= 50*time.Millisecond)
+	assert.Equal(t, 1, job.Stats().Stalled)
+	assert.ErrorContains(t, job.Healthy(), "run stalled")
+
+	release <- true
+	<-job.Done()
+	assert.Nil(t, job.Healthy())
+	assert.Equal(t, 1, job.Stats().Stalled)
+}
+
+func TestHeartbeatWithoutStallAfter(t *testing.T) {
+	assert.Nil(t, HeartbeatFrom(context.Background()))
+	HeartbeatFrom(context.Background()).Beat()
+
+	_, err := Every(1).Hours().StallAfter(0, nil).Run(test)
+	assert.NotNil(t, err)
+}
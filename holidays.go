@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// maxHolidays bounds the number of days in a row a job looks past for one
+// that is not a holiday.
+const maxHolidays = 366
+
+// HolidayProvider tells which days are holidays, e.g. from a regional
+// calendar. See SkipHolidays.
+type HolidayProvider interface {
+	// IsHoliday reports whether the day of t, in its location, is a holiday.
+	IsHoliday(t time.Time) bool
+}
+
+// HolidayFunc adapts a function to a HolidayProvider.
+type HolidayFunc func(t time.Time) bool
+
+// IsHoliday implements HolidayProvider.
+func (f HolidayFunc) IsHoliday(t time.Time) bool {
+	return f(t)
+}
+
+// Holidays returns a HolidayProvider for a fixed list of days. Only the date
+// of each time is used, in the location of the time asked about.
+func Holidays(days ...time.Time) HolidayProvider {
+	type date struct {
+		year  int
+		month time.Month
+		day   int
+	}
+	set := make(map[date]bool, len(days))
+	for _, t := range days {
+		year, month, day := t.Date()
+		set[date{year, month, day}] = true
+	}
+	return HolidayFunc(func(t time.Time) bool {
+		year, month, day := t.Date()
+		return set[date{year, month, day}]
+	})
+}
+
+// SkipHolidays leaves out the runs of the job that fall on holidays, as told
+// by h, whatever its schedule: the job next runs as scheduled after the
+// holiday is over.
+func (j *Job) SkipHolidays(h HolidayProvider) *Job {
+	return j.setHolidays(h, false)
+}
+
+// ShiftHolidays moves the runs of the job that fall on holidays, as told by h,
+// to the same time on the next day that is not one. It is meant for calendar
+// schedules, such as monthly reports, that must not be missed; a shifted run
+// may coincide with a regular one.
+func (j *Job) ShiftHolidays(h HolidayProvider) *Job {
+	return j.setHolidays(h, true)
+}
+
+func (j *Job) setHolidays(h HolidayProvider, shift bool) *Job {
+	if j.err != nil {
+		return j
+	}
+	if h == nil {
+		j.err = errors.New("nil holiday provider")
+		return j
+	}
+	j.holidays = h
+	j.shift = shift
+	return j
+}
+
+// offHoliday returns date, a run of schedule, if it is not on a holiday, or
+// else the run that replaces it: the first run of the schedule after the
+// holidays, or the run moved past them with ShiftHolidays. Interval schedules
+// resume at midnight, as after the days left out by OnlyOn.
+func (j *Job) offHoliday(schedule scheduled, date time.Time) (time.Time, error) {
+	if j.holidays == nil {
+		return date, nil
+	}
+	for i := 0; i < maxHolidays; i++ {
+		if !j.holidays.IsHoliday(date) {
+			return date, nil
+		}
+		if j.shift {
+			date = date.AddDate(0, 0, 1)
+			continue
+		}
+		year, month, day := date.Date()
+		midnight := time.Date(year, month, day+1, 0, 0, 0, 0, date.Location())
+		if r, ok := schedule.(*recurrent); ok {
+			date = r.onDay(r.from(midnight))
+			continue
+		}
+		next, err := schedule.next(midnight.Add(-time.Nanosecond))
+		if err != nil {
+			return time.Time{}, err
+		}
+		date = next
+	}
+	return time.Time{}, errNoNextRun
+}
+
+// nextRun returns the wait from now until the next run of the job, away from
+// holidays.
+func (j *Job) nextRun(now time.Time) (time.Duration, error) {
+	next, err := j.schedule.nextRun(now)
+	if err != nil || j.holidays == nil {
+		return next, err
+	}
+	date, err := j.offHoliday(j.schedule, now.Add(next))
+	return date.Sub(now), err
+}
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipHolidays(t *testing.T) {
+	// Christmas and Boxing Day 2015 were a Friday and a Saturday.
+	holidays := Holidays(date(2015, 12, 25, 0, 0), date(2015, 12, 26, 0, 0))
+	job := Every().Day().At("09:00").SkipHolidays(holidays)
+	assert.Nil(t, job.err)
+	assert.Equal(t, date(2015, 12, 24, 9, 0), job.Next(date(2015, 12, 24, 8, 0)))
+	assert.Equal(t, date(2015, 12, 27, 9, 0), job.Next(date(2015, 12, 24, 9, 0)))
+
+	job = Every().Friday().At("17:00").SkipHolidays(holidays)
+	assert.Equal(t, date(2016, 1, 1, 17, 0), job.Next(date(2015, 12, 20, 0, 0)))
+
+	job = Every(6).Hours().SkipHolidays(holidays)
+	next, err := job.nextRun(date(2015, 12, 25, 20, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, 28*time.Hour, next, "immediate run moved past the holidays")
+	next, _ = job.nextRun(date(2015, 12, 27, 20, 0))
+	assert.Equal(t, 6*time.Hour, next)
+}
+
+func TestShiftHolidays(t *testing.T) {
+	holidays := HolidayFunc(func(t time.Time) bool {
+		return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	})
+	// January 31, 2016 was a Sunday.
+	job := Every().Month().LastDay().At("18:00").ShiftHolidays(holidays)
+	assert.Nil(t, job.err)
+	assert.Equal(t, date(2016, 2, 1, 18, 0), job.Next(date(2016, 1, 15, 0, 0)))
+	assert.Equal(t, date(2016, 2, 29, 18, 0), job.Next(date(2016, 2, 1, 18, 0)))
+
+	always := HolidayFunc(func(time.Time) bool { return true })
+	assert.True(t, Every().Day().ShiftHolidays(always).Next(time.Now()).IsZero())
+	assert.NotNil(t, Every().Day().SkipHolidays(nil).err)
+}
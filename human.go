@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"strings"
+	"time"
+)
+
+// String implements fmt.Stringer with a description of the job for people,
+// e.g. "report: Sundays at 08:30 Europe/Madrid", for log lines and admin
+// pages. Description is terser and does not change with the job's name.
+func (j *Job) String() string {
+	j.RLock()
+	name, err := j.name, j.err
+	j.RUnlock()
+
+	desc := humanize(j)
+	if err != nil {
+		desc = "bad schedule: " + err.Error()
+	}
+	if name == "" {
+		return desc
+	}
+	return name + ": " + desc
+}
+
+// String implements fmt.Stringer, see Job.String.
+func (p Plan) String() string {
+	return p.job().String()
+}
+
+// humanize describes a schedule for people.
+func humanize(s Schedule) string {
+	switch s := s.(type) {
+	case *Job:
+		if schedule := s.current(); schedule != nil {
+			return humanize(schedule)
+		}
+		return ""
+	case *recurrent:
+		desc := "every " + shortDuration(time.Duration(s.units)*s.period)
+		if s.phased {
+			desc += " starting " + (&daily{}).add(s.offset).human()
+		}
+		if s.adapt != nil {
+			desc += ", adaptive"
+		}
+		return desc + s.onDays()
+	case *backoff:
+		return "every " + shortDuration(s.initial) + " to " + shortDuration(s.max) + ", backing off"
+	case *delay:
+		return "once after " + shortDuration(s.d)
+	case *once:
+		return "once at " + s.at.Format("2006-01-02 15:04") + " " + s.at.Location().String()
+	case *daily:
+		return "daily at " + s.human()
+	case *weekly:
+		return s.day.String() + "s at " + s.d.human()
+	case rota:
+		return s.human()
+	case *everyWeeks:
+		return strings.TrimSuffix(s.description(), s.d.timeString()) + s.d.human()
+	case *monthly:
+		return strings.TrimSuffix(s.description(), s.d.timeString()) + s.d.human()
+	case *monthlyWeekday:
+		return strings.TrimSuffix(s.description(), s.d.timeString()) + s.d.human()
+	case union:
+		return humanizeAll(s, " or ")
+	case intersection:
+		return humanizeAll(s, " and ")
+	}
+	return describe(s)
+}
+
+func humanizeAll(schedules []Schedule, sep string) string {
+	parts := make([]string, len(schedules))
+	for i, s := range schedules {
+		parts[i] = humanize(s)
+	}
+	return strings.Join(parts, sep)
+}
+
+// add returns a daily at d past the time of day of d.
+func (d *daily) add(offset time.Duration) *daily {
+	t := time.Date(0, 1, 1, d.hour, d.min, d.sec, d.nsec, time.UTC).Add(offset)
+	return &daily{hour: t.Hour(), min: t.Minute(), sec: t.Second(), nsec: t.Nanosecond(), loc: d.loc}
+}
+
+// human returns the time of day without the seconds if they are 0, and the
+// location unless it is the local one.
+func (d *daily) human() string {
+	at := d.timeString()
+	if d.sec == 0 && d.nsec == 0 {
+		at = at[:len("15:04")]
+	}
+	if d.loc != nil && d.loc != time.Local {
+		at += " " + d.loc.String()
+	}
+	return at
+}
+
+// shortDuration formats d as time.Duration does without the trailing zero
+// units, e.g. 2h rather than 2h0m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// String implements fmt.Stringer.
+func (r *recurrent) String() string {
+	return humanize(r)
+}
+
+// String implements fmt.Stringer.
+func (d *daily) String() string {
+	return humanize(d)
+}
+
+// String implements fmt.Stringer.
+func (w *weekly) String() string {
+	return humanize(w)
+}
+
+// String implements fmt.Stringer.
+func (r rota) String() string {
+	return humanize(r)
+}
+
+// String implements fmt.Stringer.
+func (u union) String() string {
+	return humanize(u)
+}
+
+// String implements fmt.Stringer.
+func (in intersection) String() string {
+	return humanize(in)
+}
+
+// String implements fmt.Stringer.
+func (w *window) String() string {
+	return w.description()
+}
+
+// String implements fmt.Stringer.
+func (d dates) String() string {
+	return d.description()
+}
+
+// String implements fmt.Stringer.
+func (r *rrule) String() string {
+	return r.description()
+}
+
+// String implements fmt.Stringer.
+func (c *cron) String() string {
+	return c.description()
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	cron, err := Cron("30 8 * * *")
+	assert.Nil(t, err)
+	parsed, err := ParseSchedule("sun 08:30 in Europe/Madrid")
+	assert.Nil(t, err)
+	tests := []struct {
+		schedule fmt.Stringer
+		want     string
+	}{
+		{Every(2).Hours(), "every 2h"},
+		{Every(90).Seconds(), "every 1m30s"},
+		{Every(2).Hours().AtMinute(15), "every 2h starting 00:15"},
+		{Every(1).Minutes().AdaptiveInterval(func() time.Duration { return 0 }), "every 1m, adaptive"},
+		{Backoff(time.Second, time.Minute, 2), "every 1s to 1m, backing off"},
+		{Every().Day().At("08:30"), "daily at 08:30"},
+		{Every().Sunday().At("08:30").Timezone("Europe/Madrid"), "Sundays at 08:30 Europe/Madrid"},
+		{Every().Monday().At("08:30:15"), "Mondays at 08:30:15"},
+		{Every(2).Weeks().OnWeekday(time.Friday).At("17:00"), "every 2 weeks on Friday at 17:00"},
+		{Every().Month().LastDay().At("23:00").Timezone("Europe/Madrid"), "every month on the last day at 23:00 Europe/Madrid"},
+		{Every().Day().At("09:00").Named("report"), "report: daily at 09:00"},
+		{Every(1, 2), "bad schedule: too many arguments in Every"},
+		{Plan{}.Every().Sunday().At("08:30"), "Sundays at 08:30"},
+		{Union(Every().Monday().At("08:00"), Every().Friday().At("14:00")).(fmt.Stringer), "Mondays at 08:00 or Fridays at 14:00"},
+		{cron.(fmt.Stringer), "cron 30 8 * * *"},
+		{parsed.(fmt.Stringer), "Sundays at 08:30 Europe/Madrid"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, test.schedule.String())
+	}
+	assert.Equal(t, "report: every 1h", fmt.Sprint(Every(1).Hours().Named("report")))
+}
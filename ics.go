@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxICSEvents bounds the events written for a job whose schedule has no
+// RRULE equivalent and is listed occurrence by occurrence instead.
+const maxICSEvents = 1000
+
+var rruleWeekdays = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// ExportICS writes the scheduler's jobs as an iCalendar (RFC 5545) calendar
+// covering horizon from now, e.g. to overlay planned work on a team calendar.
+// Each job becomes a recurring event with an RRULE when its schedule has one,
+// and one event per run otherwise or when it skips or shifts holidays.
+func (s *Scheduler) ExportICS(w io.Writer, horizon time.Duration) error {
+	bw := bufio.NewWriter(w)
+	line := func(l string) {
+		bw.WriteString(l + "\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//carlescere//scheduler//EN")
+	for i, j := range s.snapshot() {
+		now := j.now()
+		end := now.Add(horizon)
+		event := func(uid string, start time.Time, rule string) {
+			line("BEGIN:VEVENT")
+			line("UID:" + uid + "@scheduler")
+			line("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+			line("DTSTART" + icsTime(start))
+			line("SUMMARY:" + icsText(j.Description()))
+			if rule != "" {
+				line("RRULE:" + rule + ";UNTIL=" + icsUntil(start, end))
+			}
+			line("END:VEVENT")
+		}
+		id := "job-" + strconv.Itoa(i+1)
+		var first time.Time
+		j.upcoming(func(date time.Time) bool {
+			first = date
+			return false
+		})
+		if first.IsZero() || first.After(end) {
+			continue
+		}
+		// An RRULE would include the runs a holiday skips or shifts.
+		if rule, ok := rruleOf(j.current()); ok && j.holidays == nil {
+			event(id, first, rule)
+			continue
+		}
+		n := 0
+		j.upcoming(func(date time.Time) bool {
+			if date.After(end) {
+				return false
+			}
+			n++
+			event(id+"-"+strconv.Itoa(n), date, "")
+			return n < maxICSEvents
+		})
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// icsTime formats the value of DTSTART: in UTC for UTC times, with the zone
+// for named locations and floating, in the reader's zone, for local times.
+func icsTime(t time.Time) string {
+	switch loc := t.Location(); {
+	case loc == time.UTC:
+		return ":" + t.Format("20060102T150405Z")
+	case loc == time.Local || !strings.Contains(loc.String(), "/"):
+		return ":" + t.Format("20060102T150405")
+	default:
+		return ";TZID=" + loc.String() + ":" + t.Format("20060102T150405")
+	}
+}
+
+// icsUntil formats end as UNTIL for a rule starting at start, which must be
+// in UTC unless the start is floating.
+func icsUntil(start, end time.Time) string {
+	if strings.HasPrefix(icsTime(start), ":") && start.Location() != time.UTC {
+		return end.In(start.Location()).Format("20060102T150405")
+	}
+	return end.UTC().Format("20060102T150405Z")
+}
+
+// icsText escapes a TEXT value.
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// rruleOf returns the RRULE of a schedule, for a DTSTART at one of its runs,
+// if it has one.
+func rruleOf(s scheduled) (string, bool) {
+	switch s := s.(type) {
+	case *daily:
+		return "FREQ=DAILY", true
+	case *weekly:
+		return "FREQ=WEEKLY", true
+	case *everyWeeks:
+		return "FREQ=WEEKLY;INTERVAL=" + strconv.Itoa(s.every), true
+	case *monthly:
+		rule := "FREQ=MONTHLY;INTERVAL=" + strconv.Itoa(s.cycle.step()) + s.cycle.byMonth()
+		switch {
+		case s.last:
+			return rule + ";BYMONTHDAY=-1", true
+		case s.day <= 28 || s.policy == DaySkip:
+			return rule + ";BYMONTHDAY=" + strconv.Itoa(s.day), true
+		}
+	case *monthlyWeekday:
+		return "FREQ=MONTHLY;INTERVAL=" + strconv.Itoa(s.cycle.step()) + s.cycle.byMonth() + ";BYDAY=" + strconv.Itoa(int(s.week)) + rruleWeekdays[s.day], true
+	case *recurrent:
+		// OnlyOn and AdaptiveInterval schedules do not run at a fixed
+		// interval.
+		if s.days != 0 || s.adapt != nil {
+			return "", false
+		}
+		every := time.Duration(s.units) * s.period
+		if s.phased && (24*time.Hour)%every != 0 {
+			return "", false
+		}
+		for _, f := range []struct {
+			unit time.Duration
+			freq string
+		}{{time.Hour, "HOURLY"}, {time.Minute, "MINUTELY"}, {time.Second, "SECONDLY"}} {
+			if every%f.unit == 0 {
+				return "FREQ=" + f.freq + ";INTERVAL=" + strconv.FormatInt(int64(every/f.unit), 10), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportICS(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler()
+	daily, err := s.Every().Day().At("08:30").Timezone("UTC").WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer daily.Stop()
+	custom, err := s.On(everyOtherHour{}).WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer custom.Stop()
+
+	var buf bytes.Buffer
+	assert.Nil(t, s.ExportICS(&buf, 4*time.Hour+30*time.Minute))
+	ics := buf.String()
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VEVENT\r\nEND:VCALENDAR\r\n"))
+	// The daily job is not due within the horizon.
+	assert.NotContains(t, ics, "FREQ=DAILY")
+	assert.Equal(t, 2, strings.Count(ics, "BEGIN:VEVENT"))
+	assert.Contains(t, ics, "UID:job-2-1@scheduler\r\nDTSTAMP:20150603T120000Z\r\nDTSTART:20150603T140000Z\r\nSUMMARY:custom schedule\r\nEND:VEVENT")
+
+	buf.Reset()
+	assert.Nil(t, s.ExportICS(&buf, 48*time.Hour))
+	assert.Contains(t, buf.String(), "DTSTART:20150604T083000Z\r\nSUMMARY:every day at 08:30:00\r\nRRULE:FREQ=DAILY;UNTIL=20150605T120000Z\r\n")
+}
+
+func TestExportICSOnlyOn(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler()
+	job, err := s.Every(1).Hours().OnlyOn(time.Saturday).WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	var buf bytes.Buffer
+	assert.Nil(t, s.ExportICS(&buf, 5*24*time.Hour))
+	ics := buf.String()
+	assert.NotContains(t, ics, "RRULE")
+	assert.Equal(t, 24, strings.Count(ics, "BEGIN:VEVENT"))
+	assert.Equal(t, 24, strings.Count(ics, "DTSTART:20150606T"))
+}
+
+func TestExportICSHolidays(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler()
+	job, err := s.Every().Day().At("10:00").Timezone("UTC").
+		SkipHolidays(Holidays(time.Date(2015, 6, 4, 0, 0, 0, 0, time.UTC))).WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	var buf bytes.Buffer
+	assert.Nil(t, s.ExportICS(&buf, 3*24*time.Hour))
+	ics := buf.String()
+	assert.NotContains(t, ics, "RRULE")
+	assert.NotContains(t, ics, "DTSTART:20150604T")
+	assert.Contains(t, ics, "DTSTART:20150605T100000Z")
+	assert.Contains(t, ics, "DTSTART:20150606T100000Z")
+}
+
+func TestRRuleOf(t *testing.T) {
+	for rule, job := range map[string]*Job{
+		"FREQ=WEEKLY":                                       Every().Friday(),
+		"FREQ=WEEKLY;INTERVAL=2":                            Every(2).Weeks(),
+		"FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=-1":             Every().Quarter().LastDay(),
+		"FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=31":             Every().Month().OnDay(31).OnMissingDay(DaySkip),
+		"FREQ=MONTHLY;INTERVAL=1;BYDAY=-1FR":                Every().Month().On(Last, time.Friday),
+		"FREQ=MONTHLY;INTERVAL=1;BYMONTH=1,7;BYMONTHDAY=15": Every().January().July().OnDay(15),
+		"FREQ=HOURLY;INTERVAL=2":                            Every(2).Hours(),
+		"FREQ=MINUTELY;INTERVAL=90":                         EveryDuration(90 * time.Minute),
+		"FREQ=SECONDLY;INTERVAL=45":                         Every(45).Seconds(),
+	} {
+		actual, ok := rruleOf(job.schedule)
+		assert.True(t, ok)
+		assert.Equal(t, rule, actual)
+	}
+	for _, job := range []*Job{
+		Every().Month().OnDay(31).OnMissingDay(DayClamp),
+		Every(1).Hours().OnlyOn(time.Saturday),
+		Every(1).Hours().AdaptiveInterval(func() time.Duration { return time.Minute }),
+	} {
+		_, ok := rruleOf(job.schedule)
+		assert.False(t, ok)
+	}
+}
+
+func TestICSTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	assert.Equal(t, ";TZID=America/New_York:20150601T090000", icsTime(time.Date(2015, 6, 1, 9, 0, 0, 0, ny)))
+	assert.Equal(t, ":20150601T090000", icsTime(time.Date(2015, 6, 1, 9, 0, 0, 0, time.Local)))
+	assert.Equal(t, `a\, b\; c\\d`, icsText(`a, b; c\d`))
+}
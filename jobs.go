@@ -0,0 +1,525 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Scheduler keeps track of a set of jobs so they can be inspected and managed
+// together. Jobs created through its methods are registered when Run is
+// called; the package-level functions create jobs that belong to no scheduler.
+type Scheduler struct {
+	jobs         []*Job
+	listed       map[*Job]bool
+	groups       map[string]*Group
+	drainTimeout time.Duration
+	err          error
+	failed       chan struct{}
+	store        Store
+	storeErr     error
+	// stored are the states in the store by ID, listed once for the jobs
+	// whose ID is not under their name, see Job.load.
+	stored    map[string]JobState
+	locker    Locker
+	lockErr   error
+	standby   bool
+	cluster   *Cluster
+	workers   *pool
+	executor  Executor
+	errs      chan JobError
+	stagger   time.Duration
+	staggered map[time.Duration]int
+	funcs     map[string]func(context.Context) error
+	defaults  defaults
+	ctx       context.Context
+	specs     map[string]specJob
+	reloading sync.Mutex
+	pending   []pendingJob
+	started   bool
+	journal   Journal
+	auditSink AuditSink
+	shared    *dispatcher
+	sync.Mutex
+}
+
+// DefaultDrainTimeout is how long RunUntilSignal waits for running jobs to
+// finish unless WithDrainTimeout says otherwise.
+const DefaultDrainTimeout = 30 * time.Second
+
+// NewScheduler returns an empty scheduler configured by opts, which set
+// defaults for its jobs, e.g. so all of them run in the same time zone:
+//
+//	s := scheduler.NewScheduler(scheduler.WithDefaultLocation(madrid),
+//		scheduler.WithDefaultTimeout(time.Hour))
+//
+// Jobs override the defaults with settings of their own.
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{drainTimeout: DefaultDrainTimeout}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithContext binds the scheduler to ctx: once it is canceled, the jobs of the
+// scheduler stop, with its cause as StopCause, and the contexts of their runs
+// in progress are canceled.
+func WithContext(ctx context.Context) SchedulerOption {
+	return func(s *Scheduler) {
+		s.ctx = ctx
+	}
+}
+
+// parent returns the context the jobs of the scheduler derive theirs from.
+func (s *Scheduler) parent() context.Context {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// WithDrainTimeout sets how long RunUntilSignal waits for running jobs to
+// finish once a signal is caught.
+func (s *Scheduler) WithDrainTimeout(d time.Duration) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.drainTimeout = d
+	return s
+}
+
+// Standby makes the jobs of the scheduler skip their due runs, as if they were
+// paused, until Activate is called. It is meant for leader election: replicas
+// that are not the leader stand by so only one of them runs jobs. Runs in
+// progress are not interrupted.
+func (s *Scheduler) Standby() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.standby = true
+}
+
+// Activate lets the jobs of a scheduler on standby run again from their next
+// scheduled time.
+func (s *Scheduler) Activate() {
+	s.Lock()
+	defer s.Unlock()
+
+	s.standby = false
+}
+
+// IsActive reports whether the scheduler runs its jobs, i.e. it is not on
+// standby.
+func (s *Scheduler) IsActive() bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return !s.standby
+}
+
+// Every works like the package-level Every for a job of this scheduler.
+func (s *Scheduler) Every(times ...int) *Job {
+	return s.bind(Every(times...))
+}
+
+// EveryDuration works like the package-level EveryDuration for a job of this
+// scheduler.
+func (s *Scheduler) EveryDuration(d time.Duration) *Job {
+	return s.bind(EveryDuration(d))
+}
+
+// EveryString works like the package-level EveryString for a job of this
+// scheduler.
+func (s *Scheduler) EveryString(duration string) *Job {
+	return s.bind(EveryString(duration))
+}
+
+// After works like the package-level After for a job of this scheduler.
+func (s *Scheduler) After(d time.Duration) *Job {
+	return s.bind(After(d))
+}
+
+// Once works like the package-level Once for a job of this scheduler.
+func (s *Scheduler) Once(t time.Time) *Job {
+	return s.bind(Once(t))
+}
+
+// OnDates works like the package-level OnDates for a job of this scheduler.
+func (s *Scheduler) OnDates(times ...time.Time) *Job {
+	return s.bind(OnDates(times...))
+}
+
+// On works like the package-level On for a job of this scheduler.
+func (s *Scheduler) On(schedule Schedule) *Job {
+	return s.bind(On(schedule))
+}
+
+func (s *Scheduler) bind(j *Job) *Job {
+	j.scheduler = s
+	return j
+}
+
+// register adds a job to the scheduler, unless it is already there.
+func (s *Scheduler) register(j *Job) {
+	s.Lock()
+	added := !s.listed[j]
+	if added {
+		if s.listed == nil {
+			s.listed = make(map[*Job]bool)
+		}
+		s.listed[j] = true
+		s.jobs = append(s.jobs, j)
+	}
+	s.Unlock()
+
+	if added && j.scheduler == s {
+		j.restore()
+		j.persist()
+	}
+}
+
+// has reports if j is registered with the scheduler.
+func (s *Scheduler) has(j *Job) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.listed[j]
+}
+
+// unregister removes jobs from the scheduler.
+func (s *Scheduler) unregister(jobs ...*Job) {
+	s.Lock()
+	s.jobs = without(s.jobs, jobs)
+	for _, j := range jobs {
+		delete(s.listed, j)
+	}
+	groups := make([]*Group, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, g)
+	}
+	s.Unlock()
+
+	// Groups are locked on their own, as Add locks them before the scheduler.
+	for _, g := range groups {
+		g.Lock()
+		g.jobs = without(g.jobs, jobs)
+		g.Unlock()
+	}
+}
+
+// without returns the jobs of from that are not in jobs. It filters from in
+// place, as the lists of jobs it is used on are only ever handed out as
+// copies.
+func without(from, jobs []*Job) []*Job {
+	gone := make(map[*Job]bool, len(jobs))
+	for _, j := range jobs {
+		gone[j] = true
+	}
+	kept := from[:0]
+	for _, j := range from {
+		if !gone[j] {
+			kept = append(kept, j)
+		}
+	}
+	// Let the removed jobs be collected.
+	clear(from[len(kept):])
+	return kept
+}
+
+func (s *Scheduler) snapshot() []*Job {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]*Job(nil), s.jobs...)
+}
+
+// Jobs returns the jobs of the scheduler, in the order they were registered.
+// Jobs that were stopped, with Stop, Quit or Shutdown, are still listed, with
+// status Stopped, until they are removed with RemoveJob, RemoveByTag or
+// Reload. Jobs whose schedule ends, such as those of After, Once and OnDates,
+// leave on their own after their last run.
+func (s *Scheduler) Jobs() []*Job {
+	return s.snapshot()
+}
+
+// Len returns the number of jobs of the scheduler, counting stopped jobs as
+// Jobs lists them.
+func (s *Scheduler) Len() int {
+	s.Lock()
+	defer s.Unlock()
+
+	return len(s.jobs)
+}
+
+// Job returns the job of the scheduler with the given name, or nil if there is
+// none.
+func (s *Scheduler) Job(name string) *Job {
+	for _, j := range s.snapshot() {
+		if j.Name() == name {
+			return j
+		}
+	}
+	return nil
+}
+
+// find returns the scheduler's job with the given name.
+func (s *Scheduler) find(name string) (*Job, error) {
+	j := s.Job(name)
+	if j == nil {
+		return nil, errors.New("no job named " + strconv.Quote(name))
+	}
+	return j, nil
+}
+
+// Reschedule replaces the schedule of the scheduler's job with the given name,
+// see Job.Reschedule. It is meant for applying configuration changes to jobs
+// looked up by name.
+func (s *Scheduler) Reschedule(name string, schedule Schedule) error {
+	j, err := s.find(name)
+	if err != nil {
+		return err
+	}
+	return j.Reschedule(schedule)
+}
+
+// DryRun writes every job of the scheduler followed by each time it would run
+// within horizon from now, without running anything. It is meant for
+// reviewing schedule changes before deploying them.
+func (s *Scheduler) DryRun(w io.Writer, horizon time.Duration) error {
+	for i, j := range s.snapshot() {
+		if _, err := fmt.Fprintf(w, "job %d: %s\n", i+1, j.Description()); err != nil {
+			return err
+		}
+		end := j.now().Add(horizon)
+		var err error
+		j.upcoming(func(date time.Time) bool {
+			if date.After(end) {
+				return false
+			}
+			_, err = fmt.Fprintf(w, "  %s\n", date.Format(time.RFC3339Nano))
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrShutdown is the cause of jobs stopped by Shutdown, see Job.StopCause.
+var ErrShutdown = errors.New("scheduler shut down")
+
+// Shutdown stops every job of the scheduler, with ErrShutdown as their cause,
+// and waits up to timeout for the runs in progress to finish. It returns an
+// error if some are still running when the timeout expires.
+func (s *Scheduler) Shutdown(timeout time.Duration) error {
+	jobs := s.snapshot()
+	for _, j := range jobs {
+		j.StopWithCause(ErrShutdown)
+	}
+	deadline := time.Now().Add(timeout)
+	for _, j := range jobs {
+		for j.IsRunning() {
+			if !time.Now().Before(deadline) {
+				return errors.New("timed out waiting for running jobs")
+			}
+			time.Sleep(drainPollInterval)
+		}
+		if j.scheduler == s {
+			j.persist()
+		}
+	}
+	return nil
+}
+
+// Go returns a function that blocks until ctx is done or a job of the
+// scheduler stops on a scheduling error, and then shuts the scheduler down as
+// RunUntilSignal does. It is meant for errgroup.Group and similar supervisors:
+//
+//	g, ctx := errgroup.WithContext(ctx)
+//	g.Go(s.Go(ctx))
+//
+// The function returns the scheduling error, if any, or else the error of
+// Shutdown.
+func (s *Scheduler) Go(ctx context.Context) func() error {
+	return func() error {
+		select {
+		case <-ctx.Done():
+		case <-s.failure():
+		}
+		s.Lock()
+		timeout, err := s.drainTimeout, s.err
+		s.Unlock()
+		if shutdownErr := s.Shutdown(timeout); err == nil {
+			err = shutdownErr
+		}
+		return err
+	}
+}
+
+// fail records the first error that stopped a job of the scheduler.
+func (s *Scheduler) fail(err error) {
+	failed := s.failure()
+	s.Lock()
+	defer s.Unlock()
+
+	if s.err == nil {
+		s.err = err
+		close(failed)
+	}
+}
+
+func (s *Scheduler) failure() chan struct{} {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.failed == nil {
+		s.failed = make(chan struct{})
+	}
+	return s.failed
+}
+
+// drainPollInterval is how often Shutdown checks for runs still in progress.
+const drainPollInterval = 10 * time.Millisecond
+
+// Group is a named subset of a scheduler's jobs that can be managed together.
+type Group struct {
+	name string
+	s    *Scheduler
+	jobs []*Job
+	sync.Mutex
+}
+
+// Group returns the group of jobs with the given name, creating it if needed.
+func (s *Scheduler) Group(name string) *Group {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.groups == nil {
+		s.groups = make(map[string]*Group)
+	}
+	g, ok := s.groups[name]
+	if !ok {
+		g = &Group{name: name, s: s}
+		s.groups[name] = g
+	}
+	return g
+}
+
+// Name returns the name of the group.
+func (g *Group) Name() string {
+	return g.name
+}
+
+// Add puts jobs in the group, registering them with the group's scheduler if
+// they were not already.
+func (g *Group) Add(jobs ...*Job) {
+	g.Lock()
+	defer g.Unlock()
+
+	for _, j := range jobs {
+		g.s.register(j)
+		if !contains(g.jobs, j) {
+			g.jobs = append(g.jobs, j)
+		}
+	}
+}
+
+// remove takes jobs out of the group, leaving them registered.
+func (g *Group) remove(jobs ...*Job) {
+	g.Lock()
+	defer g.Unlock()
+
+	g.jobs = without(g.jobs, jobs)
+}
+
+func (g *Group) snapshot() []*Job {
+	g.Lock()
+	defer g.Unlock()
+
+	return append([]*Job(nil), g.jobs...)
+}
+
+// PauseAll pauses every job in the group.
+func (g *Group) PauseAll() {
+	for _, j := range g.snapshot() {
+		j.Pause()
+	}
+}
+
+// ResumeAll resumes every job in the group.
+func (g *Group) ResumeAll() {
+	for _, j := range g.snapshot() {
+		j.Resume()
+	}
+}
+
+// RemoveByTag stops the jobs of the group named tag, see Group and
+// JobSpec.Tags, and removes them from the scheduler and all its groups, e.g.
+// when a tenant is offboarded. It returns how many jobs it removed.
+func (s *Scheduler) RemoveByTag(tag string) int {
+	s.Lock()
+	g := s.groups[tag]
+	s.Unlock()
+	if g == nil {
+		return 0
+	}
+	jobs := g.snapshot()
+	for _, j := range jobs {
+		j.Stop()
+	}
+	s.unregister(jobs...)
+	for _, j := range jobs {
+		j.audit("", AuditRemove, "tag "+tag)
+	}
+	return len(jobs)
+}
+
+// TriggerTag requests a run now of every job of the group named tag, see
+// Trigger. The runs are subject to the jobs' overlap policies, such as Queue,
+// like any other. It returns how many runs it requested.
+func (s *Scheduler) TriggerTag(tag string) int {
+	s.Lock()
+	g := s.groups[tag]
+	s.Unlock()
+	if g == nil {
+		return 0
+	}
+	return g.TriggerAll()
+}
+
+// TriggerAll requests a run now of every job in the group, see Trigger. It
+// returns how many runs it requested.
+func (g *Group) TriggerAll() int {
+	n := 0
+	for _, j := range g.snapshot() {
+		if j.Trigger() {
+			n++
+		}
+	}
+	return n
+}
+
+// StopAll stops every job in the group.
+func (g *Group) StopAll() {
+	for _, j := range g.snapshot() {
+		j.Stop()
+	}
+}
+
+func contains(jobs []*Job, j *Job) bool {
+	for _, job := range jobs {
+		if job == j {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerRegistersOnRun(t *testing.T) {
+	s := NewScheduler()
+	job := s.Every(1).Hours().NotImmediately()
+	assert.Len(t, s.snapshot(), 0)
+	_, err := job.Run(test)
+	assert.Nil(t, err)
+	defer func() { job.Quit <- true }()
+	assert.Equal(t, []*Job{job}, s.snapshot())
+
+	_, err = s.Every(1).Day().Run(test)
+	assert.NotNil(t, err)
+	assert.Len(t, s.snapshot(), 1)
+}
+
+func TestDryRun(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler()
+	daily, err := s.Every().Day().At("08:30").Timezone("UTC").WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer func() { daily.Quit <- true }()
+	hourly, err := s.Every(20).Hours().NotImmediately().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer func() { hourly.Quit <- true }()
+
+	var buf bytes.Buffer
+	assert.Nil(t, s.DryRun(&buf, 48*time.Hour))
+	assert.Equal(t, `job 1: every day at 08:30:00
+  2015-06-04T08:30:00Z
+  2015-06-05T08:30:00Z
+job 2: every 20h0m0s
+  2015-06-04T08:00:00Z
+  2015-06-05T04:00:00Z
+`, buf.String())
+}
+
+func TestSchedulerJob(t *testing.T) {
+	s := NewScheduler()
+	report, err := s.Every(1).Hours().NotImmediately().Named("report").Run(test)
+	assert.Nil(t, err)
+	defer report.Stop()
+	other, err := s.Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	defer other.Stop()
+
+	assert.Equal(t, []*Job{report, other}, s.Jobs())
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, report, s.Job("report"))
+	assert.Nil(t, s.Job("cleanup"))
+
+	// Stopped jobs are listed until they are removed.
+	report.Stop()
+	for report.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, []*Job{report, other}, s.Jobs())
+	assert.Nil(t, s.RemoveJob(context.Background(), "report"))
+	assert.Equal(t, []*Job{other}, s.Jobs())
+	assert.Equal(t, 1, s.Len())
+}
+
+func TestSchedulerReschedule(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler()
+	report, err := s.Every(1).Hours().NotImmediately().WithClock(clock).Named("report").Run(test)
+	assert.Nil(t, err)
+	defer report.Stop()
+
+	assert.Nil(t, s.Reschedule("report", Every().Day().At("18:00")))
+	for !report.NextN(1)[0].Equal(time.Date(2015, 6, 1, 18, 0, 0, 0, time.UTC)) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, "every day at 18:00:00", report.Description())
+	assert.EqualError(t, s.Reschedule("cleanup", Every().Day()), `no job named "cleanup"`)
+	assert.NotNil(t, s.Reschedule("report", Every(0).Hours()))
+}
+
+func TestGroup(t *testing.T) {
+	s := NewScheduler()
+	etl := s.Group("etl")
+	assert.Equal(t, etl, s.Group("etl"))
+	assert.Equal(t, "etl", etl.Name())
+
+	c := make(chan string, 10)
+	ingest, err := s.Every(1).Hours().NotImmediately().Run(func() { c <- "ingest" })
+	assert.Nil(t, err)
+	report, err := Every(1).Hours().NotImmediately().Run(func() { c <- "report" })
+	assert.Nil(t, err)
+	cleanup := s.Every(1).Hours().NotImmediately()
+	etl.Add(ingest, report, ingest)
+	etl.Add(cleanup)
+	_, err = cleanup.Run(func() { c <- "cleanup" })
+	assert.Nil(t, err)
+	assert.Len(t, etl.snapshot(), 3)
+	assert.Len(t, s.snapshot(), 3)
+
+	etl.PauseAll()
+	assert.True(t, report.IsPaused())
+	ingest.SkipWait <- true
+	report.SkipWait <- true
+	select {
+	case name := <-c:
+		t.Error("paused job ran:", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	etl.ResumeAll()
+	assert.False(t, report.IsPaused())
+	report.SkipWait <- true
+	assert.Equal(t, "report", <-c)
+
+	etl.StopAll()
+	time.Sleep(10 * time.Millisecond)
+	cleanup.SkipWait <- true
+	select {
+	case name := <-c:
+		t.Error("stopped job ran:", name)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestRemoveByTag(t *testing.T) {
+	s := NewScheduler()
+	acme1, err := s.Every(1).Hours().NotImmediately().Named("acme-sync").Run(test)
+	assert.Nil(t, err)
+	acme2, err := s.Every(1).Hours().NotImmediately().Named("acme-report").Run(test)
+	assert.Nil(t, err)
+	other, err := s.Every(1).Hours().NotImmediately().Named("other-sync").Run(test)
+	assert.Nil(t, err)
+	defer other.Stop()
+	s.Group("tenant:acme").Add(acme1, acme2)
+	s.Group("sync").Add(acme1, other)
+
+	assert.Equal(t, 2, s.RemoveByTag("tenant:acme"))
+	assert.Equal(t, []*Job{other}, s.Jobs())
+	assert.Equal(t, []*Job{other}, s.Group("sync").snapshot())
+	assert.Empty(t, s.Group("tenant:acme").snapshot())
+	for acme1.Status() != Stopped || acme2.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 0, s.RemoveByTag("tenant:acme"))
+	assert.Equal(t, 0, s.RemoveByTag("nothing"))
+}
+
+func TestTriggerTag(t *testing.T) {
+	s := NewScheduler()
+	runs := make(chan string, 10)
+	run := func(name string) func() { return func() { runs <- name } }
+	a, err := s.Every(1).Hours().NotImmediately().Run(run("a"))
+	assert.Nil(t, err)
+	defer a.Stop()
+	b, err := s.Every(1).Hours().NotImmediately().Run(run("b"))
+	assert.Nil(t, err)
+	defer b.Stop()
+	c, err := s.Every(1).Hours().NotImmediately().Run(run("c"))
+	assert.Nil(t, err)
+	defer c.Stop()
+	s.Group("cleanup").Add(a, b)
+
+	assert.Equal(t, 2, s.TriggerTag("cleanup"))
+	assert.ElementsMatch(t, []string{"a", "b"}, []string{<-runs, <-runs})
+	assert.Equal(t, 0, s.TriggerTag("nothing"))
+	assert.Equal(t, 0, s.Group("empty").TriggerAll())
+	assert.Len(t, runs, 0)
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	s := NewScheduler(WithContext(ctx))
+	started := make(chan bool)
+	canceled := make(chan error, 1)
+	job, err := s.Every(1).Hours().RunCtx(func(ctx context.Context) {
+		started <- true
+		<-ctx.Done()
+		canceled <- ctx.Err()
+	})
+	assert.Nil(t, err)
+	<-started
+
+	shutdown := errors.New("shutting down")
+	cancel(shutdown)
+	assert.Equal(t, context.Canceled, <-canceled)
+	for job.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, shutdown, job.StopCause())
+}
+
+func TestStandby(t *testing.T) {
+	s := NewScheduler()
+	assert.True(t, s.IsActive())
+	s.Standby()
+	assert.False(t, s.IsActive())
+
+	c := make(chan bool, 10)
+	job, err := s.Every(1).Hours().NotImmediately().Run(func() { c <- true })
+	assert.Nil(t, err)
+	defer job.Stop()
+	job.SkipWait <- true
+	select {
+	case <-c:
+		t.Error("job ran on standby")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, Stats{Skipped: 1}, job.Stats())
+
+	s.Activate()
+	assert.True(t, s.IsActive())
+	job.SkipWait <- true
+	assert.True(t, <-c)
+}
+
+// quartzOnce fires once, shortly after the first call, and then fails.
+type quartzOnce struct {
+	calls *int32
+}
+
+func (q quartzOnce) NextFireTime(prev int64) (int64, error) {
+	if atomic.AddInt32(q.calls, 1) > 1 {
+		return 0, errors.New("trigger broken")
+	}
+	return prev + int64(10*time.Millisecond), nil
+}
+
+func (quartzOnce) Description() string {
+	return "once"
+}
+
+func TestGoStopsOnContext(t *testing.T) {
+	s := NewScheduler()
+	_, err := s.Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Go(ctx)() }()
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestGoReturnsSchedulingError(t *testing.T) {
+	s := NewScheduler()
+	var calls int32
+	_, err := s.bind(FromQuartz(quartzOnce{calls: &calls})).Run(test)
+	assert.Nil(t, err)
+	err = s.Go(context.Background())()
+	assert.EqualError(t, err, "job once: trigger broken")
+}
@@ -0,0 +1,310 @@
+package scheduler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Journal events, see JournalEntry.
+const (
+	JournalStart = "start"
+	JournalEnd   = "end"
+	JournalSkip  = "skip"
+)
+
+// Reasons for a run, see JournalEntry.
+const (
+	ReasonSchedule = "schedule"
+	ReasonTrigger  = "trigger"
+	ReasonQueue    = "queue"
+	ReasonRerun    = "rerun"
+)
+
+// JournalEntry records an event of a run of a job in a Journal.
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+	// Job is the name of the job, and ID its stable ID, see WithID.
+	Job string `json:"job"`
+	ID  string `json:"id,omitempty"`
+	// Run is the key of the run, see RunKey.
+	Run string `json:"run"`
+	// Event is JournalStart, JournalEnd, or JournalSkip for a run that could
+	// not take its lock, see ExclusiveWith and WithLocker.
+	Event string `json:"event"`
+	// Reason is why the run started: ReasonSchedule, ReasonTrigger,
+	// ReasonQueue for runs queued while the previous one was executing, or
+	// ReasonRerun for reruns of crashed runs, see Resume.
+	Reason string `json:"reason"`
+	// Error is the error of a failed run and Duration how long it took, for
+	// JournalEnd events.
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Journal records the start, end and outcome of every run of a scheduler's
+// jobs, see WithJournal. Record must be safe for concurrent use.
+type Journal interface {
+	Record(entry JournalEntry) error
+}
+
+// WithJournal sets the journal that records the runs of the scheduler's jobs.
+// Errors of the journal are logged, see WithDefaultLogger, and do not affect
+// the runs.
+func (s *Scheduler) WithJournal(jr Journal) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.journal = jr
+	return s
+}
+
+// journalize records an event of the run with context ctx in the journal of
+// the job's scheduler, if it has one.
+func (j *Job) journalize(ctx context.Context, event, reason string, result *RunResult) {
+	if j.scheduler == nil {
+		return
+	}
+	s := j.scheduler
+	s.Lock()
+	jr := s.journal
+	s.Unlock()
+	if jr == nil {
+		return
+	}
+	j.RLock()
+	entry := JournalEntry{Time: j.now(), Job: j.name, ID: j.id, Run: RunKey(ctx), Event: event, Reason: reason}
+	j.RUnlock()
+	if result != nil {
+		entry.Duration = result.Duration
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		}
+	}
+	if err := jr.Record(entry); err != nil {
+		s.logError("journal failed", err)
+	}
+}
+
+// NewJournal returns a Journal that writes its entries to w as lines of JSON.
+func NewJournal(w io.Writer) Journal {
+	return &writerJournal{w: w}
+}
+
+type writerJournal struct {
+	w io.Writer
+	sync.Mutex
+}
+
+func (jr *writerJournal) Record(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	jr.Lock()
+	defer jr.Unlock()
+
+	_, err = jr.w.Write(append(line, '\n'))
+	return err
+}
+
+// FileJournal is a Journal kept in a file as lines of JSON. Once the file
+// would grow past its maximum size, it is rotated: path is renamed path.1,
+// path.1 is renamed path.2 and so on, and the oldest files beyond the number
+// to keep are removed.
+type FileJournal struct {
+	path    string
+	maxSize int64
+	keep    int
+	f       *os.File
+	size    int64
+	closed  bool
+	sync.Mutex
+}
+
+// OpenJournal opens the journal file at path for appending, creating it if
+// needed. The file is rotated when it would grow past maxSize bytes, keeping
+// keep rotated files.
+func OpenJournal(path string, maxSize int64, keep int) (*FileJournal, error) {
+	if maxSize <= 0 || keep < 0 {
+		return nil, errors.New("bad journal rotation")
+	}
+	jr := &FileJournal{path: path, maxSize: maxSize, keep: keep}
+	if err := jr.open(); err != nil {
+		return nil, err
+	}
+	if err := jr.endLine(); err != nil {
+		jr.f.Close()
+		return nil, err
+	}
+	return jr, nil
+}
+
+// endLine ends the last line of the file, if a crash cut it short, so the
+// next entry starts on a line of its own.
+func (jr *FileJournal) endLine() error {
+	if jr.size == 0 {
+		return nil
+	}
+	f, err := os.Open(jr.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, jr.size-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	n, err := jr.f.Write([]byte{'\n'})
+	jr.size += int64(n)
+	return err
+}
+
+func (jr *FileJournal) open() error {
+	f, err := os.OpenFile(jr.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	jr.f, jr.size = f, info.Size()
+	return nil
+}
+
+// Record implements Journal.
+func (jr *FileJournal) Record(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	jr.Lock()
+	defer jr.Unlock()
+
+	if jr.closed {
+		return errors.New("journal closed")
+	}
+	if jr.f == nil {
+		// A failed rotation could not reopen the file.
+		if err := jr.open(); err != nil {
+			return err
+		}
+	}
+	if jr.size > 0 && jr.size+int64(len(line)) > jr.maxSize {
+		if err := jr.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := jr.f.Write(line)
+	jr.size += int64(n)
+	return err
+}
+
+// rotate moves the current file out of the way and opens a new one. If the
+// files cannot be moved, the current one is reopened and the journal goes on
+// in it.
+func (jr *FileJournal) rotate() error {
+	err := jr.f.Close()
+	jr.f = nil
+	if err == nil {
+		err = jr.shift()
+	}
+	if openErr := jr.open(); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+// shift renames the current file path.1 and each rotated file to the next
+// one, removing the oldest.
+func (jr *FileJournal) shift() error {
+	if jr.keep == 0 {
+		return os.Remove(jr.path)
+	}
+	if err := os.Remove(jr.rotated(jr.keep)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := jr.keep - 1; i >= 1; i-- {
+		if err := os.Rename(jr.rotated(i), jr.rotated(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(jr.path, jr.rotated(1))
+}
+
+// rotated returns the path of the ith rotated file.
+func (jr *FileJournal) rotated(i int) string {
+	return fmt.Sprintf("%s.%d", jr.path, i)
+}
+
+// Entries returns the entries of the journal, oldest first, from its rotated
+// files and the current one, e.g. for Resume. Lines that cannot be decoded,
+// such as one cut short by a crash, are skipped.
+func (jr *FileJournal) Entries() ([]JournalEntry, error) {
+	jr.Lock()
+	defer jr.Unlock()
+
+	var entries []JournalEntry
+	for i := jr.keep; i >= 0; i-- {
+		path := jr.path
+		if i > 0 {
+			path = jr.rotated(i)
+		}
+		read, err := readJournal(path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, read...)
+	}
+	return entries, nil
+}
+
+// readJournal reads the entries of a journal file, if it exists.
+func readJournal(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var entry JournalEntry
+		if json.Unmarshal(sc.Bytes(), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// Close closes the journal file.
+func (jr *FileJournal) Close() error {
+	jr.Lock()
+	defer jr.Unlock()
+
+	jr.closed = true
+	if jr.f == nil {
+		return nil
+	}
+	err := jr.f.Close()
+	jr.f = nil
+	return err
+}
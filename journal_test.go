@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryJournal struct {
+	entries []JournalEntry
+	sync.Mutex
+}
+
+func (m *memoryJournal) Record(entry JournalEntry) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memoryJournal) events() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	var events []string
+	for _, e := range m.entries {
+		events = append(events, e.Event+" "+e.Reason+" "+e.Error)
+	}
+	return events
+}
+
+func TestJournal(t *testing.T) {
+	jr := &memoryJournal{}
+	s := NewScheduler().WithJournal(jr)
+	fail := false
+	job := s.Every(1).Hours().Named("report").WithID("r1")
+	done := job.Done()
+	_, err := job.RunErr(func(context.Context) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	<-done
+	fail = true
+	job.Trigger()
+	<-done
+
+	assert.Equal(t, []string{"start schedule ", "end schedule ", "start trigger ", "end trigger boom"}, jr.events())
+	jr.Lock()
+	first := jr.entries[0]
+	jr.Unlock()
+	assert.Equal(t, "report", first.Job)
+	assert.Equal(t, "r1", first.ID)
+	assert.True(t, strings.HasPrefix(first.Run, "r1@"))
+}
+
+func TestNewJournal(t *testing.T) {
+	var buf bytes.Buffer
+	jr := NewJournal(&buf)
+	assert.Nil(t, jr.Record(JournalEntry{Job: "report", Event: JournalStart, Reason: ReasonTrigger}))
+	var entry JournalEntry
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "report", entry.Job)
+	assert.Equal(t, JournalStart, entry.Event)
+}
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	jr, err := OpenJournal(path, 200, 2)
+	assert.Nil(t, err)
+	defer jr.Close()
+
+	entry := JournalEntry{Time: time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC), Job: "report", Event: JournalEnd, Reason: ReasonSchedule}
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, jr.Record(entry))
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		assert.Nil(t, err)
+		assert.True(t, info.Size() <= 200)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, jr.Close())
+	assert.NotNil(t, jr.Record(entry))
+	_, err = OpenJournal(path, 0, 1)
+	assert.NotNil(t, err)
+}
+
+func TestFileJournalRotateError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	jr, err := OpenJournal(path, 200, 1)
+	assert.Nil(t, err)
+	defer jr.Close()
+
+	// A directory that is not empty cannot be removed to make way.
+	assert.Nil(t, os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o755))
+	entry := JournalEntry{Time: time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC), Job: "report", Event: JournalEnd, Reason: ReasonSchedule}
+	for err == nil {
+		err = jr.Record(entry)
+	}
+	assert.NotNil(t, jr.Record(entry))
+
+	assert.Nil(t, os.RemoveAll(path+".1"))
+	assert.Nil(t, jr.Record(entry))
+	entries, err := jr.Entries()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, entries)
+	_, err = os.Stat(path + ".1")
+	assert.Nil(t, err)
+}
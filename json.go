@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// jobJSON is the JSON form of a job, see MarshalJSON.
+type jobJSON struct {
+	Name        string     `json:"name,omitempty"`
+	Schedule    string     `json:"schedule,omitempty"`
+	Description string     `json:"description"`
+	Options     jobOptions `json:"options"`
+	Stats       jobStats   `json:"stats"`
+	Paused      bool       `json:"paused"`
+	Running     bool       `json:"running"`
+	LastRun     time.Time  `json:"lastRun,omitzero"`
+	NextRun     time.Time  `json:"nextRun,omitzero"`
+	LastError   string     `json:"lastError,omitempty"`
+}
+
+type jobOptions struct {
+	Timeout           duration `json:"timeout,omitempty"`
+	Attempts          int      `json:"attempts,omitempty"`
+	RetryDelay        duration `json:"retryDelay,omitempty"`
+	Queue             int      `json:"queue,omitempty"`
+	MaxErrorRate      float64  `json:"maxErrorRate,omitempty"`
+	DeadlineAtNextRun bool     `json:"deadlineAtNextRun,omitempty"`
+	Inline            bool     `json:"inline,omitempty"`
+}
+
+type jobStats struct {
+	Runs     int      `json:"runs"`
+	Skipped  int      `json:"skipped"`
+	Failed   int      `json:"failed"`
+	Drift    duration `json:"drift,omitempty"`
+	MaxDrift duration `json:"maxDrift,omitempty"`
+}
+
+// duration is a time.Duration written in JSON as by its String method.
+type duration time.Duration
+
+func (d duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(str)
+	*d = duration(parsed)
+	return err
+}
+
+// MarshalJSON implements json.Marshaler. It writes the job's name, schedule,
+// options, stats, and its last and next run, e.g. for monitoring endpoints.
+// The schedule is written in the syntax of ParseSchedule or Cron if it has
+// one, and is otherwise only described. Functions, such as hooks, are left
+// out.
+func (j *Job) MarshalJSON() ([]byte, error) {
+	j.RLock()
+	defer j.RUnlock()
+
+	v := jobJSON{
+		Name:        j.name,
+		Description: j.describe(),
+		Options: jobOptions{
+			Timeout:           duration(j.timeout),
+			Attempts:          j.attempts,
+			RetryDelay:        duration(j.delay),
+			Queue:             j.queue,
+			MaxErrorRate:      j.maxErrors,
+			DeadlineAtNextRun: j.deadline,
+			Inline:            j.inline,
+		},
+		Stats: jobStats{
+			Runs:     j.stats.Runs,
+			Skipped:  j.stats.Skipped,
+			Failed:   j.stats.Failed,
+			Drift:    duration(j.stats.Drift),
+			MaxDrift: duration(j.stats.MaxDrift),
+		},
+		Paused:  j.paused,
+		Running: j.isRunning,
+		LastRun: j.startedAt,
+		NextRun: j.nextAt,
+	}
+	if j.schedule != nil {
+		v.Schedule, _ = specOf(j.schedule)
+	}
+	if j.lastErr != nil {
+		v.LastError = j.lastErr.Error()
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It restores a job written by
+// MarshalJSON that has not been run yet: its name, schedule, options, stats,
+// last run and last error. Jobs whose schedule was only described keep the one
+// they were built with, so their state can be loaded into a job built by the
+// program, as in:
+//
+//	job := scheduler.Every().Month().LastDay().At("23:00")
+//	err := json.Unmarshal(saved, job)
+//
+// The next run is worked out again when the job is run.
+func (j *Job) UnmarshalJSON(data []byte) error {
+	var v jobJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	j.Lock()
+	defer j.Unlock()
+
+	if j.changes != nil {
+		return errors.New("job already running")
+	}
+	if v.Schedule != "" {
+		schedule, err := parseSpec(v.Schedule)
+		if err != nil {
+			return err
+		}
+		j.schedule = schedule
+	}
+	if j.schedule == nil {
+		return errors.New("job has no schedule")
+	}
+	switch o := v.Options; {
+	case o.Timeout < 0:
+		return errors.New("bad timeout")
+	case o.Attempts < 0 || o.RetryDelay < 0:
+		return errors.New("bad retry policy")
+	case o.Queue < 0:
+		return errors.New("bad queue size")
+	case o.MaxErrorRate < 0 || o.MaxErrorRate > 1:
+		return errors.New("bad error rate")
+	}
+	j.timeout = time.Duration(v.Options.Timeout)
+	j.attempts = v.Options.Attempts
+	j.delay = time.Duration(v.Options.RetryDelay)
+	j.queue = v.Options.Queue
+	j.maxErrors = v.Options.MaxErrorRate
+	j.deadline = v.Options.DeadlineAtNextRun
+	j.inline = v.Options.Inline
+	j.name = v.Name
+	j.paused = v.Paused
+	j.stats = Stats{
+		Runs:     v.Stats.Runs,
+		Skipped:  v.Stats.Skipped,
+		Failed:   v.Stats.Failed,
+		Drift:    time.Duration(v.Stats.Drift),
+		MaxDrift: time.Duration(v.Stats.MaxDrift),
+	}
+	j.startedAt = v.LastRun
+	j.lastErr = nil
+	if v.LastError != "" {
+		j.lastErr = errors.New(v.LastError)
+	}
+	return nil
+}
+
+// specOf returns the schedule written in the syntax of ParseSchedule or Cron,
+// if it can be.
+func specOf(s scheduled) (string, bool) {
+	zone := func(d *daily) string {
+		if d.loc == nil || d.loc == time.Local {
+			return ""
+		}
+		return " in " + d.loc.String()
+	}
+	switch s := s.(type) {
+	case *recurrent:
+		if s.phased || s.anchored || s.adapt != nil || s.days != 0 {
+			return "", false
+		}
+		return "every " + (time.Duration(s.units) * s.period).String(), true
+	case *daily:
+		return "daily " + s.timeString() + zone(s), true
+	case *weekly:
+		return strings.ToLower(s.day.String()) + " " + s.d.timeString() + zone(&s.d), true
+	case *cron:
+		if s.key != s.spec {
+			return "", false
+		}
+		return s.spec, true
+	}
+	return "", false
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobJSON(t *testing.T) {
+	job := Every().Sunday().At("08:30").Timezone("Europe/Madrid").Named("report").
+		Timeout(time.Minute).Retry(3, time.Second).Queue(1, nil).MaxErrorRate(0.5)
+	job.stats = Stats{Runs: 4, Skipped: 1, Failed: 2}
+	job.startedAt = time.Date(2015, 6, 7, 8, 30, 0, 0, time.UTC)
+	job.lastErr = errors.New("boom")
+
+	data, err := json.Marshal(job)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{
+		"name": "report",
+		"schedule": "sunday 08:30:00 in Europe/Madrid",
+		"description": "every Sunday at 08:30:00",
+		"options": {"timeout": "1m0s", "attempts": 3, "retryDelay": "1s", "queue": 1, "maxErrorRate": 0.5},
+		"stats": {"runs": 4, "skipped": 1, "failed": 2},
+		"paused": false,
+		"running": false,
+		"lastRun": "2015-06-07T08:30:00Z",
+		"lastError": "boom"
+	}`, string(data))
+
+	restored := new(Job)
+	assert.Nil(t, json.Unmarshal(data, restored))
+	assert.Equal(t, job.String(), restored.String())
+	assert.Equal(t, job.Stats(), restored.Stats())
+	assert.Equal(t, job.State(), restored.State())
+	again, err := json.Marshal(restored)
+	assert.Nil(t, err)
+	assert.Equal(t, string(data), string(again))
+}
+
+func TestJobJSONDescribedSchedule(t *testing.T) {
+	data, err := json.Marshal(Every().Month().LastDay().At("23:00").Named("close"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), `"schedule"`)
+
+	assert.EqualError(t, json.Unmarshal(data, new(Job)), "job has no schedule")
+	job := Every().Month().LastDay().At("23:00")
+	assert.Nil(t, json.Unmarshal(data, job))
+	assert.Equal(t, "close", job.Name())
+
+	assert.NotNil(t, json.Unmarshal([]byte(`{"schedule": "whenever"}`), new(Job)))
+	assert.NotNil(t, json.Unmarshal([]byte(`{"schedule": "every 1h", "options": {"queue": -1}}`), new(Job)))
+
+	running, err := Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	defer running.Stop()
+	assert.EqualError(t, json.Unmarshal(data, running), "job already running")
+}
@@ -0,0 +1,107 @@
+// Package k8sleader runs a scheduler only in the pod elected leader through a
+// Kubernetes Lease, so a Deployment with several replicas runs each job once.
+// The other pods keep their scheduler on standby and take over when the
+// leader's lease expires.
+//
+//	config, err := rest.InClusterConfig()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	client := kubernetes.NewForConfigOrDie(config)
+//	s := scheduler.NewScheduler()
+//	s.Standby()
+//	s.Every(5).Minutes().Run(poll)
+//	err = k8sleader.Run(ctx, s, client, k8sleader.Config{Namespace: "myapp", Name: "myapp-scheduler"})
+package k8sleader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// Default timings of the election, as used by the Kubernetes controllers.
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// Config names the Lease the pods compete for and tunes the election.
+type Config struct {
+	Namespace string
+	Name      string
+	// Identity tells the pods apart. It defaults to the hostname, which is
+	// the pod name.
+	Identity string
+	// LeaseDuration, RenewDeadline and RetryPeriod default to the constants
+	// above. See leaderelection.LeaderElectionConfig.
+	LeaseDuration time.Duration
+	RenewDeadline time.Duration
+	RetryPeriod   time.Duration
+}
+
+// Run puts the scheduler on standby and then competes for the lease until ctx
+// is done, activating the scheduler while this pod is the leader. It releases
+// the lease on return, so another pod can take over without waiting for it to
+// expire. Runs in progress when leadership is lost are not interrupted.
+//
+// Put the scheduler on standby yourself before registering its jobs: jobs
+// start as soon as they are registered and most run right away, so those
+// registered on an active scheduler run in every pod before Run is called.
+func Run(ctx context.Context, s *scheduler.Scheduler, client kubernetes.Interface, c Config) error {
+	if c.Namespace == "" || c.Name == "" {
+		return errors.New("lease namespace and name required")
+	}
+	if c.Identity == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return err
+		}
+		c.Identity = host
+	}
+	if c.LeaseDuration == 0 {
+		c.LeaseDuration = DefaultLeaseDuration
+	}
+	if c.RenewDeadline == 0 {
+		c.RenewDeadline = DefaultRenewDeadline
+	}
+	if c.RetryPeriod == 0 {
+		c.RetryPeriod = DefaultRetryPeriod
+	}
+
+	s.Standby()
+	defer s.Standby()
+	config := leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: c.Namespace, Name: c.Name},
+			Client:     client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: c.Identity},
+		},
+		LeaseDuration:   c.LeaseDuration,
+		RenewDeadline:   c.RenewDeadline,
+		RetryPeriod:     c.RetryPeriod,
+		ReleaseOnCancel: true,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) { s.Activate() },
+			OnStoppedLeading: s.Standby,
+		},
+	}
+	// An elector returns when it loses the lease; campaign again so this pod
+	// can take over later.
+	for ctx.Err() == nil {
+		elector, err := leaderelection.NewLeaderElector(config)
+		if err != nil {
+			return err
+		}
+		elector.Run(ctx)
+	}
+	return nil
+}
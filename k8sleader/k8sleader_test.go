@@ -0,0 +1,51 @@
+package k8sleader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// pod returns a scheduler on standby and the config of a pod competing for the
+// same lease as the others.
+func pod(identity string) (*scheduler.Scheduler, Config) {
+	s := scheduler.NewScheduler()
+	s.Standby()
+	return s, Config{Namespace: "default", Name: "scheduler", Identity: identity, RetryPeriod: 10 * time.Millisecond}
+}
+
+func TestRun(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	a, configA := pod("a")
+	b, configB := pod("b")
+
+	ctxA, stopA := context.WithCancel(context.Background())
+	doneA := make(chan error)
+	go func() { doneA <- Run(ctxA, a, client, configA) }()
+	for !a.IsActive() {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctxB, stopB := context.WithCancel(context.Background())
+	defer stopB()
+	go Run(ctxB, b, client, configB)
+	time.Sleep(100 * time.Millisecond)
+	assert.False(t, b.IsActive())
+
+	// The leader releases the lease on the way out, so b takes over quickly.
+	stopA()
+	assert.Nil(t, <-doneA)
+	assert.False(t, a.IsActive())
+	for !b.IsActive() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRunConfig(t *testing.T) {
+	err := Run(context.Background(), scheduler.NewScheduler(), fake.NewSimpleClientset(), Config{Name: "scheduler"})
+	assert.EqualError(t, err, "lease namespace and name required")
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// labeled runs f with pprof labels naming the job and the kind of its
+// schedule, so CPU and goroutine profiles attribute each run to its job. Jobs
+// without a name are labeled with their description.
+func (j *Job) labeled(ctx context.Context, f func(context.Context)) {
+	j.RLock()
+	job := j.name
+	if job == "" {
+		job = j.describe()
+	}
+	kind := scheduleKind(j.schedule)
+	j.RUnlock()
+	pprof.Do(ctx, pprof.Labels("job", job, "schedule", kind), f)
+}
+
+func scheduleKind(s scheduled) string {
+	switch s.(type) {
+	case *recurrent:
+		return "interval"
+	case *daily:
+		return "daily"
+	case *weekly, *everyWeeks, rota:
+		return "weekly"
+	case *monthly, *monthlyWeekday:
+		return "monthly"
+	case dates:
+		return "dates"
+	case *rrule:
+		return "rrule"
+	case union, intersection:
+		return "composite"
+	case quartzSchedule:
+		return "quartz"
+	case *backoff:
+		return "backoff"
+	case *delay, *once:
+		return "once"
+	case *cron:
+		return "cron"
+	}
+	return "custom"
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLocked is returned by a Locker when another process holds the lock.
+var ErrLocked = errors.New("job locked by another process")
+
+// ErrLockLost is the cause of the context of a run whose lock was lost while
+// the run was in progress, see LoseLock.
+var ErrLockLost = errors.New("job lock lost")
+
+// Locker makes sure a named job runs in one process at a time, e.g. across the
+// replicas of a service that all schedule the same jobs. Lock acquires the lock
+// called name, or returns ErrLocked if another process holds it, and unlock
+// releases it. Implementations backed by leases must keep them alive until
+// unlock is called, however long the run takes, and call LoseLock with ctx if
+// they fail to.
+type Locker interface {
+	Lock(ctx context.Context, name string) (unlock func(), err error)
+}
+
+// WithLocker sets the locker that each run of the scheduler's named jobs must
+// lock first. Runs that cannot lock are skipped. Jobs without a name are not
+// locked.
+func (s *Scheduler) WithLocker(l Locker) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.locker = l
+	return s
+}
+
+// lock takes the job's exclusion, see ExclusiveWith, and its lock for a run,
+// if it needs them. It returns false if the run must be skipped, counting it
+// as such.
+// The run must use the context it returns, which is cancelled if the lock is
+// lost.
+func (j *Job) lock(ctx context.Context) (_ context.Context, unlock func(), ok bool) {
+	release, ok := j.exclude(ctx)
+	if !ok {
+		j.skipRun()
+		return nil, nil, false
+	}
+	ctx, unlockName, ok := j.lockName(ctx)
+	if !ok {
+		release()
+		return nil, nil, false
+	}
+	return ctx, func() {
+		unlockName()
+		release()
+	}, true
+}
+
+// lockName takes the lock of the job's name from its scheduler's Locker, if
+// it has one. The locker gets, and the run must use, a context it can cancel
+// with LoseLock; it is released along with ctx.
+func (j *Job) lockName(ctx context.Context) (_ context.Context, unlock func(), ok bool) {
+	name := j.Name()
+	if j.scheduler == nil || name == "" {
+		return ctx, func() {}, true
+	}
+	s := j.scheduler
+	s.Lock()
+	l := s.locker
+	s.Unlock()
+	if l == nil {
+		return ctx, func() {}, true
+	}
+	ctx, lose := context.WithCancelCause(ctx)
+	ctx = context.WithValue(ctx, loseKey{}, lose)
+	unlock, err := l.Lock(ctx, name)
+	if err != ErrLocked {
+		s.Lock()
+		s.lockErr = err
+		s.Unlock()
+	}
+	if err != nil {
+		lose(nil)
+		j.skipRun()
+		return nil, nil, false
+	}
+	return ctx, unlock, true
+}
+
+type loseKey struct{}
+
+// LoseLock cancels the run whose lock was taken with context ctx, the one
+// given to Locker.Lock, with ErrLockLost as its cause. Lockers call it when
+// they can no longer hold a lock, e.g. once its lease expired, so the run
+// stops rather than go on alongside a run of the same job in another process.
+// Runs only stop if their function heeds its context.
+func LoseLock(ctx context.Context) {
+	if lose, ok := ctx.Value(loseKey{}).(context.CancelCauseFunc); ok {
+		lose(ErrLockLost)
+	}
+}
+
+// skipRun counts a run that was started as skipped instead.
+func (j *Job) skipRun() {
+	j.Lock()
+	j.record(func(s *Stats) {
+		s.Runs--
+		s.Skipped++
+	})
+	j.Unlock()
+}
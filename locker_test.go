@@ -0,0 +1,131 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryLocker struct {
+	held  map[string]bool
+	locks int
+	err   error
+	mu    sync.Mutex
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{held: make(map[string]bool)}
+}
+
+func (m *memoryLocker) Lock(ctx context.Context, name string) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.held[name] {
+		return nil, ErrLocked
+	}
+	m.held[name] = true
+	m.locks++
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		delete(m.held, name)
+	}, nil
+}
+
+func (m *memoryLocker) isHeld(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.held[name]
+}
+
+func TestLocker(t *testing.T) {
+	l := newMemoryLocker()
+	s := NewScheduler().WithLocker(l)
+	held := make(chan bool, 1)
+	job, err := s.Every(1).Hours().Named("report").Run(func() {
+		held <- l.isHeld("report")
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	assert.True(t, <-held)
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, l.isHeld("report"))
+	assert.Equal(t, Stats{Runs: 1}, counters(job.Stats()))
+}
+
+func TestLockerHeldElsewhere(t *testing.T) {
+	l := newMemoryLocker()
+	l.held["report"] = true
+	s := NewScheduler().WithLocker(l)
+	ran := make(chan bool, 1)
+	job, err := s.Every(1).Hours().Named("report").Run(func() { ran <- true })
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	for job.Stats().Skipped == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Len(t, ran, 0)
+	assert.Equal(t, Stats{Skipped: 1}, counters(job.Stats()))
+	assert.Nil(t, s.Healthy())
+}
+
+func TestLockerSkipsUnnamed(t *testing.T) {
+	l := newMemoryLocker()
+	l.err = errors.New("connection refused")
+	s := NewScheduler().WithLocker(l)
+	ran := make(chan bool, 1)
+	job, err := s.Every(1).Hours().Run(func() { ran <- true })
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.True(t, <-ran)
+}
+
+func TestLockerError(t *testing.T) {
+	l := newMemoryLocker()
+	l.err = errors.New("connection refused")
+	s := NewScheduler().WithLocker(l)
+	job, err := s.Every(1).Hours().Named("report").Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	for job.Stats().Skipped == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.EqualError(t, s.Healthy(), "connection refused")
+}
+
+type losingLocker struct{}
+
+func (losingLocker) Lock(ctx context.Context, name string) (func(), error) {
+	go LoseLock(ctx)
+	return func() {}, nil
+}
+
+func TestLoseLock(t *testing.T) {
+	s := NewScheduler().WithLocker(losingLocker{})
+	cause := make(chan error, 1)
+	job, err := s.Every(1).Hours().Named("report").RunCtx(func(ctx context.Context) {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	assert.Equal(t, ErrLockLost, <-cause)
+	assert.Nil(t, job.StopCause())
+	LoseLock(context.Background())
+}
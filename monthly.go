@@ -0,0 +1,494 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DayPolicy decides what a monthly job does in months that do not have the
+// requested day, e.g. day 31 in April or day 29 in a common-year February.
+type DayPolicy int
+
+const (
+	// DayStrict rejects days that some months lack (29 to 31): Run returns an
+	// error. This is the default so month-end jobs never misbehave silently.
+	DayStrict DayPolicy = iota
+	// DayClamp runs on the last day of months shorter than the requested day.
+	DayClamp
+	// DaySkip does not run in months shorter than the requested day.
+	DaySkip
+)
+
+// monthCycle selects the months a monthly job runs in: every nth month,
+// counted from anchor, among those in only, if any. Months are counted
+// continuously across years, so cycles that do not divide a year are still
+// stable across restarts.
+type monthCycle struct {
+	every  int
+	anchor time.Month
+	// only has a bit for each month the job is restricted to, see January.
+	only uint16
+}
+
+func (c *monthCycle) step() int {
+	if c.every < 1 {
+		return 1
+	}
+	return c.every
+}
+
+// first returns the first month in the cycle not before the given one.
+func (c *monthCycle) first(year int, month time.Month) (int, time.Month) {
+	anchor := c.anchor
+	if anchor == 0 {
+		anchor = time.January
+	}
+	offset := (int(anchor) - int(month) - 12*year) % c.step()
+	if offset < 0 {
+		offset += c.step()
+	}
+	date := time.Date(year, month+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+	return date.Year(), date.Month()
+}
+
+// allows reports whether the job may run in month, which may be out of range
+// and is normalized as time.Date does.
+func (c *monthCycle) allows(month time.Month) bool {
+	month = (month-1)%12 + 1
+	if month < time.January {
+		month += 12
+	}
+	return c.only == 0 || c.only&(1<<month) != 0
+}
+
+// names returns the names of the months the job is restricted to.
+func (c *monthCycle) names() []string {
+	var names []string
+	for month := time.January; month <= time.December; month++ {
+		if c.only&(1<<month) != 0 {
+			names = append(names, month.String())
+		}
+	}
+	return names
+}
+
+// byMonth returns the BYMONTH part of the RRULE of the cycle, if restricted.
+func (c *monthCycle) byMonth() string {
+	if c.only == 0 {
+		return ""
+	}
+	var months []string
+	for month := time.January; month <= time.December; month++ {
+		if c.only&(1<<month) != 0 {
+			months = append(months, strconv.Itoa(int(month)))
+		}
+	}
+	return ";BYMONTH=" + strings.Join(months, ",")
+}
+
+func (c *monthCycle) String() string {
+	period := "every month"
+	if c.step() > 1 {
+		period = "every " + strconv.Itoa(c.step()) + " months"
+	}
+	if c.only == 0 {
+		return period
+	}
+	if c.step() == 1 {
+		return "every " + strings.Join(c.names(), ", ")
+	}
+	return period + " in " + strings.Join(c.names(), ", ")
+}
+
+type monthly struct {
+	day     int
+	last    bool
+	quarter bool
+	policy  DayPolicy
+	cycle   monthCycle
+	d       daily
+}
+
+func (m *monthly) months() *monthCycle {
+	return &m.cycle
+}
+
+func (m *monthly) clock() *daily {
+	return &m.d
+}
+
+func (m *monthly) nextRun(now time.Time) (time.Duration, error) {
+	date, err := m.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+func (m *monthly) next(after time.Time) (time.Time, error) {
+	if m.day > 28 && !m.last && m.policy == DayStrict {
+		return time.Time{}, errors.New("day " + strconv.Itoa(m.day) + " does not exist in every month, choose a policy with OnMissingDay")
+	}
+	after = after.In(m.d.location())
+	year, month := m.cycle.first(after.Year(), after.Month())
+	// The months in a cycle repeat within a year and their lengths within a
+	// leap cycle: February 29 can be eight years apart, as from 1896 to
+	// 1904. If the day has not come up in eight years it never will.
+	for i := 0; i*m.cycle.step() <= 8*12; i++ {
+		next := month + time.Month(i*m.cycle.step())
+		if !m.cycle.allows(next) {
+			continue
+		}
+		date, ok := m.in(year, next)
+		if ok && date.After(after) {
+			return date, nil
+		}
+	}
+	return time.Time{}, errNoNextRun
+}
+
+// in returns the run time in the given month, if there is one.
+func (m *monthly) in(year int, month time.Month) (time.Time, bool) {
+	day := m.day
+	if last := daysIn(year, month); m.last {
+		day = last
+	} else if day > last {
+		if m.policy == DaySkip {
+			return time.Time{}, false
+		}
+		day = last
+	}
+	return m.d.on(year, month, day), true
+}
+
+// Next implements Schedule.
+func (m *monthly) Next(after time.Time) time.Time {
+	date, _ := m.next(after)
+	return date
+}
+
+func (m *monthly) description() string {
+	period, day := m.cycle.String(), "day "+strconv.Itoa(m.day)
+	if m.quarter && m.cycle.only == 0 {
+		period = "every quarter"
+	}
+	if m.last {
+		day = "the last day"
+	}
+	return period + " on " + day + " at " + m.d.timeString()
+}
+
+// daysIn returns the number of days in month, which may be out of range and is
+// normalized as time.Date does.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// Month sets the job to run every month, on the first day unless OnDay says
+// otherwise.
+func (j *Job) Month() *Job {
+	if j.schedule != nil {
+		j.err = errors.New("bad function chaining")
+	}
+	j.schedule = &monthly{day: 1, cycle: monthCycle{every: 1}}
+	return j
+}
+
+// Months sets the job to run every n months, where n was defined in the Every
+// function. The cycle starts in January unless FromMonth says otherwise, so
+// Every(3).Months() runs in January, April, July and October.
+func (j *Job) Months() *Job {
+	if j.err != nil {
+		return j
+	}
+	r, ok := j.schedule.(*recurrent)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if r.units < 1 {
+		j.err = errors.New("cannot set recurrent time with 0")
+		return j
+	}
+	j.schedule = &monthly{day: 1, cycle: monthCycle{every: r.units}}
+	return j
+}
+
+// FromMonth anchors the cycle of a job running every n months to the given
+// month, e.g. Every(6).Months().FromMonth(time.March) runs in March and
+// September.
+func (j *Job) FromMonth(month time.Month) *Job {
+	if j.err != nil {
+		return j
+	}
+	c, ok := j.schedule.(interface {
+		months() *monthCycle
+	})
+	if !ok || month < time.January || month > time.December {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	c.months().anchor = month
+	return j
+}
+
+// January sets the job to run in January, on the first day unless OnDay or
+// another selector says otherwise. Month selectors add up, so
+// Every().January().July().OnDay(15) runs on January 15 and July 15, and
+// restrict monthly jobs to their months, as in
+// Every().Month().On(scheduler.Last, time.Friday).December().
+func (j *Job) January() *Job {
+	return j.inMonth(time.January)
+}
+
+// February sets the job to run in February, see January.
+func (j *Job) February() *Job {
+	return j.inMonth(time.February)
+}
+
+// March sets the job to run in March, see January.
+func (j *Job) March() *Job {
+	return j.inMonth(time.March)
+}
+
+// April sets the job to run in April, see January.
+func (j *Job) April() *Job {
+	return j.inMonth(time.April)
+}
+
+// May sets the job to run in May, see January.
+func (j *Job) May() *Job {
+	return j.inMonth(time.May)
+}
+
+// June sets the job to run in June, see January.
+func (j *Job) June() *Job {
+	return j.inMonth(time.June)
+}
+
+// July sets the job to run in July, see January.
+func (j *Job) July() *Job {
+	return j.inMonth(time.July)
+}
+
+// August sets the job to run in August, see January.
+func (j *Job) August() *Job {
+	return j.inMonth(time.August)
+}
+
+// September sets the job to run in September, see January.
+func (j *Job) September() *Job {
+	return j.inMonth(time.September)
+}
+
+// October sets the job to run in October, see January.
+func (j *Job) October() *Job {
+	return j.inMonth(time.October)
+}
+
+// November sets the job to run in November, see January.
+func (j *Job) November() *Job {
+	return j.inMonth(time.November)
+}
+
+// December sets the job to run in December, see January.
+func (j *Job) December() *Job {
+	return j.inMonth(time.December)
+}
+
+// inMonth restricts a monthly job to month, making the job monthly if it has
+// no schedule yet.
+func (j *Job) inMonth(month time.Month) *Job {
+	if j.err != nil {
+		return j
+	}
+	if j.schedule == nil {
+		j.schedule = &monthly{day: 1, cycle: monthCycle{every: 1}}
+	}
+	c, ok := j.schedule.(interface {
+		months() *monthCycle
+	})
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	c.months().only |= 1 << month
+	return j
+}
+
+// OnDay sets the day of the month a monthly job runs on, from 1 to 31. Days
+// that some months lack require a policy set with OnMissingDay.
+func (j *Job) OnDay(day int) *Job {
+	if j.err != nil {
+		return j
+	}
+	m, ok := j.schedule.(*monthly)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if day < 1 || day > 31 {
+		j.err = errors.New("bad day of month")
+		return j
+	}
+	m.day = day
+	m.last = false
+	return j
+}
+
+// Quarter sets the job to run every calendar quarter, on the first day unless
+// LastDay or OnDay say otherwise.
+func (j *Job) Quarter() *Job {
+	if j.schedule != nil {
+		j.err = errors.New("bad function chaining")
+	}
+	j.schedule = &monthly{day: 1, quarter: true, cycle: monthCycle{every: 3}}
+	return j
+}
+
+// FirstDay sets a monthly or quarterly job to run on the first day of the
+// month or quarter.
+func (j *Job) FirstDay() *Job {
+	if j.err != nil {
+		return j
+	}
+	m, ok := j.schedule.(*monthly)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	m.day = 1
+	m.last = false
+	if m.quarter {
+		m.cycle.anchor = time.January
+	}
+	return j
+}
+
+// LastDay sets a monthly or quarterly job to run on the last day of the month
+// or quarter, e.g. on March 31, June 30, September 30 and December 31 for
+// Every().Quarter().LastDay().
+func (j *Job) LastDay() *Job {
+	if j.err != nil {
+		return j
+	}
+	m, ok := j.schedule.(*monthly)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	m.last = true
+	if m.quarter {
+		m.cycle.anchor = time.March
+	}
+	return j
+}
+
+// OnMissingDay sets what a monthly job does in months that do not have its
+// day. See DayPolicy.
+func (j *Job) OnMissingDay(p DayPolicy) *Job {
+	if j.err != nil {
+		return j
+	}
+	m, ok := j.schedule.(*monthly)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	m.policy = p
+	return j
+}
+
+// Week selects an occurrence of a weekday within a month, see On.
+type Week int
+
+// Occurrences of a weekday within a month.
+const (
+	Last   Week = -1
+	First  Week = 1
+	Second Week = 2
+	Third  Week = 3
+	Fourth Week = 4
+)
+
+var weekNames = map[Week]string{Last: "last", First: "first", Second: "second", Third: "third", Fourth: "fourth"}
+
+type monthlyWeekday struct {
+	week  Week
+	day   time.Weekday
+	cycle monthCycle
+	d     daily
+}
+
+func (m *monthlyWeekday) months() *monthCycle {
+	return &m.cycle
+}
+
+func (m *monthlyWeekday) clock() *daily {
+	return &m.d
+}
+
+func (m *monthlyWeekday) nextRun(now time.Time) (time.Duration, error) {
+	date, err := m.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+func (m *monthlyWeekday) next(after time.Time) (time.Time, error) {
+	after = after.In(m.d.location())
+	year, month := m.cycle.first(after.Year(), after.Month())
+	for i := 0; i < 13; i++ {
+		next := month + time.Month(i*m.cycle.step())
+		if !m.cycle.allows(next) {
+			continue
+		}
+		if date := m.in(year, next); date.After(after) {
+			return date, nil
+		}
+	}
+	return time.Time{}, errNoNextRun
+}
+
+// in returns the run time in the given month.
+func (m *monthlyWeekday) in(year int, month time.Month) time.Time {
+	if m.week == Last {
+		last := daysIn(year, month)
+		lastDay := time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday()
+		return m.d.on(year, month, last-(int(lastDay)-int(m.day)+7)%7)
+	}
+	firstDay := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	return m.d.on(year, month, 1+(int(m.day)-int(firstDay)+7)%7+7*(int(m.week)-1))
+}
+
+// Next implements Schedule.
+func (m *monthlyWeekday) Next(after time.Time) time.Time {
+	date, _ := m.next(after)
+	return date
+}
+
+func (m *monthlyWeekday) description() string {
+	return m.cycle.String() + " on the " + weekNames[m.week] + " " + m.day.String() + " at " + m.d.timeString()
+}
+
+// On sets a monthly job to run on the given occurrence of a weekday, e.g.
+// On(scheduler.Second, time.Tuesday) for the second Tuesday of every month.
+func (j *Job) On(week Week, day time.Weekday) *Job {
+	if j.err != nil {
+		return j
+	}
+	m, ok := j.schedule.(*monthly)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if _, ok := weekNames[week]; !ok {
+		j.err = errors.New("bad week of month")
+		return j
+	}
+	j.schedule = &monthlyWeekday{week: week, day: day, cycle: m.cycle, d: m.d}
+	return j
+}
@@ -0,0 +1,236 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func date(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.Local)
+}
+
+func TestEveryMonth(t *testing.T) {
+	job, err := Every().Month().Run(test)
+	assert.Nil(t, err)
+	testDay(t, job, err, time.Now().AddDate(0, 1, 1-time.Now().Day()), 0, 0, 0)
+}
+
+func TestEveryMonthOnDay(t *testing.T) {
+	job := Every().Month().OnDay(15).At("08:30")
+	assert.Equal(t, date(2015, 6, 15, 8, 30), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 7, 15, 8, 30), job.Next(date(2015, 6, 15, 8, 30)))
+	assert.Equal(t, date(2016, 1, 15, 8, 30), job.Next(date(2015, 12, 20, 0, 0)))
+}
+
+func TestEveryMonthClamp(t *testing.T) {
+	job := Every().Month().OnDay(31).OnMissingDay(DayClamp).At("10:00")
+	assert.Equal(t, date(2015, 1, 31, 10, 0), job.Next(date(2015, 1, 3, 0, 0)))
+	assert.Equal(t, date(2015, 2, 28, 10, 0), job.Next(date(2015, 1, 31, 10, 0)))
+	assert.Equal(t, date(2016, 2, 29, 10, 0), job.Next(date(2016, 2, 1, 0, 0)))
+	assert.Equal(t, date(2015, 4, 30, 10, 0), job.Next(date(2015, 3, 31, 12, 0)))
+}
+
+func TestEveryMonthSkip(t *testing.T) {
+	job := Every().Month().OnDay(31).OnMissingDay(DaySkip)
+	assert.Equal(t, date(2015, 3, 31, 0, 0), job.Next(date(2015, 1, 31, 10, 0)))
+	assert.Equal(t, date(2015, 5, 31, 0, 0), job.Next(date(2015, 3, 31, 10, 0)))
+
+	job = Every().Month().OnDay(29).OnMissingDay(DaySkip)
+	assert.Equal(t, date(2016, 2, 29, 0, 0), job.Next(date(2016, 2, 1, 0, 0)))
+	assert.Equal(t, date(2015, 3, 29, 0, 0), job.Next(date(2015, 2, 1, 0, 0)))
+
+	job = Every().February().OnDay(29).OnMissingDay(DaySkip)
+	assert.Equal(t, date(2028, 2, 29, 0, 0), job.Next(date(2026, 3, 1, 0, 0)))
+	assert.Equal(t, date(1904, 2, 29, 0, 0), job.Next(date(1896, 3, 1, 0, 0)))
+}
+
+func TestEveryMonthStrict(t *testing.T) {
+	job, err := Every().Month().OnDay(30).Run(test)
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+
+	job, err = Every().Month().OnDay(28).OnMissingDay(DayStrict).Run(test)
+	assert.Nil(t, err)
+	assert.NotNil(t, job)
+}
+
+func TestBadMonthChain(t *testing.T) {
+	for _, job := range []*Job{
+		Every().Month().OnDay(0),
+		Every().Month().OnDay(32),
+		Every().Day().OnDay(1),
+		Every(1).Month(),
+		Every().Monday().OnMissingDay(DayClamp),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestEveryMonthOnWeekday(t *testing.T) {
+	job := Every().Month().On(Second, time.Tuesday).At("10:00")
+	assert.Equal(t, date(2015, 6, 9, 10, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 7, 14, 10, 0), job.Next(date(2015, 6, 9, 10, 0)))
+	assert.Equal(t, date(2015, 9, 8, 10, 0), job.Next(date(2015, 9, 1, 0, 0)))
+	assert.Equal(t, "every month on the second Tuesday at 10:00:00", job.Description())
+
+	job = Every().Month().On(First, time.Monday)
+	assert.Equal(t, date(2015, 6, 1, 0, 0), job.Next(date(2015, 5, 31, 0, 0)))
+	assert.Equal(t, date(2015, 7, 6, 0, 0), job.Next(date(2015, 6, 1, 0, 0)))
+
+	job = Every().Month().On(Fourth, time.Sunday)
+	assert.Equal(t, date(2015, 2, 22, 0, 0), job.Next(date(2015, 2, 1, 0, 0)))
+}
+
+func TestEveryMonthOnLastWeekday(t *testing.T) {
+	job := Every().Month().On(Last, time.Friday).At("17:00")
+	assert.Equal(t, date(2015, 6, 26, 17, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 7, 31, 17, 0), job.Next(date(2015, 6, 26, 17, 0)))
+	assert.Equal(t, date(2016, 1, 29, 17, 0), job.Next(date(2015, 12, 25, 18, 0)))
+}
+
+func TestEveryMonthOnRun(t *testing.T) {
+	job, err := Every().Month().On(Third, time.Wednesday).Timezone("UTC").Run(test)
+	assert.Nil(t, err)
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual).UTC()
+	assert.Equal(t, time.Wednesday, runTime.Weekday())
+	assert.True(t, runTime.Day() > 14 && runTime.Day() <= 21)
+}
+
+func TestBadMonthOn(t *testing.T) {
+	_, err := Every().Month().On(Week(5), time.Monday).Run(test)
+	assert.NotNil(t, err)
+	_, err = Every().Day().On(First, time.Monday).Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestEveryNMonths(t *testing.T) {
+	job := Every(3).Months().OnDay(1).At("00:30")
+	assert.Equal(t, date(2015, 7, 1, 0, 30), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 10, 1, 0, 30), job.Next(date(2015, 7, 1, 0, 30)))
+	assert.Equal(t, date(2016, 1, 1, 0, 30), job.Next(date(2015, 10, 1, 0, 30)))
+	assert.Equal(t, date(2015, 1, 1, 0, 30), job.Next(date(2015, 1, 1, 0, 0)))
+	assert.Equal(t, "every 3 months on day 1 at 00:30:00", job.Description())
+}
+
+func TestEveryMonthSelectors(t *testing.T) {
+	job := Every().January().OnDay(1).At("00:00")
+	assert.Equal(t, date(2016, 1, 1, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2017, 1, 1, 0, 0), job.Next(date(2016, 1, 1, 0, 0)))
+	assert.Equal(t, "every January on day 1 at 00:00:00", job.Description())
+
+	job = Every().April().October().OnDay(15).At("09:00")
+	assert.Equal(t, date(2015, 10, 15, 9, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2016, 4, 15, 9, 0), job.Next(date(2015, 10, 15, 9, 0)))
+	assert.Equal(t, "every April, October on day 15 at 09:00:00", job.Description())
+
+	job = Every().Month().On(Last, time.Friday).December()
+	assert.Equal(t, date(2015, 12, 25, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2016, 12, 30, 0, 0), job.Next(date(2015, 12, 25, 0, 0)))
+
+	job = Every(2).Months().OnDay(1).March()
+	assert.Equal(t, date(2016, 3, 1, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, "every 2 months in March on day 1 at 00:00:00", job.Description())
+	assert.Equal(t, time.Time{}, Every(2).Months().February().Next(date(2015, 6, 3, 12, 0)))
+
+	_, err := Every(1).Hours().June().Run(test)
+	assert.NotNil(t, err)
+	_, err = Every().Monday().June().Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestEveryNMonthsFromMonth(t *testing.T) {
+	job := Every(6).Months().FromMonth(time.March).OnDay(15)
+	assert.Equal(t, date(2015, 9, 15, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2016, 3, 15, 0, 0), job.Next(date(2015, 9, 15, 0, 0)))
+
+	job = Every(5).Months().FromMonth(time.February)
+	assert.Equal(t, date(2015, 7, 1, 0, 0), job.Next(date(2015, 2, 1, 0, 0)))
+	assert.Equal(t, date(2015, 12, 1, 0, 0), job.Next(date(2015, 7, 1, 0, 0)))
+	assert.Equal(t, date(2016, 5, 1, 0, 0), job.Next(date(2015, 12, 1, 0, 0)))
+}
+
+func TestEveryNMonthsSkip(t *testing.T) {
+	job := Every(2).Months().FromMonth(time.February).OnDay(31).OnMissingDay(DaySkip)
+	assert.Equal(t, date(2015, 8, 31, 0, 0), job.Next(date(2015, 1, 1, 0, 0)))
+
+	job = Every(12).Months().FromMonth(time.April).OnDay(31).OnMissingDay(DaySkip)
+	_, err := job.Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestEveryNMonthsOnWeekday(t *testing.T) {
+	job := Every(2).Months().On(First, time.Monday)
+	assert.Equal(t, date(2015, 7, 6, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 9, 7, 0, 0), job.Next(date(2015, 7, 6, 0, 0)))
+}
+
+func TestBadMonths(t *testing.T) {
+	for _, job := range []*Job{
+		Every(0).Months(),
+		Every().Months(),
+		Every().Day().FromMonth(time.March),
+		Every(2).Months().FromMonth(time.Month(13)),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestEveryNMonthsAcrossYears(t *testing.T) {
+	job := Every(5).Months().FromMonth(time.February)
+	from := date(2015, 1, 1, 0, 0)
+	var runs []time.Month
+	for i := 0; i < 6; i++ {
+		from = job.Next(from)
+		runs = append(runs, from.Month())
+	}
+	assert.Equal(t, []time.Month{time.February, time.July, time.December, time.May, time.October, time.March}, runs)
+	assert.Equal(t, date(2016, 5, 1, 0, 0), job.Next(date(2016, 1, 1, 0, 0)))
+}
+
+func TestEveryQuarter(t *testing.T) {
+	job := Every().Quarter().FirstDay().At("06:00")
+	assert.Equal(t, date(2015, 7, 1, 6, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 10, 1, 6, 0), job.Next(date(2015, 7, 1, 6, 0)))
+	assert.Equal(t, date(2016, 1, 1, 6, 0), job.Next(date(2015, 10, 1, 6, 0)))
+	assert.Equal(t, "every quarter on day 1 at 06:00:00", job.Description())
+
+	job = Every().Quarter()
+	assert.Equal(t, date(2015, 4, 1, 0, 0), job.Next(date(2015, 1, 1, 0, 0)))
+}
+
+func TestEveryQuarterLastDay(t *testing.T) {
+	job := Every().Quarter().LastDay().At("23:00")
+	assert.Equal(t, date(2015, 6, 30, 23, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 9, 30, 23, 0), job.Next(date(2015, 6, 30, 23, 0)))
+	assert.Equal(t, date(2015, 12, 31, 23, 0), job.Next(date(2015, 9, 30, 23, 0)))
+	assert.Equal(t, date(2016, 3, 31, 23, 0), job.Next(date(2015, 12, 31, 23, 0)))
+	assert.Equal(t, "every quarter on the last day at 23:00:00", job.Description())
+
+	_, err := job.Run(test)
+	assert.Nil(t, err)
+}
+
+func TestEveryMonthLastDay(t *testing.T) {
+	job := Every().Month().LastDay()
+	assert.Equal(t, date(2015, 2, 28, 0, 0), job.Next(date(2015, 2, 3, 0, 0)))
+	assert.Equal(t, date(2015, 3, 31, 0, 0), job.Next(date(2015, 2, 28, 0, 0)))
+	assert.Equal(t, date(2015, 3, 1, 0, 0), Every().Month().LastDay().FirstDay().Next(date(2015, 2, 3, 0, 0)))
+}
+
+func TestBadQuarter(t *testing.T) {
+	for _, job := range []*Job{
+		Every(1).Quarter(),
+		Every().Day().LastDay(),
+		Every().Monday().FirstDay(),
+		Every().Quarter().On(First, time.Monday).LastDay(),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
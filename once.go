@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// PastPolicy decides what a job defined with Once does if its time has already
+// passed when it is started.
+type PastPolicy int
+
+const (
+	// PastFail makes Run return an error. This is the default, as with
+	// OnDates.
+	PastFail PastPolicy = iota
+	// PastRun runs the job immediately, for operations that must happen
+	// even if the program was down at the time, such as a feature flag
+	// flip.
+	PastRun
+)
+
+// once runs a single time, at at. See Once.
+type once struct {
+	at   time.Time
+	late bool
+	// due is set once the job is started.
+	due bool
+	sync.Mutex
+}
+
+// Once defines a job that runs once at t, and then stops and leaves its
+// scheduler, if any, e.g. to lift an embargo. If t has passed when the job is
+// started, Run returns an error unless OnPast says otherwise. A job stopped
+// before it is due never runs.
+func Once(t time.Time) *Job {
+	if t.IsZero() {
+		return &Job{err: errors.New("zero time")}
+	}
+	return &Job{schedule: &once{at: t}}
+}
+
+// OnPast sets what a job defined with Once does if its time has passed when
+// it is started. See PastPolicy.
+func (j *Job) OnPast(p PastPolicy) *Job {
+	if j.err != nil {
+		return j
+	}
+	o, ok := j.schedule.(*once)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	o.late = p == PastRun
+	return j
+}
+
+func (o *once) nextRun(now time.Time) (time.Duration, error) {
+	o.Lock()
+	defer o.Unlock()
+
+	if !o.due {
+		o.due = true
+		if !now.Before(o.at) {
+			if !o.late {
+				return 0, errors.New("time " + o.at.Format(time.RFC3339) + " is past")
+			}
+			return 0, nil
+		}
+	}
+	if now.Before(o.at) {
+		return o.at.Sub(now), nil
+	}
+	return 0, errNoNextRun
+}
+
+func (o *once) next(after time.Time) (time.Time, error) {
+	if after.Before(o.at) {
+		return o.at, nil
+	}
+	return time.Time{}, errNoNextRun
+}
+
+// Next implements Schedule.
+func (o *once) Next(after time.Time) time.Time {
+	date, _ := o.next(after)
+	return date
+}
+
+func (o *once) description() string {
+	return "once at " + o.at.Format(time.RFC3339)
+}
@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnce(t *testing.T) {
+	now := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	lift := now.Add(time.Hour)
+	job := Once(lift)
+	assert.Nil(t, job.err)
+	assert.Equal(t, lift, job.Next(now))
+	assert.True(t, job.Next(lift).IsZero())
+	assert.Equal(t, "once at 2015-06-01T13:00:00Z", job.Description())
+	assert.Equal(t, "once at 2015-06-01 13:00 UTC", job.String())
+
+	next, err := job.schedule.nextRun(now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Hour, next)
+	_, err = job.schedule.nextRun(lift)
+	assert.Equal(t, errNoNextRun, err)
+
+	_, err = Once(now).schedule.nextRun(lift)
+	assert.EqualError(t, err, "time 2015-06-01T12:00:00Z is past")
+	late := Once(now).OnPast(PastRun)
+	next, err = late.schedule.nextRun(lift)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), next)
+	_, err = late.schedule.nextRun(lift)
+	assert.Equal(t, errNoNextRun, err)
+
+	assert.NotNil(t, Once(time.Time{}).err)
+	assert.NotNil(t, After(time.Minute).OnPast(PastRun).err)
+	assert.NotNil(t, Every().Day().OnPast(PastRun).err)
+}
+
+func TestOnceRun(t *testing.T) {
+	s := NewScheduler()
+	runs := make(chan bool, 1)
+	_, err := s.Once(time.Now().Add(-time.Hour)).Run(test)
+	assert.NotNil(t, err)
+	_, err = s.Once(time.Now().Add(-time.Hour)).OnPast(PastRun).Run(func() { runs <- true })
+	assert.Nil(t, err)
+	<-runs
+	for len(s.Jobs()) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// Option configures a job created with NewJob. Options check their arguments
+// as they are applied, so NewJob reports the first bad one.
+type Option func(*Job) error
+
+// NewJob creates and starts a job running fn on schedule, configured by opts,
+// as an alternative to chaining builder methods:
+//
+//	job, err := scheduler.NewJob(scheduler.Every().Day().At("03:00"), cleanup,
+//		scheduler.WithName("cleanup"), scheduler.WithTimeout(time.Hour))
+//
+// Only the schedule is taken from a job given as schedule, not its options.
+func NewJob(schedule Schedule, fn func(), opts ...Option) (*Job, error) {
+	return newJob(nil, schedule, fn, opts)
+}
+
+// NewJob works like the package-level NewJob for a job of this scheduler.
+func (s *Scheduler) NewJob(schedule Schedule, fn func(), opts ...Option) (*Job, error) {
+	return newJob(s, schedule, fn, opts)
+}
+
+func newJob(s *Scheduler, schedule Schedule, fn func(), opts []Option) (*Job, error) {
+	if fn == nil {
+		return nil, errors.New("nil function")
+	}
+	own, err := scheduleOf(schedule)
+	if err != nil {
+		return nil, err
+	}
+	j := &Job{schedule: own, scheduler: s}
+	for _, opt := range opts {
+		if err := opt(j); err != nil {
+			return nil, err
+		}
+	}
+	return j.Run(fn)
+}
+
+// WithName names the job, see Named.
+func WithName(name string) Option {
+	return func(j *Job) error {
+		if name == "" {
+			return errors.New("empty job name")
+		}
+		j.Named(name)
+		return nil
+	}
+}
+
+// WithTimeout limits how long each run may take, see Timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(j *Job) error {
+		return j.Timeout(d).err
+	}
+}
+
+// WithSingleton keeps runs of the job from overlapping without losing any: a
+// run due while the previous one is still executing starts once it finishes,
+// and further ones due in the meantime are skipped. See Queue.
+func WithSingleton() Option {
+	return func(j *Job) error {
+		return j.Queue(1, nil).err
+	}
+}
+
+// WithLocation sets the location a daily, weekly or monthly schedule is
+// interpreted in, see Timezone.
+func WithLocation(loc *time.Location) Option {
+	return func(j *Job) error {
+		c, ok := j.schedule.(calendar)
+		if loc == nil || !ok {
+			return errors.New("location needs a calendar schedule")
+		}
+		c.clock().loc = loc
+		return nil
+	}
+}
+
+// WithSpread offsets the job within its period by a hash of key, see
+// SpreadBy.
+func WithSpread(key string) Option {
+	return func(j *Job) error {
+		return j.SpreadBy(key).err
+	}
+}
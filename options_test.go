@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJob(t *testing.T) {
+	s := NewScheduler()
+	c := make(chan bool, 1)
+	job, err := s.NewJob(Every(1).Hours(), func() { c <- true },
+		WithName("report"), WithTimeout(time.Minute), WithSingleton())
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.True(t, <-c)
+	assert.Equal(t, "report", job.Name())
+	assert.Equal(t, time.Minute, job.timeout)
+	assert.Equal(t, 1, job.queue)
+	assert.Equal(t, job, s.Job("report"))
+}
+
+func TestNewJobWithLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	job, err := NewJob(Every().Day().At("08:00"), test, WithLocation(loc))
+	assert.Nil(t, err)
+	defer job.Stop()
+	from := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2015, 6, 2, 8, 0, 0, 0, loc), job.Next(from))
+}
+
+func TestNewJobErrors(t *testing.T) {
+	for _, c := range []struct {
+		schedule Schedule
+		fn       func()
+		opts     []Option
+	}{
+		{Every(1).Hours(), nil, nil},
+		{nil, test, nil},
+		{Every(1).Hours().At("08:00"), test, nil},
+		{Every(1).Hours(), test, []Option{WithName("")}},
+		{Every(1).Hours(), test, []Option{WithTimeout(0)}},
+		{Every(1).Hours(), test, []Option{WithLocation(time.UTC)}},
+		{Every().Day(), test, []Option{WithLocation(nil)}},
+	} {
+		job, err := NewJob(c.schedule, c.fn, c.opts...)
+		assert.NotNil(t, err)
+		assert.Nil(t, job)
+	}
+}
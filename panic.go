@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// PanicPolicy decides what happens to a job after one of its runs panics, see
+// OnPanicPolicy.
+type PanicPolicy int
+
+const (
+	// PanicContinue keeps the job's schedule, as for any other failed run.
+	PanicContinue PanicPolicy = iota
+	// PanicPause pauses the job for a cool-off period.
+	PanicPause
+	// PanicStop stops the job for good, with the panic as its cause.
+	PanicStop
+)
+
+// PanicError is the error of a run that panicked, see OnPanicPolicy.
+type PanicError struct {
+	// Value is the value the run panicked with.
+	Value any
+	// Stack is the stack trace of the goroutine that panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// OnPanicPolicy recovers the panics of the job's runs, which otherwise crash
+// the program, and decides what happens to the job next. A run that panics
+// fails with a *PanicError, without further attempts, and then the job keeps
+// its schedule, is paused or is stopped, as policy says. With PanicPause the
+// job resumes after cooloff, or when Resume is called if cooloff is zero.
+func (j *Job) OnPanicPolicy(policy PanicPolicy, cooloff time.Duration) *Job {
+	if j.err != nil {
+		return j
+	}
+	if policy < PanicContinue || policy > PanicStop || cooloff < 0 {
+		j.err = errors.New("bad panic policy")
+		return j
+	}
+	j.recovers = true
+	j.onPanic = policy
+	j.panicOff = cooloff
+	return j
+}
+
+// attempt calls fn, recovering its panic into a *PanicError if the job has a
+// panic policy.
+func (j *Job) attempt(ctx context.Context, fn func(context.Context) error) (err error) {
+	if !j.recovers {
+		return fn(ctx)
+	}
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
+
+func isPanic(err error) bool {
+	var p *PanicError
+	return errors.As(err, &p)
+}
+
+// panicked applies the job's panic policy after a run failed with err, if it
+// panicked.
+func (j *Job) panicked(err error) {
+	if !isPanic(err) {
+		return
+	}
+	switch j.onPanic {
+	case PanicPause:
+		j.Lock()
+		j.paused = true
+		j.pauses++
+		pauses := j.pauses
+		j.Unlock()
+		j.changed()
+		j.resumeIn(j.panicOff, pauses)
+	case PanicStop:
+		j.StopWithCause(err)
+	}
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnPanicPolicy(t *testing.T) {
+	boom := func() { panic("boom") }
+
+	job := Every(1).Hours().OnPanicPolicy(PanicContinue, 0).Retry(3, 0)
+	done := job.Done()
+	_, err := job.RunErr(func(context.Context) error {
+		boom()
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	result := <-done
+	var p *PanicError
+	assert.True(t, errors.As(result.Err, &p))
+	assert.Equal(t, "boom", p.Value)
+	assert.NotEmpty(t, p.Stack)
+	assert.Equal(t, "panic: boom", result.Err.Error())
+	assert.Equal(t, 1, result.Attempts)
+	assert.False(t, job.IsPaused())
+	assert.Nil(t, job.StopCause())
+
+	paused := Every(1).Hours().OnPanicPolicy(PanicPause, 50*time.Millisecond)
+	done = paused.Done()
+	_, err = paused.Run(boom)
+	assert.Nil(t, err)
+	defer paused.Stop()
+	<-done
+	assert.True(t, paused.IsPaused())
+	assert.Eventually(t, func() bool { return !paused.IsPaused() }, time.Second, 5*time.Millisecond)
+
+	stopped := Every(1).Hours().OnPanicPolicy(PanicStop, 0)
+	done = stopped.Done()
+	_, err = stopped.Run(boom)
+	assert.Nil(t, err)
+	result = <-done
+	assert.Equal(t, result.Err, stopped.StopCause())
+	assert.Eventually(t, func() bool { return stopped.Status() == Stopped }, time.Second, 5*time.Millisecond)
+}
+
+func TestBadPanicPolicy(t *testing.T) {
+	for _, job := range []*Job{
+		Every(1).Hours().OnPanicPolicy(PanicPolicy(7), 0),
+		Every(1).Hours().OnPanicPolicy(PanicPause, -time.Second),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
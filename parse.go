@@ -0,0 +1,189 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError describes a problem with a schedule spec. Pos is the byte offset
+// of the offending token within Spec.
+type ParseError struct {
+	Spec string
+	Pos  int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return e.Msg + " at position " + strconv.Itoa(e.Pos) + " in " + strconv.Quote(e.Spec)
+}
+
+type token struct {
+	text string
+	pos  int
+}
+
+func tokenize(spec string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range spec {
+		space := r == ' ' || r == '\t' || r == '\n' || r == '\r'
+		if space && start >= 0 {
+			tokens = append(tokens, token{spec[start:i], start})
+			start = -1
+		} else if !space && start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{spec[start:], start})
+	}
+	return tokens
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+var units = map[string]time.Duration{
+	"second": time.Second, "seconds": time.Second,
+	"minute": time.Minute, "minutes": time.Minute,
+	"hour": time.Hour, "hours": time.Hour,
+}
+
+// ParseSchedule parses a schedule written in the package's compact syntax:
+//
+//  every 2h                  every 90 seconds
+//  daily                     daily 08:30
+//  sun 20:00                 monday 8 in Europe/Madrid
+//
+// "every" takes a Go duration or a count and a unit (seconds, minutes or
+// hours); recurrent schedules run immediately and then every period. Daily and
+// weekday schedules take an optional time as accepted by At and an optional
+// "in <zone>" suffix. Keywords are case-insensitive.
+//
+// Errors are returned as *ParseError pointing at the offending token. Each call
+// returns a new schedule, which should be used by a single job.
+func ParseSchedule(spec string) (Schedule, error) {
+	p := &parser{spec: spec, tokens: tokenize(spec)}
+	s := p.parse()
+	if p.err != nil {
+		return nil, p.err
+	}
+	return s, nil
+}
+
+type parser struct {
+	spec   string
+	tokens []token
+	err    *ParseError
+}
+
+func (p *parser) fail(pos int, msg string) {
+	if p.err == nil {
+		p.err = &ParseError{Spec: p.spec, Pos: pos, Msg: msg}
+	}
+}
+
+func (p *parser) failAt(tok token, msg string) {
+	p.fail(tok.pos, msg+" "+strconv.Quote(tok.text))
+}
+
+func (p *parser) parse() Schedule {
+	if len(p.tokens) == 0 {
+		p.fail(0, "empty schedule")
+		return nil
+	}
+	head := p.tokens[0]
+	rest := p.tokens[1:]
+	switch keyword := strings.ToLower(head.text); keyword {
+	case "every":
+		return p.recurrent(head, rest)
+	case "daily":
+		d := p.daily(rest)
+		return &d
+	default:
+		day, ok := weekdays[keyword]
+		if !ok {
+			p.failAt(head, "unknown schedule")
+			return nil
+		}
+		return &weekly{day: day, d: p.daily(rest)}
+	}
+}
+
+func (p *parser) recurrent(head token, args []token) Schedule {
+	var period time.Duration
+	switch len(args) {
+	case 0:
+		p.fail(head.pos+len(head.text), "missing interval")
+		return nil
+	case 1:
+		d, err := time.ParseDuration(args[0].text)
+		if err != nil {
+			p.failAt(args[0], "bad interval")
+			return nil
+		}
+		period = d
+	case 2:
+		n, err := strconv.Atoi(args[0].text)
+		if err != nil {
+			p.failAt(args[0], "bad count")
+			return nil
+		}
+		unit, ok := units[strings.ToLower(args[1].text)]
+		if !ok {
+			p.failAt(args[1], "unknown unit")
+			return nil
+		}
+		period = time.Duration(n) * unit
+	default:
+		p.failAt(args[2], "unexpected")
+		return nil
+	}
+	if period <= 0 {
+		p.fail(args[0].pos, "interval must be positive")
+		return nil
+	}
+	return &recurrent{units: 1, period: period}
+}
+
+func (p *parser) daily(args []token) daily {
+	var d daily
+	if len(args) > 0 && strings.ToLower(args[0].text) != "in" {
+		hour, min, sec, nsec, err := parseTime(args[0].text)
+		if err != nil {
+			p.failAt(args[0], "bad time")
+			return d
+		}
+		d.setTime(hour, min, sec, nsec)
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return d
+	}
+	if strings.ToLower(args[0].text) != "in" {
+		p.failAt(args[0], "unexpected")
+		return d
+	}
+	if len(args) == 1 {
+		p.fail(args[0].pos+len(args[0].text), "missing time zone")
+		return d
+	}
+	loc, err := loadLocation(args[1].text)
+	if err != nil {
+		p.failAt(args[1], "unknown time zone")
+		return d
+	}
+	d.loc = loc
+	if len(args) > 2 {
+		p.failAt(args[2], "unexpected")
+	}
+	return d
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var parseFrom = time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local) // a Wednesday
+
+func TestParseSchedule(t *testing.T) {
+	madrid, _ := time.LoadLocation("Europe/Madrid")
+	cases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"every 2h", parseFrom.Add(2 * time.Hour)},
+		{"every 1h30m", parseFrom.Add(90 * time.Minute)},
+		{"every 90 seconds", parseFrom.Add(90 * time.Second)},
+		{"EVERY 1 Hour", parseFrom.Add(time.Hour)},
+		{"daily", time.Date(2015, 6, 4, 0, 0, 0, 0, time.Local)},
+		{"daily 08:30", time.Date(2015, 6, 4, 8, 30, 0, 0, time.Local)},
+		{"  daily   18:15:30 ", time.Date(2015, 6, 3, 18, 15, 30, 0, time.Local)},
+		{"sun 20:00", time.Date(2015, 6, 7, 20, 0, 0, 0, time.Local)},
+		{"Friday", time.Date(2015, 6, 5, 0, 0, 0, 0, time.Local)},
+		{"daily 08:30 in Europe/Madrid", time.Date(2015, 6, 4, 8, 30, 0, 0, madrid)},
+		{"mon in Europe/Madrid", time.Date(2015, 6, 8, 0, 0, 0, 0, madrid)},
+	}
+	for _, c := range cases {
+		s, err := ParseSchedule(c.spec)
+		if assert.Nil(t, err, c.spec) {
+			assert.True(t, c.expected.Equal(s.Next(parseFrom)), c.spec)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	cases := []struct {
+		spec string
+		pos  int
+		msg  string
+	}{
+		{"", 0, "empty schedule"},
+		{"hourly", 0, `unknown schedule "hourly"`},
+		{"every", 5, "missing interval"},
+		{"every 2x", 6, `bad interval "2x"`},
+		{"every two hours", 6, `bad count "two"`},
+		{"every 2 days", 8, `unknown unit "days"`},
+		{"every 0s", 6, "interval must be positive"},
+		{"every 2 hours now", 14, `unexpected "now"`},
+		{"daily 25:00", 6, `bad time "25:00"`},
+		{"sun 20:00 sharp", 10, `unexpected "sharp"`},
+		{"sun 20:00 in", 12, "missing time zone"},
+		{"sun in Mars/Olympus_Mons", 7, `unknown time zone "Mars/Olympus_Mons"`},
+	}
+	for _, c := range cases {
+		s, err := ParseSchedule(c.spec)
+		assert.Nil(t, s, c.spec)
+		if perr, ok := err.(*ParseError); assert.True(t, ok, c.spec) {
+			assert.Equal(t, c.pos, perr.Pos, c.spec)
+			assert.Equal(t, c.msg, perr.Msg, c.spec)
+		}
+	}
+}
+
+func TestParseErrorMessage(t *testing.T) {
+	_, err := ParseSchedule("daily 8:75")
+	assert.EqualError(t, err, `bad time "8:75" at position 6 in "daily 8:75"`)
+}
+
+func TestParseScheduleRun(t *testing.T) {
+	s, err := ParseSchedule("every 3h")
+	assert.Nil(t, err)
+	job := On(s)
+	testEveryX(t, job, 3*time.Hour, true)
+}
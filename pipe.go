@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pipe works like j.RunErr for functions that produce a result, and passes the
+// result of each successful run to consume, e.g. to send it on a channel:
+//
+//	prices := make(chan Price, 1)
+//	scheduler.Pipe(scheduler.Every(1).Minutes(), fetchPrice, func(p Price) { prices <- p })
+//
+// Failed runs produce no result and are handled as with RunErr.
+func Pipe[T any](j *Job, f func(context.Context) (T, error), consume func(T)) (*Job, error) {
+	return j.RunErr(func(ctx context.Context) error {
+		v, err := f(ctx)
+		if err != nil {
+			return err
+		}
+		consume(v)
+		j.publish(v)
+		return nil
+	})
+}
+
+// DropPolicy decides what happens to a result that does not fit in the buffer
+// of a subscription, see Subscribe.
+type DropPolicy int
+
+const (
+	// DropNewest discards the result that does not fit.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest result in the buffer to make room.
+	DropOldest
+	// DropNone waits for the subscriber to make room, holding up the run,
+	// until the job is stopped.
+	DropNone
+)
+
+// subscriber receives the results of a job, see Subscribe. Its mutex orders
+// sends and the closing of its channel.
+type subscriber struct {
+	send   func(ctx context.Context, v any)
+	close  func()
+	closed bool
+	sync.Mutex
+}
+
+// Subscribe returns a channel that receives the result of each successful run
+// of j, which must be run with Pipe or as a JobOf, e.g. to feed a pipeline:
+//
+//	prices := scheduler.Subscribe[Price](job, 16, scheduler.DropOldest)
+//	scheduler.Pipe(job, fetchPrice, func(Price) {})
+//
+// The channel holds up to buffer results, and policy decides what happens to
+// those that do not fit. Results not of type T are not delivered. The channel
+// is closed once the job stops, or if it fails to start; results of runs still
+// in progress then are dropped.
+func Subscribe[T any](j *Job, buffer int, policy DropPolicy) <-chan T {
+	ch := make(chan T, max(buffer, 0))
+	s := &subscriber{close: func() { close(ch) }}
+	s.send = func(ctx context.Context, v any) {
+		t, ok := v.(T)
+		if !ok {
+			return
+		}
+		switch policy {
+		case DropOldest:
+			for {
+				select {
+				case ch <- t:
+					return
+				default:
+				}
+				select {
+				case <-ch:
+				default:
+					return
+				}
+			}
+		case DropNone:
+			select {
+			case ch <- t:
+			case <-ctx.Done():
+			}
+		default:
+			select {
+			case ch <- t:
+			default:
+			}
+		}
+	}
+
+	j.Lock()
+	defer j.Unlock()
+	if j.exited {
+		close(ch)
+		return ch
+	}
+	j.subs = append(j.subs, s)
+	return ch
+}
+
+// publish sends the result of a run to the job's subscribers.
+func (j *Job) publish(v any) {
+	j.RLock()
+	subs, ctx := j.subs, j.ctx
+	j.RUnlock()
+
+	for _, s := range subs {
+		s.Lock()
+		if !s.closed {
+			s.send(ctx, v)
+		}
+		s.Unlock()
+	}
+}
+
+// unsubscribe closes the channels of the job's subscribers.
+func (j *Job) unsubscribe() {
+	j.Lock()
+	subs := j.subs
+	j.subs = nil
+	j.Unlock()
+
+	for _, s := range subs {
+		s.Lock()
+		s.closed = true
+		s.close()
+		s.Unlock()
+	}
+}
+
+// Tick is the input of a run of a JobOf: when the run started and the job's
+// data.
+type Tick[T any] struct {
+	Time time.Time
+	Data T
+}
+
+// JobOf is a job whose runs take data of type T and produce results of type
+// R, without conversions from interface{}:
+//
+//	job, err := scheduler.NewJobOf[Feed, []Item](scheduler.Every(5).Minutes(), feed).
+//		Results(func(items []Item) { store(items) }).
+//		Run(func(ctx context.Context, tick scheduler.Tick[Feed]) ([]Item, error) {
+//			return tick.Data.Fetch(ctx, tick.Time)
+//		})
+type JobOf[T, R any] struct {
+	j       *Job
+	data    T
+	results func(R)
+}
+
+// NewJobOf returns a typed job with the schedule and options of j, whose runs
+// get data.
+func NewJobOf[T, R any](j *Job, data T) *JobOf[T, R] {
+	return &JobOf[T, R]{j: j, data: data}
+}
+
+// Results sets the function that gets the result of each successful run. The
+// results are discarded without one.
+func (t *JobOf[T, R]) Results(f func(R)) *JobOf[T, R] {
+	t.results = f
+	return t
+}
+
+// Run starts the job as RunErr does and returns the underlying job, to stop it
+// or inspect it.
+func (t *JobOf[T, R]) Run(f func(context.Context, Tick[T]) (R, error)) (*Job, error) {
+	j := t.j
+	return Pipe(j, func(ctx context.Context) (R, error) {
+		j.RLock()
+		started := j.startedAt
+		j.RUnlock()
+		return f(ctx, Tick[T]{Time: started, Data: t.data})
+	}, func(r R) {
+		if t.results != nil {
+			t.results(r)
+		}
+	})
+}
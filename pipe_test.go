@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipe(t *testing.T) {
+	results := make(chan int, 1)
+	n := 0
+	job, err := Pipe(Every(1).Hours().Retry(2, 0), func(context.Context) (int, error) {
+		n++
+		if n == 1 {
+			return 0, errors.New("boom")
+		}
+		return n, nil
+	}, func(v int) { results <- v })
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, 2, <-results)
+	assert.Equal(t, Stats{Runs: 1}, counters(job.Stats()))
+
+	_, err = Pipe(Every(0).Hours(), func(context.Context) (int, error) { return 0, nil }, func(int) {})
+	assert.NotNil(t, err)
+}
+
+func TestJobOf(t *testing.T) {
+	type feed struct{ url string }
+	results := make(chan string, 1)
+	job, err := NewJobOf[feed, string](Every(1).Hours(), feed{"https://example.com"}).
+		Results(func(s string) { results <- s }).
+		Run(func(ctx context.Context, tick Tick[feed]) (string, error) {
+			assert.WithinDuration(t, time.Now(), tick.Time, time.Second)
+			return tick.Data.url, nil
+		})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, "https://example.com", <-results)
+
+	done := make(chan bool, 1)
+	job, err = NewJobOf[int, int](Every(1).Hours(), 1).Run(func(ctx context.Context, tick Tick[int]) (int, error) {
+		done <- true
+		return tick.Data, nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.True(t, <-done)
+}
+
+func TestSubscribe(t *testing.T) {
+	job := Every(1).Hours().NotImmediately()
+	newest := Subscribe[int](job, 1, DropOldest)
+	oldest := Subscribe[int](job, 1, DropNewest)
+	wrong := Subscribe[string](job, 1, DropNewest)
+	done := job.Done()
+	n := 0
+	_, err := Pipe(job, func(context.Context) (int, error) {
+		n++
+		return n, nil
+	}, func(int) {})
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		job.SkipWait <- true
+		<-done
+	}
+	assert.Equal(t, 2, <-newest)
+	assert.Equal(t, 1, <-oldest)
+	job.Stop()
+	for range newest {
+	}
+	_, open := <-wrong
+	assert.False(t, open, "closed once the job stops")
+
+	_, open = <-Subscribe[int](job, 1, DropNewest)
+	assert.False(t, open)
+}
+
+func TestSubscribeBlocking(t *testing.T) {
+	job := Every(1).Hours().NotImmediately()
+	results := Subscribe[int](job, 0, DropNone)
+	done := job.Done()
+	_, err := Pipe(job, func(context.Context) (int, error) { return 1, nil }, func(int) {})
+	assert.Nil(t, err)
+	job.SkipWait <- true
+	assert.Equal(t, 1, <-results)
+	<-done
+
+	job.SkipWait <- true
+	for !job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	job.Stop()
+	for range results {
+	}
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+
+	bad := Every(0).Hours()
+	results = Subscribe[int](bad, 1, DropNewest)
+	_, err = Pipe(bad, func(context.Context) (int, error) { return 1, nil }, func(int) {})
+	assert.NotNil(t, err)
+	_, open := <-results
+	assert.False(t, open, "closed if the job fails to start")
+}
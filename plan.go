@@ -0,0 +1,385 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// Plan builds a schedule like the methods of Job, but as an immutable value:
+// each method returns a new Plan and leaves the receiver untouched, so plans
+// can be shared and branched freely, and errors are checked at each step
+// rather than when the job is run. The zero Plan is empty.
+//
+//	weekday := scheduler.Plan{}.Every()
+//	morning := weekday.Monday().At("08:00")
+//	evening := weekday.Monday().At("20:00")
+//	if err := evening.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+//	scheduler.NewJob(morning, job)
+//
+// A Plan is a Schedule, and On, NewJob and Reschedule take it as they would
+// the job it describes.
+type Plan struct {
+	steps []func(*Job) *Job
+	err   error
+	// built is the job the steps make, checked as by job. It is only read,
+	// so Next and Description need not replay the steps on every call.
+	built *Job
+}
+
+// errIncompletePlan is the error of plans that do not describe a schedule yet,
+// e.g. Plan{}.Every().
+var errIncompletePlan = errors.New("incomplete plan")
+
+// then returns a plan with step added, unless p has an error already.
+func (p Plan) then(step func(*Job) *Job) Plan {
+	if p.err != nil {
+		return p
+	}
+	steps := make([]func(*Job) *Job, len(p.steps), len(p.steps)+1)
+	copy(steps, p.steps)
+	q := Plan{steps: append(steps, step)}
+	q.built = q.replay()
+	q.err = q.built.err
+	check(q.built)
+	return q
+}
+
+// replay applies the steps of the plan to a new job.
+func (p Plan) replay() *Job {
+	j := &Job{}
+	for _, step := range p.steps {
+		j = step(j)
+	}
+	return j
+}
+
+// job replays the plan and checks that the result is a usable schedule.
+func (p Plan) job() *Job {
+	return check(p.replay())
+}
+
+// resolved returns the job the plan describes, to be read but not run or
+// changed.
+func (p Plan) resolved() *Job {
+	if p.built == nil {
+		return p.job()
+	}
+	return p.built
+}
+
+// check sets the error of a replayed job if it is not a usable schedule.
+func check(j *Job) *Job {
+	switch {
+	case j.err != nil:
+	case j.schedule == nil:
+		j.err = errIncompletePlan
+	default:
+		// Some mistakes, such as a day missing from some months, only show
+		// when the schedule is used.
+		if _, err := j.schedule.next(time.Now()); err != nil && err != errNoNextRun {
+			j.err = err
+		}
+	}
+	return j
+}
+
+// Err returns the first error in the plan, or an error if it does not describe
+// a complete schedule yet.
+func (p Plan) Err() error {
+	if p.err != nil {
+		return p.err
+	}
+	return p.resolved().err
+}
+
+// Next implements Schedule. It returns the zero time if the plan has an error.
+func (p Plan) Next(after time.Time) time.Time {
+	return p.resolved().Next(after)
+}
+
+// Description describes the planned schedule, as Job.Description does.
+func (p Plan) Description() string {
+	return p.resolved().Description()
+}
+
+// Every works like the package-level Every.
+func (p Plan) Every(times ...int) Plan {
+	return p.then(func(*Job) *Job { return Every(times...) })
+}
+
+// EveryDuration works like the package-level EveryDuration.
+func (p Plan) EveryDuration(d time.Duration) Plan {
+	return p.then(func(*Job) *Job { return EveryDuration(d) })
+}
+
+// Backoff works like the package-level Backoff.
+func (p Plan) Backoff(initial, max time.Duration, factor float64) Plan {
+	return p.then(func(*Job) *Job { return Backoff(initial, max, factor) })
+}
+
+// After works like the package-level After.
+func (p Plan) After(d time.Duration) Plan {
+	return p.then(func(*Job) *Job { return After(d) })
+}
+
+// Once works like the package-level Once.
+func (p Plan) Once(t time.Time) Plan {
+	return p.then(func(*Job) *Job { return Once(t) })
+}
+
+// Seconds works like Job.Seconds.
+func (p Plan) Seconds() Plan {
+	return p.then((*Job).Seconds)
+}
+
+// Minutes works like Job.Minutes.
+func (p Plan) Minutes() Plan {
+	return p.then((*Job).Minutes)
+}
+
+// Hours works like Job.Hours.
+func (p Plan) Hours() Plan {
+	return p.then((*Job).Hours)
+}
+
+// Day works like Job.Day.
+func (p Plan) Day() Plan {
+	return p.then((*Job).Day)
+}
+
+// Monday works like Job.Monday.
+func (p Plan) Monday() Plan {
+	return p.then((*Job).Monday)
+}
+
+// Tuesday works like Job.Tuesday.
+func (p Plan) Tuesday() Plan {
+	return p.then((*Job).Tuesday)
+}
+
+// Wednesday works like Job.Wednesday.
+func (p Plan) Wednesday() Plan {
+	return p.then((*Job).Wednesday)
+}
+
+// Thursday works like Job.Thursday.
+func (p Plan) Thursday() Plan {
+	return p.then((*Job).Thursday)
+}
+
+// Friday works like Job.Friday.
+func (p Plan) Friday() Plan {
+	return p.then((*Job).Friday)
+}
+
+// Saturday works like Job.Saturday.
+func (p Plan) Saturday() Plan {
+	return p.then((*Job).Saturday)
+}
+
+// Sunday works like Job.Sunday.
+func (p Plan) Sunday() Plan {
+	return p.then((*Job).Sunday)
+}
+
+// Weeks works like Job.Weeks.
+func (p Plan) Weeks() Plan {
+	return p.then((*Job).Weeks)
+}
+
+// Week works like Job.Week.
+func (p Plan) Week() Plan {
+	return p.then((*Job).Week)
+}
+
+// Starting works like Job.Starting.
+func (p Plan) Starting(day time.Weekday) Plan {
+	return p.then(func(j *Job) *Job { return j.Starting(day) })
+}
+
+// OnWeekday works like Job.OnWeekday.
+func (p Plan) OnWeekday(day time.Weekday) Plan {
+	return p.then(func(j *Job) *Job { return j.OnWeekday(day) })
+}
+
+// FromWeek works like Job.FromWeek.
+func (p Plan) FromWeek(t time.Time) Plan {
+	return p.then(func(j *Job) *Job { return j.FromWeek(t) })
+}
+
+// Month works like Job.Month.
+func (p Plan) Month() Plan {
+	return p.then((*Job).Month)
+}
+
+// Months works like Job.Months.
+func (p Plan) Months() Plan {
+	return p.then((*Job).Months)
+}
+
+// FromMonth works like Job.FromMonth.
+func (p Plan) FromMonth(month time.Month) Plan {
+	return p.then(func(j *Job) *Job { return j.FromMonth(month) })
+}
+
+// Quarter works like Job.Quarter.
+func (p Plan) Quarter() Plan {
+	return p.then((*Job).Quarter)
+}
+
+// January works like Job.January.
+func (p Plan) January() Plan {
+	return p.then((*Job).January)
+}
+
+// February works like Job.February.
+func (p Plan) February() Plan {
+	return p.then((*Job).February)
+}
+
+// March works like Job.March.
+func (p Plan) March() Plan {
+	return p.then((*Job).March)
+}
+
+// April works like Job.April.
+func (p Plan) April() Plan {
+	return p.then((*Job).April)
+}
+
+// May works like Job.May.
+func (p Plan) May() Plan {
+	return p.then((*Job).May)
+}
+
+// June works like Job.June.
+func (p Plan) June() Plan {
+	return p.then((*Job).June)
+}
+
+// July works like Job.July.
+func (p Plan) July() Plan {
+	return p.then((*Job).July)
+}
+
+// August works like Job.August.
+func (p Plan) August() Plan {
+	return p.then((*Job).August)
+}
+
+// September works like Job.September.
+func (p Plan) September() Plan {
+	return p.then((*Job).September)
+}
+
+// October works like Job.October.
+func (p Plan) October() Plan {
+	return p.then((*Job).October)
+}
+
+// November works like Job.November.
+func (p Plan) November() Plan {
+	return p.then((*Job).November)
+}
+
+// December works like Job.December.
+func (p Plan) December() Plan {
+	return p.then((*Job).December)
+}
+
+// OnDay works like Job.OnDay.
+func (p Plan) OnDay(day int) Plan {
+	return p.then(func(j *Job) *Job { return j.OnDay(day) })
+}
+
+// FirstDay works like Job.FirstDay.
+func (p Plan) FirstDay() Plan {
+	return p.then((*Job).FirstDay)
+}
+
+// LastDay works like Job.LastDay.
+func (p Plan) LastDay() Plan {
+	return p.then((*Job).LastDay)
+}
+
+// OnMissingDay works like Job.OnMissingDay.
+func (p Plan) OnMissingDay(policy DayPolicy) Plan {
+	return p.then(func(j *Job) *Job { return j.OnMissingDay(policy) })
+}
+
+// OnPast works like Job.OnPast.
+func (p Plan) OnPast(policy PastPolicy) Plan {
+	return p.then(func(j *Job) *Job { return j.OnPast(policy) })
+}
+
+// On works like Job.On.
+func (p Plan) On(week Week, day time.Weekday) Plan {
+	return p.then(func(j *Job) *Job { return j.On(week, day) })
+}
+
+// At works like Job.At.
+func (p Plan) At(hourTime string) Plan {
+	return p.then(func(j *Job) *Job { return j.At(hourTime) })
+}
+
+// AtTime works like Job.AtTime.
+func (p Plan) AtTime(hour, min, sec int) Plan {
+	return p.then(func(j *Job) *Job { return j.AtTime(hour, min, sec) })
+}
+
+// Timezone works like Job.Timezone.
+func (p Plan) Timezone(name string) Plan {
+	return p.then(func(j *Job) *Job { return j.Timezone(name) })
+}
+
+// In works like Job.In.
+func (p Plan) In(name string) Plan {
+	return p.then(func(j *Job) *Job { return j.In(name) })
+}
+
+// NotImmediately works like Job.NotImmediately.
+func (p Plan) NotImmediately() Plan {
+	return p.then((*Job).NotImmediately)
+}
+
+// NoDrift works like Job.NoDrift.
+func (p Plan) NoDrift() Plan {
+	return p.then((*Job).NoDrift)
+}
+
+// AdaptiveInterval works like Job.AdaptiveInterval.
+func (p Plan) AdaptiveInterval(f func() time.Duration) Plan {
+	return p.then(func(j *Job) *Job { return j.AdaptiveInterval(f) })
+}
+
+// OnlyOn works like Job.OnlyOn.
+func (p Plan) OnlyOn(days ...time.Weekday) Plan {
+	return p.then(func(j *Job) *Job { return j.OnlyOn(days...) })
+}
+
+// SpreadBy works like Job.SpreadBy.
+func (p Plan) SpreadBy(key string) Plan {
+	return p.then(func(j *Job) *Job { return j.SpreadBy(key) })
+}
+
+// AtSecond works like Job.AtSecond.
+func (p Plan) AtSecond(sec int) Plan {
+	return p.then(func(j *Job) *Job { return j.AtSecond(sec) })
+}
+
+// AtMinute works like Job.AtMinute.
+func (p Plan) AtMinute(min int) Plan {
+	return p.then(func(j *Job) *Job { return j.AtMinute(min) })
+}
+
+// SkipHolidays works like Job.SkipHolidays.
+func (p Plan) SkipHolidays(h HolidayProvider) Plan {
+	return p.then(func(j *Job) *Job { return j.SkipHolidays(h) })
+}
+
+// ShiftHolidays works like Job.ShiftHolidays.
+func (p Plan) ShiftHolidays(h HolidayProvider) Plan {
+	return p.then(func(j *Job) *Job { return j.ShiftHolidays(h) })
+}
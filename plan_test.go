@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan(t *testing.T) {
+	monday := Plan{}.Every().Monday()
+	morning := monday.At("08:00")
+	evening := monday.At("20:00")
+	assert.Nil(t, morning.Err())
+	assert.Equal(t, "every Monday at 08:00:00", morning.Description())
+	assert.Equal(t, "every Monday at 20:00:00", evening.Description())
+	assert.Equal(t, "every Monday at 00:00:00", monday.Description())
+
+	from := time.Date(2015, 6, 2, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2015, 6, 8, 8, 0, 0, 0, time.Local), morning.Next(from))
+
+	c := make(chan bool, 1)
+	job, err := NewJob(Plan{}.Every(1).Hours(), func() { c <- true })
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.True(t, <-c)
+	assert.Nil(t, job.Reschedule(evening))
+	assert.Equal(t, "every Monday at 20:00:00", On(evening).Description())
+}
+
+func TestPlanErrors(t *testing.T) {
+	assert.Equal(t, errIncompletePlan, Plan{}.Err())
+	assert.Equal(t, errIncompletePlan, Plan{}.Every().Err())
+
+	bad := Plan{}.Every(1).Hours().At("08:00")
+	assert.EqualError(t, bad.Err(), "bad function chaining")
+	// Further steps keep the first error.
+	assert.Equal(t, bad.Err(), bad.Every().Day().Err())
+	assert.True(t, bad.Next(time.Now()).IsZero())
+	_, err := NewJob(bad, test)
+	assert.NotNil(t, err)
+
+	assert.NotNil(t, Plan{}.Every().Month().OnDay(31).Err())
+	assert.Nil(t, Plan{}.Every().Month().OnDay(31).OnMissingDay(DayClamp).Err())
+}
+
+func TestPlanReplaysOnce(t *testing.T) {
+	replays := 0
+	p := Plan{}.then(func(*Job) *Job {
+		replays++
+		return Every().Day().At("08:00").In("Europe/Madrid")
+	})
+	from := time.Date(2015, 6, 2, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Date(2015, 6, 3, 6, 0, 0, 0, time.UTC), p.Next(from).UTC())
+		assert.Nil(t, p.Err())
+	}
+	assert.Equal(t, 1, replays)
+}
@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// specJob is a job of a scheduler created from a spec, see Reload.
+type specJob struct {
+	spec JobSpec
+	job  *Job
+}
+
+// manage records that j was created from spec, so Reload updates it.
+func (s *Scheduler) manage(spec JobSpec, j *Job) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.specs == nil {
+		s.specs = make(map[string]specJob)
+	}
+	s.specs[spec.Name] = specJob{spec: spec, job: j}
+}
+
+// managed returns the jobs of the scheduler created from specs that are still
+// registered, by name.
+func (s *Scheduler) managed() map[string]specJob {
+	s.Lock()
+	defer s.Unlock()
+
+	managed := make(map[string]specJob, len(s.specs))
+	for name, m := range s.specs {
+		if s.listed[m.job] {
+			managed[name] = m
+		}
+	}
+	return managed
+}
+
+// Reload makes the jobs created from specs, by AddAll or an earlier Reload,
+// match specs, e.g. after the configuration they were loaded from changed:
+// jobs whose spec is gone are stopped and removed, new specs are added as by
+// AddAll, and the jobs whose spec changed are updated in place. A job given a
+// new schedule goes on from its next run under that schedule, as with
+// Reschedule but without an immediate run, and a run in progress finishes
+// with the function it started with. Jobs not created from specs are left
+// alone.
+//
+// Specs are checked as by AddAll first, and nothing changes if any is bad.
+func (s *Scheduler) Reload(specs []JobSpec) error {
+	s.reloading.Lock()
+	defer s.reloading.Unlock()
+
+	managed := s.managed()
+	free := make([]*Job, 0, len(managed))
+	for _, m := range managed {
+		free = append(free, m.job)
+	}
+	schedules, err := s.checkSpecs(specs, free)
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		kept[spec.Name] = true
+	}
+	var removed []*Job
+	for name, m := range managed {
+		if !kept[name] {
+			m.job.Stop()
+			removed = append(removed, m.job)
+		}
+	}
+	s.unregister(removed...)
+	for _, j := range removed {
+		j.audit("", AuditRemove, "reload")
+	}
+
+	var errs []error
+	updated := make(map[string]specJob, len(specs))
+	for i, spec := range specs {
+		m, ok := managed[spec.Name]
+		if !ok {
+			j, err := s.startSpec(spec, schedules[i])
+			if err != nil {
+				errs = append(errs, &SpecError{Index: i, Name: spec.Name, Err: err})
+				continue
+			}
+			for _, tag := range spec.Tags {
+				s.Group(tag).Add(j)
+			}
+			updated[spec.Name] = specJob{spec: spec, job: j}
+			continue
+		}
+		if err := s.update(m, spec, schedules[i]); err != nil {
+			errs = append(errs, &SpecError{Index: i, Name: spec.Name, Err: err})
+			spec = m.spec
+		}
+		updated[spec.Name] = specJob{spec: spec, job: m.job}
+	}
+
+	s.Lock()
+	s.specs = updated
+	s.Unlock()
+	return errors.Join(errs...)
+}
+
+// update changes the job of m to match spec, whose schedule has been parsed.
+func (s *Scheduler) update(m specJob, spec JobSpec, schedule scheduled) error {
+	j := m.job
+	if spec.Schedule != m.spec.Schedule {
+		next := &Job{schedule: schedule}
+		if _, ok := schedule.(*recurrent); ok {
+			next.NotImmediately()
+		}
+		if err := j.Reschedule(next); err != nil {
+			return err
+		}
+	}
+	if spec.Func != m.spec.Func {
+		f := s.funcOf(spec.Func)
+		j.Lock()
+		j.fn = f
+		j.Unlock()
+	}
+	for _, tag := range m.spec.Tags {
+		if !containsTag(spec.Tags, tag) {
+			s.Group(tag).remove(j)
+		}
+	}
+	for _, tag := range spec.Tags {
+		s.Group(tag).Add(j)
+	}
+	return nil
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSpecs reads job specs from the JSON file at path, which holds an array
+// of objects with the fields of JobSpec:
+//
+//	[{"name": "cleanup", "schedule": "daily 03:00", "func": "cleanup", "tags": ["maintenance"]}]
+func LoadSpecs(path string) ([]JobSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSpecs(data)
+}
+
+func decodeSpecs(data []byte) ([]JobSpec, error) {
+	var specs []JobSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// WatchSpecs loads the job specs in the file at path, see LoadSpecs, and
+// reloads them, see Reload, whenever the file changes, checking it every
+// interval until ctx is done. It returns the error of the first load, if any.
+// Later errors, such as a bad spec in an edited file, leave the jobs as they
+// were and are logged with the scheduler's default logger, see
+// WithDefaultLogger; the file is loaded again once it changes once more.
+func (s *Scheduler) WatchSpecs(ctx context.Context, path string, interval time.Duration) error {
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := s.reloadData(last); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			s.logError("cannot read job specs", err)
+			continue
+		}
+		if bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+		if err := s.reloadData(data); err != nil {
+			s.logError("cannot reload job specs", err)
+		}
+	}
+}
+
+func (s *Scheduler) reloadData(data []byte) error {
+	specs, err := decodeSpecs(data)
+	if err != nil {
+		return err
+	}
+	return s.Reload(specs)
+}
+
+// logError logs an error of the scheduler with its default logger, if it has
+// one.
+func (s *Scheduler) logError(msg string, err error) {
+	s.Lock()
+	logger := s.defaults.logger
+	s.Unlock()
+	if logger != nil {
+		logger.Error(msg, "err", err)
+	}
+}
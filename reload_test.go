@@ -0,0 +1,96 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	sink := &memoryAudit{}
+	s := NewScheduler().WithAuditSink(sink)
+	ran := make(chan string, 10)
+	for _, key := range []string{"sync", "report", "audit"} {
+		key := key
+		s.RegisterFunc(key, func(context.Context) error {
+			ran <- key
+			return nil
+		})
+	}
+	other, err := s.Every(1).Hours().NotImmediately().Named("other").Run(test)
+	assert.Nil(t, err)
+	defer other.Stop()
+	jobs, err := s.AddAll([]JobSpec{
+		{Name: "sync", Schedule: "every 1h", Tags: []string{"billing"}, Func: "sync"},
+		{Name: "report", Schedule: "daily 08:00", Func: "report"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "sync", <-ran)
+	sync, report := jobs[0], jobs[1]
+
+	err = s.Reload([]JobSpec{
+		{Name: "sync", Schedule: "every 2h", Tags: []string{"ops"}, Func: "report"},
+		{Name: "audit", Schedule: "daily 09:00", Func: "audit"},
+	})
+	assert.Nil(t, err)
+	defer s.Group("ops").StopAll()
+	defer s.Job("audit").Stop()
+	assert.Equal(t, sync, s.Job("sync"))
+	assert.Nil(t, s.Job("report"))
+	for report.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, other, s.Job("other"))
+	assert.Equal(t, "every day at 09:00:00", s.Job("audit").Description())
+	for sync.Description() != "every 2h0m0s" {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Empty(t, s.Group("billing").snapshot())
+	assert.Equal(t, []*Job{sync}, s.Group("ops").snapshot())
+	assert.Contains(t, sink.actions(), " remove report reload")
+	assert.Len(t, ran, 0)
+	sync.Trigger()
+	assert.Equal(t, "report", <-ran)
+
+	err = s.Reload([]JobSpec{
+		{Name: "sync", Schedule: "whenever", Func: "sync"},
+		{Name: "other", Schedule: "every 1h", Func: "sync"},
+	})
+	assert.Contains(t, err.Error(), `job spec 0 ("sync")`)
+	assert.Contains(t, err.Error(), `job spec 1 ("other"): duplicate job name`)
+	assert.NotNil(t, s.Job("audit"))
+	assert.Equal(t, "every 2h0m0s", sync.Description())
+}
+
+func TestWatchSpecs(t *testing.T) {
+	s := NewScheduler()
+	s.RegisterFunc("sync", func(context.Context) error { return nil })
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	write := func(specs string) {
+		assert.Nil(t, os.WriteFile(path, []byte(specs), 0o644))
+	}
+	assert.NotNil(t, s.WatchSpecs(context.Background(), path, time.Millisecond))
+
+	write(`[{"name": "a", "schedule": "daily 08:00", "func": "sync"}]`)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.WatchSpecs(ctx, path, time.Millisecond) }()
+	for s.Job("a") == nil {
+		time.Sleep(time.Millisecond)
+	}
+
+	write(`[{"name": "b", "schedule": "daily 08:00", "func": "sync"}]`)
+	for s.Job("b") == nil || s.Job("a") != nil {
+		time.Sleep(time.Millisecond)
+	}
+	write(`[{"name": "c", "schedule": "whenever", "func": "sync"}]`)
+	time.Sleep(20 * time.Millisecond)
+	assert.NotNil(t, s.Job("b"))
+	cancel()
+	assert.Nil(t, <-done)
+	s.Job("b").Stop()
+}
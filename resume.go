@@ -0,0 +1,137 @@
+package scheduler
+
+import "time"
+
+// errInterrupted is the error recorded in the journal for runs that never
+// finished, see Resume.
+const errInterrupted = "run interrupted"
+
+// OnCrash sets a function to call, from Resume, with the journal entry of
+// each run of the job that started but never finished because the process
+// crashed, e.g. to clean up after it.
+func (j *Job) OnCrash(f func(JournalEntry)) *Job {
+	j.onCrash = f
+	return j
+}
+
+// RerunCrashed makes Resume run the job again for each of its runs that
+// crashed, one after another and with the same RunKey, so each rerun can tell
+// which of its side effects already happened.
+func (j *Job) RerunCrashed() *Job {
+	j.rerun = true
+	return j
+}
+
+// Unfinished returns the entries of the runs in entries that started but
+// never finished nor were skipped, in the order they started.
+func Unfinished(entries []JournalEntry) []JournalEntry {
+	type run struct{ job, key string }
+	open := make(map[run]bool)
+	for _, e := range entries {
+		r := run{e.Job + "\x00" + e.ID, e.Run}
+		if e.Event == JournalStart {
+			open[r] = true
+		} else {
+			delete(open, r)
+		}
+	}
+	var runs []JournalEntry
+	for _, e := range entries {
+		r := run{e.Job + "\x00" + e.ID, e.Run}
+		if e.Event == JournalStart && open[r] {
+			runs = append(runs, e)
+			delete(open, r)
+		}
+	}
+	return runs
+}
+
+// Resume finds the runs in entries, e.g. those of FileJournal.Entries read at
+// startup, that started but never finished because the process crashed during
+// them, and hands each one to the job of the scheduler it belongs to, matched
+// by ID or else by name: the job's OnCrash function is called with the entry,
+// and a job set to RerunCrashed runs again. Resume must be called once the
+// jobs are running. Each crashed run handed to a job is then recorded as
+// finished in the scheduler's journal, if it has one, so it is only resumed
+// once. The crashed runs of RerunCrashed jobs that cannot run again then,
+// because they are paused, on standby or already running, are left alone for
+// a later Resume. Resume returns the number of crashed runs it handed to jobs.
+func (s *Scheduler) Resume(entries []JournalEntry) int {
+	resumed := 0
+	var rerun []*Job
+	crashes := make(map[*Job][]JournalEntry)
+	for _, e := range Unfinished(entries) {
+		j := s.crashed(e)
+		if j == nil {
+			continue
+		}
+		j.RLock()
+		again := j.rerun
+		j.RUnlock()
+		if !again {
+			s.handCrashed(j, e)
+			resumed++
+			continue
+		}
+		if crashes[j] == nil {
+			rerun = append(rerun, j)
+		}
+		crashes[j] = append(crashes[j], e)
+	}
+	for _, j := range rerun {
+		resumed += s.rerunCrashed(j, crashes[j])
+	}
+	return resumed
+}
+
+// handCrashed calls the job's OnCrash function, if any, with the entry of a
+// crashed run and records the run as finished in the journal.
+func (s *Scheduler) handCrashed(j *Job, e JournalEntry) {
+	j.RLock()
+	onCrash := j.onCrash
+	j.RUnlock()
+	if onCrash != nil {
+		onCrash(e)
+	}
+	s.Lock()
+	jr := s.journal
+	s.Unlock()
+	if jr != nil {
+		end := JournalEntry{Time: j.now(), Job: e.Job, ID: e.ID, Run: e.Run, Event: JournalEnd, Reason: e.Reason, Error: errInterrupted}
+		if err := jr.Record(end); err != nil {
+			s.logError("journal failed", err)
+		}
+	}
+}
+
+// rerunCrashed hands the job its crashed runs and runs it again with the key
+// of each one, one after another. It returns how many it handed: all of them,
+// or none if the job cannot start a run now.
+func (s *Scheduler) rerunCrashed(j *Job, crashes []JournalEntry) int {
+	if !j.claimRerun() {
+		return 0
+	}
+	keys := make([]string, len(crashes))
+	for i, e := range crashes {
+		s.handCrashed(j, e)
+		keys[i] = e.Run
+	}
+	j.Lock()
+	j.reruns = append(j.reruns, keys[1:]...)
+	j.Unlock()
+	j.start(nil, time.Time{}, keys[0])
+	return len(keys)
+}
+
+// crashed returns the job of the scheduler that a journal entry belongs to.
+func (s *Scheduler) crashed(e JournalEntry) *Job {
+	for _, j := range s.snapshot() {
+		j.RLock()
+		id, name := j.id, j.name
+		j.RUnlock()
+		if id != "" && id == e.ID || (id == "" || e.ID == "") && name != "" && name == e.Job {
+			return j
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.log")
+	jr, err := OpenJournal(path, 1<<20, 1)
+	assert.Nil(t, err)
+	for _, e := range []JournalEntry{
+		{Job: "report", Run: "report@1", Event: JournalStart, Reason: ReasonSchedule},
+		{Job: "report", Run: "report@1", Event: JournalEnd, Reason: ReasonSchedule},
+		{Job: "report", Run: "report@2", Event: JournalStart, Reason: ReasonSchedule},
+		{Job: "gone", Run: "gone@2", Event: JournalStart, Reason: ReasonSchedule},
+	} {
+		assert.Nil(t, jr.Record(e))
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	assert.Nil(t, err)
+	f.WriteString(`{"job":"report","run":"rep`)
+	f.Close()
+	assert.Nil(t, jr.Close())
+
+	jr, err = OpenJournal(path, 1<<20, 1)
+	assert.Nil(t, err)
+	defer jr.Close()
+	entries, err := jr.Entries()
+	assert.Nil(t, err)
+	assert.Len(t, entries, 4)
+	assert.Len(t, Unfinished(entries), 2)
+
+	s := NewScheduler().WithJournal(jr)
+	crashed := make(chan JournalEntry, 1)
+	keys := make(chan string, 1)
+	job, err := s.Every(1).Hours().NotImmediately().Named("report").
+		OnCrash(func(e JournalEntry) { crashed <- e }).
+		RerunCrashed().
+		RunCtx(func(ctx context.Context) { keys <- RunKey(ctx) })
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	assert.Equal(t, 1, s.Resume(entries))
+	assert.Equal(t, "report@2", (<-crashed).Run)
+	assert.Equal(t, "report@2", <-keys)
+
+	entries, err = jr.Entries()
+	assert.Nil(t, err)
+	end := entries[4]
+	assert.Equal(t, JournalEnd, end.Event)
+	assert.Equal(t, "report@2", end.Run)
+	assert.Equal(t, errInterrupted, end.Error)
+	assert.Equal(t, 0, s.Resume(entries))
+}
+
+func TestResumeReruns(t *testing.T) {
+	entries := []JournalEntry{
+		{Job: "report", Run: "report@1", Event: JournalStart, Reason: ReasonSchedule},
+		{Job: "report", Run: "report@2", Event: JournalStart, Reason: ReasonSchedule},
+	}
+	jr := &memoryJournal{}
+	s := NewScheduler().WithJournal(jr)
+	keys := make(chan string, 3)
+	job, err := s.Every(1).Hours().NotImmediately().Named("report").WithID("r").
+		RerunCrashed().
+		RunCtx(func(ctx context.Context) { keys <- RunKey(ctx) })
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	// Reruns that cannot start are left unfinished for a later Resume.
+	job.Pause()
+	assert.Equal(t, 0, s.Resume(entries))
+	assert.Empty(t, jr.events())
+	job.Resume()
+
+	assert.Equal(t, 2, s.Resume(entries))
+	assert.Equal(t, "report@1", <-keys)
+	assert.Equal(t, "report@2", <-keys)
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	jr.Lock()
+	assert.Empty(t, Unfinished(jr.entries))
+	jr.Unlock()
+
+	// Reruns leave nothing behind for later runs.
+	assert.True(t, job.Trigger())
+	assert.Regexp(t, `^r@`, <-keys)
+}
@@ -0,0 +1,446 @@
+package scheduler
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRRuleDays bounds the search for the next day matching a recurrence rule,
+// which is long enough for leap days of rules repeating every few years.
+const maxRRuleDays = 366 * 30
+
+type frequency int
+
+const (
+	minutely frequency = iota
+	hourly
+	dailyFreq
+	weeklyFreq
+	monthlyFreq
+	yearly
+)
+
+var frequencies = map[string]frequency{
+	"MINUTELY": minutely,
+	"HOURLY":   hourly,
+	"DAILY":    dailyFreq,
+	"WEEKLY":   weeklyFreq,
+	"MONTHLY":  monthlyFreq,
+	"YEARLY":   yearly,
+}
+
+var rruleDays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// byDay is a BYDAY entry: a weekday, and its occurrence within the month or
+// year if n is not zero, counted from the end if negative.
+type byDay struct {
+	n   int
+	day time.Weekday
+}
+
+type rrule struct {
+	rule     string
+	freq     frequency
+	interval int
+	count    int
+	until    time.Time
+	start    time.Time
+	hasStart bool
+	months   []int
+	monthDay []int
+	days     []byDay
+	hours    []int
+	minutes  []int
+	seconds  []int
+}
+
+// RRule returns the schedule of an RFC 5545 recurrence rule, such as
+// "FREQ=MONTHLY;BYDAY=-1FR" for the last Friday of every month. The rule may
+// be preceded by a DTSTART line, optionally with a TZID, which sets the start
+// of the recurrence, its time of day and its location:
+//
+//	DTSTART;TZID=Europe/Madrid:20150605T170000
+//	RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR
+//
+// Without DTSTART, rules start at midnight, local time, on Monday, January 5,
+// 1970, which also gives the day of MONTHLY and YEARLY rules without BYDAY or
+// BYMONTHDAY, and COUNT is not allowed. FREQ may be MINUTELY, HOURLY, DAILY, WEEKLY,
+// MONTHLY or YEARLY, and the supported parts are INTERVAL, COUNT, UNTIL,
+// BYMONTH, BYMONTHDAY, BYDAY, BYHOUR, BYMINUTE, BYSECOND and WKST=MO.
+func RRule(rule string) (Schedule, error) {
+	r := &rrule{rule: strings.TrimSpace(rule), interval: 1}
+	r.start = time.Date(1970, time.January, 5, 0, 0, 0, 0, time.Local)
+	var body string
+	for _, line := range strings.Split(r.rule, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "DTSTART"):
+			start, err := parseDTStart(line)
+			if err != nil {
+				return nil, err
+			}
+			r.start, r.hasStart = start, true
+		case strings.HasPrefix(line, "RRULE:"):
+			body = strings.TrimPrefix(line, "RRULE:")
+		case line != "":
+			body = line
+		}
+	}
+	if err := r.parse(body); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func parseDTStart(line string) (time.Time, error) {
+	i := strings.LastIndexByte(line, ':')
+	if i < 0 {
+		return time.Time{}, errors.New("bad rrule DTSTART " + strconv.Quote(line))
+	}
+	loc := time.Local
+	for _, param := range strings.Split(line[:i], ";")[1:] {
+		if !strings.HasPrefix(param, "TZID=") {
+			return time.Time{}, errors.New("bad rrule DTSTART " + strconv.Quote(line))
+		}
+		var err error
+		if loc, err = loadLocation(strings.TrimPrefix(param, "TZID=")); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return parseRRuleTime(line[i+1:], loc)
+}
+
+// parseRRuleTime parses a date or date-time in the form of DTSTART and UNTIL,
+// in loc unless it is in UTC.
+func parseRRuleTime(str string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if len(str) != len(layout) {
+			continue
+		}
+		if strings.HasSuffix(layout, "Z") {
+			loc = time.UTC
+		}
+		if t, err := time.ParseInLocation(layout, str, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("bad rrule time " + strconv.Quote(str))
+}
+
+func (r *rrule) parse(body string) error {
+	hasFreq := false
+	for _, part := range strings.Split(body, ";") {
+		i := strings.IndexByte(part, '=')
+		if i < 0 {
+			return errors.New("bad rrule part " + strconv.Quote(part))
+		}
+		name, value := part[:i], part[i+1:]
+		var err error
+		switch name {
+		case "FREQ":
+			r.freq, hasFreq = frequencies[value]
+			if !hasFreq {
+				err = errors.New("unsupported rrule FREQ " + strconv.Quote(value))
+			}
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(value)
+			if err == nil && r.interval < 1 {
+				err = errors.New("bad rrule INTERVAL")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(value)
+			if err == nil && (r.count < 1 || !r.hasStart) {
+				err = errors.New("rrule COUNT needs a positive count and a DTSTART")
+			}
+		case "UNTIL":
+			r.until, err = parseRRuleTime(value, r.start.Location())
+		case "BYMONTH":
+			r.months, err = parseInts(value, 1, 12, false)
+		case "BYMONTHDAY":
+			r.monthDay, err = parseInts(value, 1, 31, true)
+		case "BYHOUR":
+			r.hours, err = parseInts(value, 0, 23, false)
+		case "BYMINUTE":
+			r.minutes, err = parseInts(value, 0, 59, false)
+		case "BYSECOND":
+			r.seconds, err = parseInts(value, 0, 59, false)
+		case "BYDAY":
+			r.days, err = parseDays(value)
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("unsupported rrule WKST " + strconv.Quote(value))
+			}
+		default:
+			err = errors.New("unsupported rrule part " + strconv.Quote(name))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !hasFreq {
+		return errors.New("rrule without FREQ")
+	}
+	for _, d := range r.days {
+		if d.n != 0 && r.freq != monthlyFreq && r.freq != yearly {
+			return errors.New("rrule BYDAY occurrences need FREQ=MONTHLY or YEARLY")
+		}
+	}
+	r.setDefaults()
+	return nil
+}
+
+// setDefaults fills in the parts a rule leaves to DTSTART.
+func (r *rrule) setDefaults() {
+	start := r.start
+	if r.seconds == nil {
+		r.seconds = []int{start.Second()}
+	}
+	if r.minutes == nil {
+		r.minutes = []int{start.Minute()}
+		if r.freq == minutely {
+			r.minutes = rangeOf(60)
+		}
+	}
+	if r.hours == nil {
+		r.hours = []int{start.Hour()}
+		if r.freq <= hourly {
+			r.hours = rangeOf(24)
+		}
+	}
+	switch r.freq {
+	case weeklyFreq:
+		if r.days == nil {
+			r.days = []byDay{{day: start.Weekday()}}
+		}
+	case monthlyFreq:
+		if r.days == nil && r.monthDay == nil {
+			r.monthDay = []int{start.Day()}
+		}
+	case yearly:
+		if r.days == nil && r.monthDay == nil {
+			r.monthDay = []int{start.Day()}
+			if r.months == nil {
+				r.months = []int{int(start.Month())}
+			}
+		}
+	}
+}
+
+func rangeOf(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i
+	}
+	return values
+}
+
+// parseInts parses a comma separated list of numbers from min to max, or from
+// -max to -min as well if negative is set.
+func parseInts(list string, min, max int, negative bool) ([]int, error) {
+	var values []int
+	for _, str := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(str)
+		abs := n
+		if negative && n < 0 {
+			abs = -n
+		}
+		if err != nil || abs < min || abs > max {
+			return nil, errors.New("bad rrule value " + strconv.Quote(str))
+		}
+		values = append(values, n)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+func parseDays(list string) ([]byDay, error) {
+	var days []byDay
+	for _, str := range strings.Split(list, ",") {
+		if len(str) < 2 {
+			return nil, errors.New("bad rrule BYDAY " + strconv.Quote(str))
+		}
+		day, ok := rruleDays[str[len(str)-2:]]
+		if !ok {
+			return nil, errors.New("bad rrule BYDAY " + strconv.Quote(str))
+		}
+		var n int
+		if prefix := str[:len(str)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, errors.New("bad rrule BYDAY " + strconv.Quote(str))
+			}
+		}
+		days = append(days, byDay{n: n, day: day})
+	}
+	return days, nil
+}
+
+func hasInt(values []int, n int) bool {
+	for _, v := range values {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// period returns the number of the FREQ period the given date falls in,
+// counted from the start of the rule, or -1 if it is before the start.
+func (r *rrule) period(year int, month time.Month, day int) int {
+	sy, sm, sd := r.start.Date()
+	days := int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Sub(time.Date(sy, sm, sd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	if days < 0 {
+		return -1
+	}
+	switch r.freq {
+	case weeklyFreq:
+		return weekOf(year, month, day) - weekOf(sy, sm, sd)
+	case monthlyFreq:
+		return (year-sy)*12 + int(month) - int(sm)
+	case yearly:
+		return year - sy
+	}
+	return days
+}
+
+// matchDay reports if the rule has occurrences on the given date.
+func (r *rrule) matchDay(year int, month time.Month, day int) bool {
+	if r.months != nil && !hasInt(r.months, int(month)) {
+		return false
+	}
+	last := daysIn(year, month)
+	if r.monthDay != nil && !hasInt(r.monthDay, day) && !hasInt(r.monthDay, day-last-1) {
+		return false
+	}
+	if r.days != nil && !r.matchWeekday(year, month, day) {
+		return false
+	}
+	p := r.period(year, month, day)
+	if p < 0 {
+		return false
+	}
+	if r.freq >= dailyFreq {
+		return p%r.interval == 0
+	}
+	return true
+}
+
+func (r *rrule) matchWeekday(year int, month time.Month, day int) bool {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	for _, d := range r.days {
+		if date.Weekday() != d.day {
+			continue
+		}
+		if d.n == 0 {
+			return true
+		}
+		// Occurrences count within the month, or within the year for
+		// yearly rules not limited to some months.
+		nth, fromEnd := (day-1)/7+1, -((daysIn(year, month)-day)/7 + 1)
+		if r.freq == yearly && r.months == nil {
+			yearDays := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+			nth, fromEnd = (date.YearDay()-1)/7+1, -((yearDays-date.YearDay())/7 + 1)
+		}
+		if d.n == nth || d.n == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// times returns the occurrences on the given date, which matches the rule.
+func (r *rrule) times(year int, month time.Month, day int) []time.Time {
+	var times []time.Time
+	p := r.period(year, month, day)
+	for _, hour := range r.hours {
+		for _, min := range r.minutes {
+			// Sub-daily rules count their interval in hours or minutes.
+			switch r.freq {
+			case hourly:
+				if (p*24+hour-r.start.Hour())%r.interval != 0 {
+					continue
+				}
+			case minutely:
+				if (p*24*60+hour*60+min-r.start.Hour()*60-r.start.Minute())%r.interval != 0 {
+					continue
+				}
+			}
+			for _, sec := range r.seconds {
+				times = append(times, time.Date(year, month, day, hour, min, sec, 0, r.start.Location()))
+			}
+		}
+	}
+	return times
+}
+
+// following returns the first occurrence after the given time, ignoring COUNT.
+func (r *rrule) following(after time.Time) (time.Time, error) {
+	if after.Before(r.start) {
+		after = r.start.Add(-time.Nanosecond)
+	}
+	after = after.In(r.start.Location())
+	year, month, day := after.Date()
+	for i := 0; i < maxRRuleDays; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, time.UTC)
+		y, m, d := date.Date()
+		if !r.matchDay(y, m, d) {
+			continue
+		}
+		for _, t := range r.times(y, m, d) {
+			if !r.until.IsZero() && t.After(r.until) {
+				return time.Time{}, errNoNextRun
+			}
+			if t.After(after) && !t.Before(r.start) {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, errNoNextRun
+}
+
+func (r *rrule) next(after time.Time) (time.Time, error) {
+	if r.count == 0 {
+		return r.following(after)
+	}
+	t := r.start.Add(-time.Nanosecond)
+	for i := 0; i < r.count; i++ {
+		var err error
+		if t, err = r.following(t); err != nil {
+			return time.Time{}, err
+		}
+		if t.After(after) {
+			return t, nil
+		}
+	}
+	return time.Time{}, errNoNextRun
+}
+
+func (r *rrule) nextRun(now time.Time) (time.Duration, error) {
+	date, err := r.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+// Next implements Schedule.
+func (r *rrule) Next(after time.Time) time.Time {
+	date, _ := r.next(after)
+	return date
+}
+
+func (r *rrule) description() string {
+	return strings.Replace(r.rule, "\n", " ", -1)
+}
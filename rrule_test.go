@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustRRule(t *testing.T, rule string) Schedule {
+	s, err := RRule(rule)
+	assert.Nil(t, err, rule)
+	return s
+}
+
+func TestRRuleLastFriday(t *testing.T) {
+	s := mustRRule(t, "FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=17")
+	assert.Equal(t, date(2015, 6, 26, 17, 0), s.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 7, 31, 17, 0), s.Next(date(2015, 6, 26, 17, 0)))
+	assert.Equal(t, "FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=17", On(s).Description())
+}
+
+func TestRRuleWeekly(t *testing.T) {
+	s := mustRRule(t, "DTSTART:20150605T170000\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=FR")
+	assert.Equal(t, date(2015, 6, 5, 17, 0), s.Next(date(2015, 1, 1, 0, 0)))
+	assert.Equal(t, date(2015, 6, 19, 17, 0), s.Next(date(2015, 6, 5, 17, 0)))
+	assert.Equal(t, date(2015, 7, 3, 17, 0), s.Next(date(2015, 6, 20, 0, 0)))
+
+	s = mustRRule(t, "FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9;BYMINUTE=0,30")
+	assert.Equal(t, date(2015, 6, 3, 9, 0), s.Next(date(2015, 6, 1, 9, 30)))
+	assert.Equal(t, date(2015, 6, 3, 9, 30), s.Next(date(2015, 6, 3, 9, 0)))
+}
+
+func TestRRuleDailyAndSubDaily(t *testing.T) {
+	s := mustRRule(t, "DTSTART:20150601T083000\nRRULE:FREQ=DAILY;INTERVAL=3")
+	assert.Equal(t, date(2015, 6, 4, 8, 30), s.Next(date(2015, 6, 1, 8, 30)))
+
+	s = mustRRule(t, "DTSTART:20150601T000000\nRRULE:FREQ=HOURLY;INTERVAL=6;BYDAY=MO,TU,WE,TH,FR")
+	assert.Equal(t, date(2015, 6, 1, 6, 0), s.Next(date(2015, 6, 1, 0, 0)))
+	assert.Equal(t, date(2015, 6, 8, 0, 0), s.Next(date(2015, 6, 5, 18, 0)))
+
+	s = mustRRule(t, "FREQ=MINUTELY;INTERVAL=15")
+	assert.Equal(t, date(2015, 6, 1, 10, 15), s.Next(date(2015, 6, 1, 10, 7)))
+}
+
+func TestRRuleYearly(t *testing.T) {
+	s := mustRRule(t, "DTSTART:20120229T120000\nRRULE:FREQ=YEARLY")
+	assert.Equal(t, date(2016, 2, 29, 12, 0), s.Next(date(2012, 3, 1, 0, 0)))
+
+	s = mustRRule(t, "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH")
+	assert.Equal(t, date(2015, 11, 26, 0, 0), s.Next(date(2015, 6, 1, 0, 0)))
+
+	s = mustRRule(t, "FREQ=YEARLY;BYDAY=1MO")
+	assert.Equal(t, date(2016, 1, 4, 0, 0), s.Next(date(2015, 6, 1, 0, 0)))
+}
+
+func TestRRuleMonthDay(t *testing.T) {
+	s := mustRRule(t, "FREQ=MONTHLY;BYMONTHDAY=-1")
+	assert.Equal(t, date(2016, 2, 29, 0, 0), s.Next(date(2016, 2, 1, 0, 0)))
+
+	s = mustRRule(t, "DTSTART:20150131T100000\nRRULE:FREQ=MONTHLY")
+	assert.Equal(t, date(2015, 3, 31, 10, 0), s.Next(date(2015, 1, 31, 10, 0)))
+}
+
+func TestRRuleCountAndUntil(t *testing.T) {
+	s := mustRRule(t, "DTSTART:20150601T090000\nRRULE:FREQ=DAILY;COUNT=3")
+	assert.Equal(t, date(2015, 6, 1, 9, 0), s.Next(date(2015, 1, 1, 0, 0)))
+	assert.Equal(t, date(2015, 6, 3, 9, 0), s.Next(date(2015, 6, 2, 9, 0)))
+	assert.True(t, s.Next(date(2015, 6, 3, 9, 0)).IsZero())
+
+	s = mustRRule(t, "RRULE:FREQ=DAILY;UNTIL=20150603T000000Z;BYHOUR=12")
+	assert.True(t, s.Next(time.Date(2015, 6, 2, 12, 0, 0, 0, time.UTC)).IsZero())
+}
+
+func TestRRuleTZID(t *testing.T) {
+	s := mustRRule(t, "DTSTART;TZID=America/New_York:20150601T090000\nRRULE:FREQ=DAILY")
+	ny, err := time.LoadLocation("America/New_York")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2015, 6, 2, 9, 0, 0, 0, ny), s.Next(time.Date(2015, 6, 1, 14, 0, 0, 0, time.UTC)))
+}
+
+func TestBadRRule(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"BYDAY=MO",
+		"FREQ=SECONDLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=WEEKLY;WKST=SU",
+		"DTSTART:2015\nRRULE:FREQ=DAILY",
+		"DTSTART;TZID=Nowhere/City:20150601T090000\nRRULE:FREQ=DAILY",
+	} {
+		_, err := RRule(rule)
+		assert.NotNil(t, err, rule)
+	}
+}
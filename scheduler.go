@@ -15,25 +15,104 @@
 package scheduler
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+// Schedule computes when a job runs. Next returns the first activation time
+// later than after, or the zero time if there is none. Implement it to run jobs
+// on calendars of your own with On.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// scheduled is implemented by the package's own schedules, which may differ
+// from plain Schedules in their first run, see nextRun.
 type scheduled interface {
-	nextRun() (time.Duration, error)
+	Schedule
+	nextRun(now time.Time) (time.Duration, error)
+	next(after time.Time) (time.Time, error)
+	description() string
 }
 
 // Job defines a running job and allows to stop a scheduled job or run it.
+//
+// Once a job runs, its methods may be called from any goroutine, except those
+// that build it, which set its schedule or options and must be called before
+// Run. Use Reschedule to change the schedule of a running job.
 type Job struct {
-	fn        func()
+	fn        func(context.Context) error
+	ctx       context.Context
+	deadline  bool
 	Quit      chan bool
 	SkipWait  chan bool
 	err       error
 	schedule  scheduled
 	isRunning bool
+	inline    bool
+	timer     Timer
+	clock     Clock
+	running   RunTracker
+	nextAt    time.Time
+	scheduler *Scheduler
+	paused    bool
+	stats     Stats
+	since     Stats
+	durations sketch
+	sinceDur  sketch
+	queue     int
+	pending   int
+	overflow  func()
+	attempts  int
+	delay     time.Duration
+	exhausted func(error)
+	onSuccess func(RunInfo)
+	onFailure func(RunInfo, error)
+	breakAt   int
+	cooloff   time.Duration
+	onBreak   func(error)
+	onStatus  func(StatusChange)
+	status    Status
+	failures  int
+	pauses    int
+	timeout   time.Duration
+	startedAt time.Time
+	stopErr   error
+	cause     error
+	done      chan RunResult
+	subs      []*subscriber
+	maxErrors float64
+	outcomes  outcomes
+	exited    bool
+	name      string
+	id        string
+	lastErr   error
+	errs      []RunError
+	changes   chan change
+	payload   []byte
+	drift     time.Duration
+	holidays  HolidayProvider
+	shift     bool
+	logger    *slog.Logger
+	exclusive string
+	skipLate  bool
+	onOverrun func(time.Time)
+	stallTime time.Duration
+	onStall   func(RunInfo)
+	beat      *Heartbeat
+	abort     context.CancelFunc
+	recovers  bool
+	onPanic   PanicPolicy
+	panicOff  time.Duration
+	onCrash   func(JournalEntry)
+	rerun     bool
+	reruns    []string
 	sync.RWMutex
 }
 
@@ -41,67 +120,254 @@ type recurrent struct {
 	units  int
 	period time.Duration
 	done   bool
+	// anchored schedules count every period from epoch, the time of the first
+	// call to nextRun, instead of from the previous run. See NoDrift.
+	anchored bool
+	epoch    time.Time
+	// phased schedules run at offset past each multiple of the period since
+	// midnight. See AtSecond and AtMinute.
+	phased bool
+	offset time.Duration
+	// adapt, if set, gives the wait before each run after the first. See
+	// AdaptiveInterval.
+	adapt func() time.Duration
+	// days, if not empty, are the weekdays the schedule runs on, one bit
+	// each. See OnlyOn.
+	days uint8
 }
 
-func (r *recurrent) nextRun() (time.Duration, error) {
+func (r *recurrent) nextRun(now time.Time) (time.Duration, error) {
 	if r.units == 0 || r.period == 0 {
 		return 0, errors.New("cannot set recurrent time with 0")
 	}
+	if r.phased {
+		next, err := r.next(now)
+		return next.Sub(now), err
+	}
+	wait := r.wait(now)
+	return r.onDay(now.Add(wait)).Sub(now), nil
+}
+
+// wait returns the time to the next run of a schedule that is not phased,
+// whatever the weekday.
+func (r *recurrent) wait(now time.Time) time.Duration {
+	every := time.Duration(r.units) * r.period
+	if r.anchored && !r.epoch.IsZero() {
+		// The first period boundary after now, skipping those missed by a
+		// late run.
+		return r.epoch.Add((now.Sub(r.epoch)/every + 1) * every).Sub(now)
+	}
+	if r.anchored {
+		r.epoch = now
+	}
 	if !r.done {
 		r.done = true
-		return 0, nil
+		return 0
 	}
-	return time.Duration(r.units) * r.period, nil
+	if r.adapt != nil {
+		if d := r.adapt(); d > 0 {
+			return d
+		}
+	}
+	return every
+}
+
+// onDay returns at if it falls on one of the weekdays of the schedule, or
+// otherwise its first run on the next of them: at midnight, or at the first
+// period boundary after it for phased and anchored schedules.
+func (r *recurrent) onDay(at time.Time) time.Time {
+	if r.days == 0 || r.days&(1<<at.Weekday()) != 0 {
+		return at
+	}
+	year, month, day := at.Date()
+	for i := 1; i <= 7; i++ {
+		midnight := time.Date(year, month, day+i, 0, 0, 0, 0, at.Location())
+		if r.days&(1<<midnight.Weekday()) != 0 {
+			return r.from(midnight)
+		}
+	}
+	return at
+}
+
+// from returns the first run of the schedule on the day starting at midnight
+// after a break, such as days left out by OnlyOn: midnight itself, or the
+// first period boundary from it for phased and anchored schedules.
+func (r *recurrent) from(midnight time.Time) time.Time {
+	every := time.Duration(r.units) * r.period
+	switch {
+	case r.phased:
+		return alignedInterval(every).Next(midnight.Add(-r.offset - time.Nanosecond)).Add(r.offset)
+	case r.anchored && !r.epoch.IsZero() && midnight.After(r.epoch):
+		return r.epoch.Add((midnight.Sub(r.epoch) + every - 1) / every * every)
+	}
+	return midnight
+}
+
+func (r *recurrent) next(after time.Time) (time.Time, error) {
+	if r.units == 0 || r.period == 0 {
+		return time.Time{}, errors.New("cannot set recurrent time with 0")
+	}
+	every := time.Duration(r.units) * r.period
+	if r.phased {
+		return r.onDay(alignedInterval(every).Next(after.Add(-r.offset)).Add(r.offset)), nil
+	}
+	return r.onDay(after.Add(every)), nil
+}
+
+// Next implements Schedule.
+func (r *recurrent) Next(after time.Time) time.Time {
+	date, _ := r.next(after)
+	return date
+}
+
+func (r *recurrent) description() string {
+	desc := "every " + (time.Duration(r.units) * r.period).String()
+	if r.phased {
+		desc += " at " + r.offset.String() + " past"
+	}
+	if r.adapt != nil {
+		desc += ", adaptive"
+	}
+	return desc + r.onDays()
+}
+
+// onDays describes the weekdays of the schedule, if restricted.
+func (r *recurrent) onDays() string {
+	if r.days == 0 {
+		return ""
+	}
+	var days []string
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if r.days&(1<<day) != 0 {
+			days = append(days, day.String())
+		}
+	}
+	return " on " + strings.Join(days, ", ")
+}
+
+// calendar is implemented by schedules that run at a time of day, which At
+// and Timezone adjust through the embedded daily.
+type calendar interface {
+	scheduled
+	clock() *daily
 }
 
 type daily struct {
 	hour int
 	min  int
 	sec  int
+	nsec int
+	loc  *time.Location
 }
 
-func (d *daily) setTime(h, m, s int) {
+func (d *daily) setTime(h, m, s, ns int) {
 	d.hour = h
 	d.min = m
 	d.sec = s
+	d.nsec = ns
+}
+
+func (d *daily) clock() *daily {
+	return d
 }
 
-func (d daily) nextRun() (time.Duration, error) {
-	now := time.Now()
-	year, month, day := now.Date()
-	date := time.Date(year, month, day, d.hour, d.min, d.sec, 0, time.Local)
-	if now.Before(date) {
-		return date.Sub(now), nil
+func (d *daily) location() *time.Location {
+	if d.loc == nil {
+		return time.Local
 	}
-	date = time.Date(year, month, day+1, d.hour, d.min, d.sec, 0, time.Local)
+	return d.loc
+}
+
+// on returns the time of day d on the given date, normalizing the date as
+// time.Date does.
+func (d *daily) on(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, d.hour, d.min, d.sec, d.nsec, d.location())
+}
+
+func (d *daily) nextRun(now time.Time) (time.Duration, error) {
+	date, _ := d.next(now)
 	return date.Sub(now), nil
 }
 
+func (d *daily) next(after time.Time) (time.Time, error) {
+	after = after.In(d.location())
+	year, month, day := after.Date()
+	date := d.on(year, month, day)
+	if after.Before(date) {
+		return date, nil
+	}
+	return d.on(year, month, day+1), nil
+}
+
+// Next implements Schedule.
+func (d *daily) Next(after time.Time) time.Time {
+	date, _ := d.next(after)
+	return date
+}
+
+func (d *daily) description() string {
+	return "every day at " + d.timeString()
+}
+
+func (d *daily) timeString() string {
+	return time.Date(0, 1, 1, d.hour, d.min, d.sec, d.nsec, time.UTC).Format("15:04:05.999999999")
+}
+
 type weekly struct {
 	day time.Weekday
 	d   daily
+	// week is set for calendar weeks, whose start Starting can move. See
+	// Week.
+	week bool
 }
 
-func (w weekly) nextRun() (time.Duration, error) {
-	now := time.Now()
-	year, month, day := now.Date()
-	numDays := w.day - now.Weekday()
-	if numDays == 0 {
-		numDays = 7
-	} else if numDays < 0 {
-		numDays += 7
-	}
-	date := time.Date(year, month, day+int(numDays), w.d.hour, w.d.min, w.d.sec, 0, time.Local)
+func (w *weekly) clock() *daily {
+	return &w.d
+}
+
+func (w *weekly) nextRun(now time.Time) (time.Duration, error) {
+	date, _ := w.next(now)
 	return date.Sub(now), nil
 }
 
+func (w *weekly) next(after time.Time) (time.Time, error) {
+	after = after.In(w.d.location())
+	year, month, day := after.Date()
+	days := (int(w.day) - int(after.Weekday()) + 7) % 7
+	date := w.d.on(year, month, day+days)
+	if !date.After(after) {
+		date = w.d.on(year, month, day+days+7)
+	}
+	return date, nil
+}
+
+// Next implements Schedule.
+func (w *weekly) Next(after time.Time) time.Time {
+	date, _ := w.next(after)
+	return date
+}
+
+func (w *weekly) description() string {
+	return "every " + w.day.String() + " at " + w.d.timeString()
+}
+
 // Every defines when to run a job. For a recurrent jobs (n seconds/minutes/hours) you
 // should specify the unit and then call to the correspondent period method.
+//
+// n is a plain count of units: Every(5).Seconds() runs every five seconds.
+// Durations go to EveryDuration instead. A count of seconds, minutes or hours
+// that makes an interval longer than ten years, as a duration such as
+// int(500*time.Millisecond) does, is rejected with an error, saying so if it
+// looks like a duration, as is a negative count.
 func Every(times ...int) *Job {
 	switch len(times) {
 	case 0:
 		return &Job{}
 	case 1:
+		n := times[0]
+		if n < 0 {
+			return &Job{err: errors.New("negative interval in Every")}
+		}
 		r := new(recurrent)
 		r.units = times[0]
 		return &Job{schedule: r}
@@ -113,99 +379,900 @@ func Every(times ...int) *Job {
 	}
 }
 
-// NotImmediately allows recurrent jobs not to be executed immediatelly after
-// definition. If a job is declared hourly won't start executing until the first hour
-// passed.
+// EveryDuration defines a job that runs every d, which may combine units, e.g.
+// EveryDuration(90 * time.Second) or EveryDuration(time.Hour + 30*time.Minute).
+// It behaves like Every(n) followed by a unit and needs no unit of its own.
+func EveryDuration(d time.Duration) *Job {
+	if d <= 0 {
+		return &Job{err: errors.New("cannot set recurrent time with 0")}
+	}
+	return &Job{schedule: &recurrent{units: 1, period: d}}
+}
+
+// EveryString works like EveryDuration with a duration written as for
+// time.ParseDuration, e.g. "1h30m", as is usual in configuration files and
+// environment variables. Run returns an error if it cannot be parsed.
+func EveryString(duration string) *Job {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return &Job{err: errors.New("bad duration " + strconv.Quote(duration))}
+	}
+	return EveryDuration(d)
+}
+
+// On creates a job driven by any Schedule, e.g. one returned by ParseSchedule
+// or Union, or a custom implementation. The job stops once the schedule has no
+// further activations.
+func On(s Schedule) *Job {
+	if s == nil {
+		return &Job{err: errors.New("nil schedule")}
+	}
+	if p, ok := s.(Plan); ok {
+		return p.job()
+	}
+	if unbounded(s) {
+		return &Job{err: errors.New("window needs Intersect")}
+	}
+	if own, ok := s.(scheduled); ok {
+		return &Job{schedule: own}
+	}
+	return &Job{schedule: customSchedule{s}}
+}
+
+type customSchedule struct {
+	Schedule
+}
+
+func (c customSchedule) nextRun(now time.Time) (time.Duration, error) {
+	return nextRunOf(c.Schedule, now)
+}
+
+func (c customSchedule) next(after time.Time) (time.Time, error) {
+	return nextOf(c.Schedule, after)
+}
+
+func (c customSchedule) description() string {
+	return "custom schedule"
+}
+
+// NotImmediately allows recurrent and Backoff jobs not to be executed
+// immediatelly after definition. If a job is declared hourly won't start
+// executing until the first hour passed.
 func (j *Job) NotImmediately() *Job {
+	if j.err != nil {
+		return j
+	}
+	switch s := j.schedule.(type) {
+	case *recurrent:
+		s.done = true
+	case *backoff:
+		s.done = true
+	default:
+		j.err = errors.New("bad function chaining")
+	}
+	return j
+}
+
+// AtSecond sets a job recurring every n minutes or hours to run at the given
+// second past each period, counted from midnight, instead of from the time it
+// was started: Every(1).Minutes().AtSecond(30) runs at 00:00:30, 00:01:30 and
+// so on. It may follow AtMinute. The first run is the next such time.
+func (j *Job) AtSecond(sec int) *Job {
+	return j.phase(sec, time.Second)
+}
+
+// AtMinute sets a job recurring every n hours to run at the given minute past
+// each period, counted from midnight: Every(1).Hours().AtMinute(15) runs at
+// 00:15, 01:15 and so on. The first run is the next such time.
+func (j *Job) AtMinute(min int) *Job {
+	return j.phase(min, time.Minute)
+}
+
+// phase moves a recurrent job whose period is at least 60 units to run n
+// units, from 0 to 59, past each period.
+func (j *Job) phase(n int, unit time.Duration) *Job {
+	if j.err != nil {
+		return j
+	}
 	rj, ok := j.schedule.(*recurrent)
-	if !ok {
+	if !ok || rj.adapt != nil || rj.period < 60*unit || rj.offset%(60*unit) >= unit {
 		j.err = errors.New("bad function chaining")
 		return j
 	}
+	if n < 0 || n > 59 {
+		j.err = errors.New("bad offset")
+		return j
+	}
+	rj.phased = true
 	rj.done = true
+	rj.offset += time.Duration(n) * unit
+	return j
+}
+
+// NoDrift makes a recurrent job count every period from the time it was
+// started instead of from its previous run, so the time taken to wake up and
+// dispatch each run does not add up. Every(24).Hours().NoDrift() started at
+// 02:00 keeps running at 02:00 for as long as the program lives. A run late
+// enough to miss a period boundary waits for the next one.
+func (j *Job) NoDrift() *Job {
+	if j.err != nil {
+		return j
+	}
+	rj, ok := j.schedule.(*recurrent)
+	if !ok || rj.adapt != nil {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	rj.anchored = true
+	return j
+}
+
+// AdaptiveInterval makes a recurrent job ask f how long to wait before each
+// run, so that polling can follow load, queue depth or rate limits:
+// Every(1).Minutes().AdaptiveInterval(f) waits whatever f returns, or a
+// minute when f returns 0 or less. f is called from the job's scheduling
+// goroutine and should return quickly. Previews such as NextN and DryRun
+// assume the static interval. It cannot be combined with NoDrift, AtSecond
+// or AtMinute.
+func (j *Job) AdaptiveInterval(f func() time.Duration) *Job {
+	if j.err != nil {
+		return j
+	}
+	rj, ok := j.schedule.(*recurrent)
+	if !ok || rj.phased || rj.anchored {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if f == nil {
+		j.err = errors.New("nil interval function")
+		return j
+	}
+	rj.adapt = f
+	return j
+}
+
+// OnlyOn restricts an interval job to the given weekdays, e.g.
+// Every(10).Minutes().OnlyOn(time.Monday, time.Friday). Runs that would fall
+// on other days are left out, and the job resumes at midnight of the next
+// allowed day, or at the first run after it for AtSecond, AtMinute and NoDrift
+// schedules. Weekdays are those of the clock's time zone.
+func (j *Job) OnlyOn(days ...time.Weekday) *Job {
+	if j.err != nil {
+		return j
+	}
+	rj, ok := j.schedule.(*recurrent)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if len(days) == 0 {
+		j.err = errors.New("no weekdays")
+		return j
+	}
+	for _, day := range days {
+		if day < time.Sunday || day > time.Saturday {
+			j.err = errors.New("bad weekday")
+			return j
+		}
+		rj.days |= 1 << day
+	}
 	return j
 }
 
 // At lets you define a specific time when the job would be run. Does not work with
 // recurrent jobs.
 // Time should be defined as a string separated by a colon. Could be used as "08:35:30",
-// "08:35" or "8" for only the hours.
+// "08:35" or "8" for only the hours. Seconds may carry a fraction of up to nine
+// digits, e.g. "08:35:30.250" for a quarter past the second.
 func (j *Job) At(hourTime string) *Job {
 	if j.err != nil {
 		return j
 	}
-	hour, min, sec, err := parseTime(hourTime)
+	hour, min, sec, nsec, err := parseTime(hourTime)
 	if err != nil {
 		j.err = err
 		return j
 	}
-	d, ok := j.schedule.(daily)
+	return j.atClock(hour, min, sec, nsec)
+}
+
+// AtTime works like At with the time given as numbers, e.g. AtTime(8, 35, 0)
+// for "08:35:00".
+func (j *Job) AtTime(hour, min, sec int) *Job {
+	if j.err != nil {
+		return j
+	}
+	if hour < 0 || min < 0 || sec < 0 || hour > 23 || min > 59 || sec > 59 {
+		j.err = errors.New("bad time")
+		return j
+	}
+	return j.atClock(hour, min, sec, 0)
+}
+
+// AtClock works like At with the time of day of t, down to the nanosecond.
+// The job runs in t's location, as if set with Timezone.
+func (j *Job) AtClock(t time.Time) *Job {
+	if j.err != nil {
+		return j
+	}
+	hour, min, sec := t.Clock()
+	j.atClock(hour, min, sec, t.Nanosecond())
+	if c, ok := j.schedule.(calendar); ok {
+		c.clock().loc = t.Location()
+	}
+	return j
+}
+
+func (j *Job) atClock(hour, min, sec, nsec int) *Job {
+	c, ok := j.schedule.(calendar)
 	if !ok {
-		w, ok := j.schedule.(weekly)
-		if !ok {
-			j.err = errors.New("bad function chaining")
-			return j
-		}
-		w.d.setTime(hour, min, sec)
-		j.schedule = w
-	} else {
-		d.setTime(hour, min, sec)
-		j.schedule = d
+		j.err = errors.New("bad function chaining")
+		return j
 	}
+	c.clock().setTime(hour, min, sec, nsec)
 	return j
 }
 
 // Run sets the job to the schedule and returns the pointer to the job so it may be
 // stopped or executed without waiting or an error.
 func (j *Job) Run(f func()) (*Job, error) {
+	return j.RunErr(func(context.Context) error {
+		f()
+		return nil
+	})
+}
+
+// RunCtx works like Run for functions that take a context. The context is
+// canceled once the job is stopped, and with DeadlineAtNextRun it also expires
+// when the next run is due.
+func (j *Job) RunCtx(f func(ctx context.Context)) (*Job, error) {
+	return j.RunErr(func(ctx context.Context) error {
+		f(ctx)
+		return nil
+	})
+}
+
+// RunErr works like RunCtx for functions that can fail. Failed runs are
+// retried as set with Retry, and OnExhausted is called once they run out of
+// attempts.
+func (j *Job) RunErr(f func(ctx context.Context) error) (*Job, error) {
+	j.applyDefaults()
 	if j.err != nil {
+		j.report(j.err)
+		j.unsubscribe()
 		return nil, j.err
 	}
 	var next time.Duration
 	var err error
+	var cancel context.CancelFunc
 	j.Quit = make(chan bool, 1)
 	j.SkipWait = make(chan bool, 1)
+	j.changes = make(chan change)
 	j.fn = f
+	parent := context.Background()
+	if j.scheduler != nil {
+		parent = j.scheduler.parent()
+	}
+	j.ctx, cancel = context.WithCancel(parent)
 	// Check for possible errors in scheduling
-	next, err = j.schedule.nextRun()
+	next, err = j.nextRun(j.now())
 	if err != nil {
+		cancel()
+		j.changes = nil
+		j.report(err)
+		j.unsubscribe()
 		return nil, err
 	}
+	next += j.stagger()
+	j.setNextAt(j.now().Add(next))
+	if j.scheduler != nil {
+		j.scheduler.register(j)
+	}
+	if d := j.dispatcher(); d != nil {
+		j.changed()
+		d.add(&entry{job: j, parent: parent, cancel: cancel, at: time.Now().Add(j.wait(next))})
+		return j, nil
+	}
+	j.timer = j.getClock().NewTimer(j.wait(next))
+	j.changed()
 	go func(j *Job) {
+		defer j.unsubscribe()
+		defer cancel()
+		defer j.exit()
+		defer j.timer.Stop()
+		var applied chan struct{}
 		for {
+			// A pending Quit wins over a run that is due at the same time.
 			select {
 			case <-j.Quit:
+				j.setCause(ErrStopped)
+				return
+			case <-parent.Done():
+				j.setCause(context.Cause(parent))
+				return
+			default:
+			}
+			select {
+			case <-j.Quit:
+				j.setCause(ErrStopped)
+				return
+			case <-parent.Done():
+				j.setCause(context.Cause(parent))
 				return
 			case <-j.SkipWait:
-				go runJob(j)
-			case <-time.After(next):
-				go runJob(j)
+				j.stopTimer()
+				j.dispatch(j.takePayload(), time.Time{})
+			case c := <-j.changes:
+				j.stopTimer()
+				j.Lock()
+				j.schedule = c.schedule
+				j.Unlock()
+				applied = c.done
+			case <-j.timer.C():
+				if d := j.untilDue(); d > 0 {
+					j.timer.Reset(d)
+					continue
+				}
+				j.RLock()
+				due := j.nextAt
+				j.RUnlock()
+				j.dispatch(nil, due)
 			}
-			next, _ = j.schedule.nextRun()
+			wait, ok := j.advance()
+			if applied != nil {
+				close(applied)
+				applied = nil
+			}
+			if !ok {
+				return
+			}
+			j.timer.Reset(wait)
 		}
 	}(j)
 	return j, nil
 }
 
-func (j *Job) setRunning(running bool) {
+// advance works out the job's next run once its scheduling goroutine has
+// handled an event, and returns how long to wait for it. It returns false,
+// having stopped or unregistered the job as its schedule requires, if the job
+// has no more runs.
+func (j *Job) advance() (time.Duration, bool) {
+	next, err := j.nextRun(j.now())
+	if err != nil {
+		if err != errNoNextRun {
+			j.stopped(err)
+		} else if j.scheduler != nil && ends(j.schedule) {
+			j.scheduler.unregister(j)
+		}
+		return 0, false
+	}
+	j.setNextAt(j.now().Add(next))
+	return j.wait(next), true
+}
+
+// takePayload returns the payload of the run requested by TriggerWith, if any.
+func (j *Job) takePayload() []byte {
 	j.Lock()
 	defer j.Unlock()
 
-	j.isRunning = running
+	payload := j.payload
+	j.payload = nil
+	return payload
+}
+
+// DeadlineAtNextRun sets the context given to a RunCtx function to expire when
+// the next run of the job is due, so runs that must not overlap the next one
+// can give up in time.
+func (j *Job) DeadlineAtNextRun() *Job {
+	j.deadline = true
+	return j
+}
+
+// RunInline works like Run but executes the job in the scheduling goroutine
+// instead of a new one. Runs are therefore serialized: a run that is due while
+// the previous one is still executing waits for it, and recurrent jobs count
+// their period from the end of the previous run.
+func (j *Job) RunInline(f func()) (*Job, error) {
+	j.inline = true
+	return j.Run(f)
+}
+
+// stopTimer stops the job's timer and discards an expiration that was not
+// received, leaving it safe to Reset. It must only be called from the
+// scheduling goroutine, which owns the timer once Run has started it.
+func (j *Job) stopTimer() {
+	if !j.timer.Stop() {
+		select {
+		case <-j.timer.C():
+		default:
+		}
+	}
 }
 
-func runJob(job *Job) {
-	if job.IsRunning() {
+// dispatch starts a run unless the job is paused or the previous run is still
+// executing, see claim. The running flag is claimed here, before spawning anything, so
+// skipped runs cost neither a goroutine nor a race between the check and the
+// set. Runs go to the scheduler's worker pool, if it has one. The run's
+// context carries payload, if any, see TriggerWith, and pprof labels. Runs
+// that were due at a scheduled time, rather than triggered, pass it as due.
+func (j *Job) dispatch(payload []byte, due time.Time) {
+	if !j.claim(due) {
 		return
 	}
-	job.setRunning(true)
-	job.fn()
-	job.setRunning(false)
+	j.start(payload, due, "")
+}
+
+// start starts a run that was claimed. A run with a key, a rerun of a crashed
+// run, see Resume, keeps it.
+func (j *Job) start(payload []byte, due time.Time, key string) {
+	if j.running != nil {
+		j.running.RunStarted()
+	}
+	run := func() {
+		ctx, cancel := j.runContext()
+		reason := ReasonSchedule
+		switch {
+		case key != "":
+			ctx = context.WithValue(ctx, runKey{}, key)
+			reason = ReasonRerun
+		case due.IsZero():
+			ctx = j.withRunKey(ctx, due)
+			reason = ReasonTrigger
+		default:
+			ctx = j.withRunKey(ctx, due)
+		}
+		if payload != nil {
+			ctx = context.WithValue(ctx, payloadKey{}, payload)
+		}
+		j.labeled(ctx, func(ctx context.Context) { j.execute(ctx, cancel, reason) })
+	}
+	switch p := j.pool(); {
+	case j.inline:
+		run()
+	case p != nil:
+		p.submit(run)
+	default:
+		go run()
+	}
 }
 
-func parseTime(str string) (hour, min, sec int, err error) {
+// runContext returns the context for a run starting now.
+func (j *Job) runContext() (context.Context, context.CancelFunc) {
+	if j.timeout > 0 {
+		ctx, cancel := j.deadlineContext()
+		ctx, cancelTimeout := context.WithTimeout(ctx, j.timeout)
+		return ctx, func() {
+			cancelTimeout()
+			cancel()
+		}
+	}
+	return j.deadlineContext()
+}
+
+func (j *Job) deadlineContext() (context.Context, context.CancelFunc) {
+	if j.deadline {
+		now := j.now()
+		if next, err := j.current().next(now); err == nil {
+			// The job's clock may be virtual, so only its distance to the
+			// next run carries over to the real deadline of the context.
+			return context.WithTimeout(j.ctx, next.Sub(now))
+		}
+	}
+	return context.WithCancel(j.ctx)
+}
+
+// exit marks the job's scheduling goroutine as gone.
+func (j *Job) exit() {
+	j.Lock()
+	j.exited = true
+	j.Unlock()
+	j.changed()
+}
+
+func (j *Job) setNextAt(t time.Time) {
+	j.Lock()
+	defer j.Unlock()
+
+	j.nextAt = t
+}
+
+// claim marks the job as running if it can start a run now. Otherwise the run
+// is queued, if Queue allows it, or skipped, as it is while the job is paused,
+// its scheduler on standby or the job owned by another node of the cluster.
+// The drift of a run started at its due time is recorded in the job's stats.
+func (j *Job) claim(due time.Time) bool {
+	standby := j.scheduler != nil && !j.scheduler.runs(j.Name())
+	j.Lock()
+	overflow, overran := false, false
+	switch {
+	case j.paused || standby:
+		j.record(func(s *Stats) { s.Skipped++ })
+	case j.isRunning && j.skipLate && !due.IsZero():
+		j.record(func(s *Stats) { s.Skipped++ })
+		overran = j.onOverrun != nil
+	case j.isRunning && j.pending < j.queue:
+		j.pending++
+	case j.isRunning:
+		j.record(func(s *Stats) { s.Skipped++ })
+		overflow = j.overflow != nil
+	default:
+		j.begin()
+		if !due.IsZero() {
+			j.drift = j.startedAt.Sub(due)
+			j.record(func(s *Stats) {
+				s.Drift = j.drift
+				s.MaxDrift = max(s.MaxDrift, j.drift)
+			})
+		}
+		j.Unlock()
+		j.changed()
+		return true
+	}
+	j.Unlock()
+
+	if overflow {
+		j.overflow()
+	}
+	if overran {
+		j.onOverrun(due)
+	}
+	return false
+}
+
+// claimRerun marks the job as running for a rerun of a crashed run, see
+// Resume, if it can start one now. Unlike claim, it neither queues nor counts
+// a run that cannot start.
+func (j *Job) claimRerun() bool {
+	standby := j.scheduler != nil && !j.scheduler.runs(j.Name())
+	j.Lock()
+	if j.paused || standby || j.isRunning {
+		j.Unlock()
+		return false
+	}
+	j.begin()
+	j.Unlock()
+	j.changed()
+	return true
+}
+
+// begin marks the job as running a run that starts now.
+func (j *Job) begin() {
+	j.isRunning = true
+	j.record(func(s *Stats) { s.Runs++ })
+	j.startedAt = j.now()
+	j.drift = 0
+}
+
+// release ends a run. It returns true, leaving the job running, if a queued
+// run or a rerun must start next, along with the key of the rerun.
+func (j *Job) release() (more bool, key string) {
+	j.Lock()
+	defer j.Unlock()
+
+	switch {
+	case j.pending > 0:
+		j.pending--
+	case len(j.reruns) > 0:
+		key = j.reruns[0]
+		j.reruns = j.reruns[1:]
+	default:
+		j.isRunning = false
+		return false, ""
+	}
+	j.begin()
+	return true, key
+}
+
+func (j *Job) execute(ctx context.Context, cancel context.CancelFunc, reason string) {
+	for {
+		j.Lock()
+		j.abort = cancel
+		j.Unlock()
+		var result *RunResult
+		if ctx, unlock, ok := j.lock(ctx); ok {
+			j.journalize(ctx, JournalStart, reason, nil)
+			run, stop := j.heartbeat(ctx)
+			r := j.call(run)
+			stop()
+			unlock()
+			result = &r
+			j.journalize(ctx, JournalEnd, reason, result)
+		} else {
+			j.journalize(ctx, JournalSkip, reason, nil)
+		}
+		cancel()
+		j.Lock()
+		j.abort = nil
+		j.Unlock()
+		more, key := j.release()
+		j.changed()
+		j.persist()
+		if result != nil {
+			j.notify(*result)
+		}
+		if more && j.running != nil {
+			j.running.RunStarted()
+		}
+		if j.running != nil {
+			j.running.RunFinished()
+		}
+		if !more {
+			return
+		}
+		ctx, cancel = j.runContext()
+		if key != "" {
+			ctx = context.WithValue(ctx, runKey{}, key)
+			reason = ReasonRerun
+		} else {
+			ctx = j.withRunKey(ctx, time.Time{})
+			reason = ReasonQueue
+		}
+	}
+}
+
+// call runs the job's function, retrying it as set with Retry, and returns its
+// result.
+func (j *Job) call(ctx context.Context) RunResult {
+	var err error
+	fn := j.function()
+	started := j.now()
+	j.RLock()
+	drift := j.drift
+	j.RUnlock()
+	attempt := 1
+	for ; ; attempt++ {
+		if err = j.attempt(ctx, fn); err == nil || attempt >= j.attempts || isPanic(err) || !sleep(ctx, j.delay) {
+			break
+		}
+	}
+	info := RunInfo{Job: j, Started: started, Duration: j.now().Sub(started), Attempts: attempt, Drift: drift}
+	j.Lock()
+	j.record(func(s *Stats) {
+		s.Finished++
+		s.TotalDuration += info.Duration
+		s.MaxDuration = max(s.MaxDuration, info.Duration)
+	})
+	j.durations.add(info.Duration)
+	j.sinceDur.add(info.Duration)
+	j.Unlock()
+	if err == nil {
+		j.failed(nil)
+		if j.onSuccess != nil {
+			j.onSuccess(info)
+		}
+		return RunResult{RunInfo: info}
+	}
+	if j.exhausted != nil {
+		j.exhausted(err)
+	}
+	j.failed(err)
+	if j.onFailure != nil {
+		j.onFailure(info, err)
+	}
+	j.panicked(err)
+	return RunResult{RunInfo: info, Err: err}
+}
+
+// RunResult is the outcome of a finished run, see Done.
+type RunResult struct {
+	RunInfo
+	// Err is the error of the run's last attempt, or nil if it succeeded.
+	Err error
+}
+
+// Done returns a channel that receives the result of each run of the job once
+// it has finished, e.g. for tests and coordinators that wait for the next run.
+// Results are sent without blocking the job: if the previous one was not
+// received yet, it is dropped for the newer one. All calls return the same
+// channel.
+func (j *Job) Done() <-chan RunResult {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.done == nil {
+		j.done = make(chan RunResult, 1)
+	}
+	return j.done
+}
+
+// notify sends the result of a run to the channel returned by Done, if any.
+func (j *Job) notify(r RunResult) {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.done == nil {
+		return
+	}
+	for {
+		select {
+		case j.done <- r:
+			return
+		default:
+		}
+		select {
+		case <-j.done:
+		default:
+		}
+	}
+}
+
+// RunInfo describes a finished run, see OnSuccess and OnFailure.
+type RunInfo struct {
+	Job      *Job
+	Started  time.Time
+	Duration time.Duration
+	// Attempts is how many times the function was called, see Retry.
+	Attempts int
+	// Drift is how late the run started after it was due, see Stats.
+	Drift time.Duration
+}
+
+// OnSuccess sets a function to call after each successful run, e.g. to send a
+// notification.
+func (j *Job) OnSuccess(f func(RunInfo)) *Job {
+	j.onSuccess = f
+	return j
+}
+
+// OnFailure sets a function to call with the error of each failed run, after
+// its attempts, see Retry, have all failed.
+func (j *Job) OnFailure(f func(RunInfo, error)) *Job {
+	j.onFailure = f
+	return j
+}
+
+// failed counts consecutive failed runs, which a nil error resets, and breaks
+// the circuit set with BreakAfter once there are too many.
+func (j *Job) failed(err error) {
+	j.Lock()
+	j.outcomes.add(err != nil)
+	j.lastErr = err
+	if err == nil {
+		j.failures = 0
+		j.Unlock()
+		return
+	}
+	j.record(func(s *Stats) { s.Failed++ })
+	j.addError(err)
+	j.failures++
+	broken := j.breakAt > 0 && j.failures >= j.breakAt
+	if broken {
+		j.failures = 0
+		j.paused = true
+		j.pauses++
+	}
+	pauses := j.pauses
+	j.Unlock()
+	j.report(err)
+	j.changed()
+
+	if !broken {
+		return
+	}
+	if j.onBreak != nil {
+		j.onBreak(err)
+	}
+	j.resumeIn(j.cooloff, pauses)
+}
+
+// resumeIn resumes the job after d, see resumeAfterBreak, unless d is zero.
+func (j *Job) resumeIn(d time.Duration, pauses int) {
+	if d <= 0 {
+		return
+	}
+	t := j.getClock().NewTimer(d)
+	go func() {
+		select {
+		case <-t.C():
+			j.resumeAfterBreak(pauses)
+		case <-j.ctx.Done():
+		}
+		t.Stop()
+	}()
+}
+
+// resumeAfterBreak resumes a job paused by its circuit breaker, unless it has
+// been paused or resumed by hand since.
+func (j *Job) resumeAfterBreak(pauses int) {
+	j.Lock()
+	if j.pauses == pauses {
+		j.paused = false
+	}
+	j.Unlock()
+	j.changed()
+}
+
+// sleep waits for d, or until ctx is done in which case it returns false.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Retry sets a job run with RunErr to make up to attempts attempts at each
+// run, waiting delay between them. The retries are part of the run: the next
+// scheduled run is skipped if it comes while they are still going on.
+func (j *Job) Retry(attempts int, delay time.Duration) *Job {
+	if j.err != nil {
+		return j
+	}
+	if attempts < 1 || delay < 0 {
+		j.err = errors.New("bad retry policy")
+		return j
+	}
+	j.attempts = attempts
+	j.delay = delay
+	return j
+}
+
+// OnExhausted sets a function to call with the last error of a run whose
+// attempts all failed, e.g. to hand the work to a dead-letter queue or raise
+// an alert.
+func (j *Job) OnExhausted(f func(lastErr error)) *Job {
+	j.exhausted = f
+	return j
+}
+
+// BreakAfter pauses a job run with RunErr once n runs in a row have failed,
+// for cooloff or, if it is zero, until Resume is called. A run fails when all
+// its attempts, see Retry, fail.
+func (j *Job) BreakAfter(n int, cooloff time.Duration) *Job {
+	if j.err != nil {
+		return j
+	}
+	if n < 1 || cooloff < 0 {
+		j.err = errors.New("bad circuit breaker")
+		return j
+	}
+	j.breakAt = n
+	j.cooloff = cooloff
+	return j
+}
+
+// OnBreak sets a function to call with the last error when the circuit set
+// with BreakAfter pauses the job.
+func (j *Job) OnBreak(f func(lastErr error)) *Job {
+	j.onBreak = f
+	return j
+}
+
+// Queue keeps up to size runs that are due while the previous one is still
+// executing, instead of skipping them, and starts them one after the other
+// once it finishes. Runs due while the queue is full are skipped and call
+// overflow, if not nil, which must not block.
+func (j *Job) Queue(size int, overflow func()) *Job {
+	if j.err != nil {
+		return j
+	}
+	if size < 0 {
+		j.err = errors.New("bad queue size")
+		return j
+	}
+	j.queue = size
+	j.overflow = overflow
+	return j
+}
+
+// SkipAfterOverrun skips the scheduled runs that are due while the previous
+// run is still executing, even if Queue would keep them, so a run that
+// overruns its slot is followed by the next one on schedule rather than by
+// one started late. f, if not nil, is called with the time each skipped run
+// was due, e.g. to log the overrun, and must not block. Runs requested with
+// Trigger are queued as usual.
+func (j *Job) SkipAfterOverrun(f func(due time.Time)) *Job {
+	j.skipLate = true
+	j.onOverrun = f
+	return j
+}
+
+func parseTime(str string) (hour, min, sec, nsec int, err error) {
 	chunks := strings.Split(str, ":")
-	var hourStr, minStr, secStr string
+	var hourStr, minStr, secStr, fracStr string
 	switch len(chunks) {
 	case 1:
 		hourStr = chunks[0]
@@ -219,36 +1286,73 @@ func parseTime(str string) (hour, min, sec int, err error) {
 		hourStr = chunks[0]
 		minStr = chunks[1]
 		secStr = chunks[2]
+		if i := strings.IndexByte(secStr, '.'); i >= 0 {
+			secStr, fracStr = secStr[:i], secStr[i+1:]
+			if !isFraction(fracStr) {
+				return 0, 0, 0, 0, errors.New("bad time")
+			}
+		}
 	}
 	hour, err = strconv.Atoi(hourStr)
 	if err != nil {
-		return 0, 0, 0, errors.New("bad time")
+		return 0, 0, 0, 0, errors.New("bad time")
 	}
 	min, err = strconv.Atoi(minStr)
 	if err != nil {
-		return 0, 0, 0, errors.New("bad time")
+		return 0, 0, 0, 0, errors.New("bad time")
 	}
 	sec, err = strconv.Atoi(secStr)
 	if err != nil {
-		return 0, 0, 0, errors.New("bad time")
+		return 0, 0, 0, 0, errors.New("bad time")
+	}
+	if fracStr != "" {
+		// Right-pad to nine digits so "5" reads as 500ms, not 5ns.
+		nsec, _ = strconv.Atoi(fracStr + strings.Repeat("0", 9-len(fracStr)))
 	}
 
-	if hour > 23 || min > 59 || sec > 59 {
-		return 0, 0, 0, errors.New("bad time")
+	if hour < 0 || min < 0 || sec < 0 || hour > 23 || min > 59 || sec > 59 {
+		return 0, 0, 0, 0, errors.New("bad time")
 	}
 
 	return
 }
 
+// isFraction reports whether str is one to nine decimal digits.
+func isFraction(str string) bool {
+	if str == "" || len(str) > 9 {
+		return false
+	}
+	for _, r := range str {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func (j *Job) dayOfWeek(d time.Weekday) *Job {
+	// A weekday following another starts a rota, with a time of its own.
+	if j.err == nil {
+		switch s := j.schedule.(type) {
+		case *weekly:
+			j.schedule = rota{s, &weekly{day: d, d: daily{loc: s.d.loc}}}
+			return j
+		case rota:
+			j.schedule = append(s, &weekly{day: d, d: daily{loc: s.clock().loc}})
+			return j
+		}
+	}
 	if j.schedule != nil {
 		j.err = errors.New("bad function chaining")
 	}
-	j.schedule = weekly{day: d}
+	j.schedule = &weekly{day: d}
 	return j
 }
 
-// Monday sets the job to run every Monday.
+// Monday sets the job to run every Monday. Following another weekday, it adds
+// Monday to the days the job runs on, at a time of its own, as in
+// Every().Monday().At("08:00").Friday().At("14:00"). The same goes for the
+// other weekdays.
 func (j *Job) Monday() *Job {
 	return j.dayOfWeek(time.Monday)
 }
@@ -288,15 +1392,32 @@ func (j *Job) Day() *Job {
 	if j.schedule != nil {
 		j.err = errors.New("bad function chaining")
 	}
-	j.schedule = daily{}
+	j.schedule = &daily{}
 	return j
 }
 
+// maxInterval bounds the intervals of Every(n) in seconds, minutes or hours.
+// Longer ones are mistakes, mostly durations given as counts.
+const maxInterval = 10 * 365 * 24 * time.Hour
+
 func (j *Job) timeOfDay(d time.Duration) *Job {
 	if j.err != nil {
 		return j
 	}
-	r := j.schedule.(*recurrent)
+	r, ok := j.schedule.(*recurrent)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if r.units > int(maxInterval/d) {
+		// Durations handed to Every are mostly from a millisecond to a year.
+		if n := time.Duration(r.units); n >= time.Millisecond && n <= 366*24*time.Hour {
+			j.err = fmt.Errorf("Every(%d) looks like a time.Duration, use EveryDuration(%v) instead", r.units, n)
+		} else {
+			j.err = errors.New("interval too long")
+		}
+		return j
+	}
 	r.period = d
 	j.schedule = r
 	return j
@@ -319,6 +1440,398 @@ func (j *Job) Hours() *Job {
 	return j.timeOfDay(time.Hour)
 }
 
+// NextN returns the next n times the job is scheduled to run, without
+// affecting its schedule. For a job that has not been started yet, the times
+// are those it would have if Run was called now. It returns fewer times if the
+// schedule ends, and none if the job is not correctly defined.
+func (j *Job) NextN(n int) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+	var times []time.Time
+	j.upcoming(func(date time.Time) bool {
+		times = append(times, date)
+		return len(times) < n
+	})
+	return times
+}
+
+// upcoming calls fn with each upcoming run time, as returned by NextN, until it
+// returns false or the schedule ends.
+func (j *Job) upcoming(fn func(time.Time) bool) {
+	j.RLock()
+	date, schedule := j.nextAt, j.schedule
+	j.RUnlock()
+	if j.err != nil || schedule == nil {
+		return
+	}
+	if date.IsZero() {
+		var err error
+		date, err = j.firstRun()
+		if err != nil {
+			return
+		}
+	}
+	for fn(date) {
+		next, err := schedule.next(date)
+		if err == nil {
+			next, err = j.offHoliday(schedule, next)
+		}
+		if err != nil {
+			return
+		}
+		date = next
+	}
+}
+
+// firstRun returns when the job would first run if started now.
+func (j *Job) firstRun() (time.Time, error) {
+	now := j.now()
+	if r, ok := j.schedule.(*recurrent); ok {
+		if r.units == 0 || r.period == 0 {
+			return time.Time{}, errors.New("cannot set recurrent time with 0")
+		}
+		if !r.done {
+			return j.offHoliday(j.schedule, now)
+		}
+	}
+	date, err := j.schedule.next(now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return j.offHoliday(j.schedule, date)
+}
+
+// Pause stops the job from running until Resume is called. Its schedule keeps
+// going: runs due while paused, including those requested through SkipWait,
+// are skipped rather than delayed.
+func (j *Job) Pause() {
+	j.pause()
+	j.audit("", AuditPause, "")
+}
+
+func (j *Job) pause() {
+	j.Lock()
+	j.paused = true
+	j.pauses++
+	j.Unlock()
+	j.changed()
+}
+
+// Resume lets a paused job run again from its next scheduled time.
+func (j *Job) Resume() {
+	j.resume()
+	j.audit("", AuditResume, "")
+}
+
+func (j *Job) resume() {
+	j.Lock()
+	j.paused = false
+	j.pauses++
+	j.Unlock()
+	j.changed()
+}
+
+// IsPaused returns if the job is paused.
+func (j *Job) IsPaused() bool {
+	j.RLock()
+	defer j.RUnlock()
+	return j.paused
+}
+
+// ErrStopped is the cause of jobs stopped with Stop, see StopCause.
+var ErrStopped = errors.New("job stopped")
+
+// Stop stops the job for good, like sending on Quit, but never blocks. A run
+// in progress is not interrupted. Its cause is ErrStopped.
+func (j *Job) Stop() {
+	j.StopWithCause(ErrStopped)
+}
+
+// CancelCurrentRun cancels the context of the job's run in progress, if any,
+// without stopping the job, which goes on with its next run as scheduled.
+// Only functions that heed their context, such as those of RunCtx and RunErr,
+// are interrupted; it also stops the retries of the run. It returns false if
+// no run was in progress.
+func (j *Job) CancelCurrentRun() bool {
+	j.RLock()
+	abort := j.abort
+	j.RUnlock()
+	if abort == nil {
+		return false
+	}
+	abort()
+	return true
+}
+
+// StopWithCause works like Stop and records why the job was stopped, e.g.
+// ErrShutdown or an error of the caller's own, to be read with StopCause. Only
+// the first cause of a job is kept. A nil cause is taken as ErrStopped.
+func (j *Job) StopWithCause(cause error) {
+	if cause == nil {
+		cause = ErrStopped
+	}
+	j.setCause(cause)
+	select {
+	case j.Quit <- true:
+	default:
+	}
+}
+
+// StopCause returns why the job was stopped: the cause given to
+// StopWithCause, ErrStopped if it was stopped with Stop or Quit, or the scheduling
+// error that stopped it. It returns nil while the job has not been stopped,
+// including once a schedule such as Once's has no more runs.
+func (j *Job) StopCause() error {
+	j.RLock()
+	defer j.RUnlock()
+	return j.cause
+}
+
+func (j *Job) setCause(cause error) {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.cause == nil {
+		j.cause = cause
+	}
+}
+
+// Trigger requests a run now, like sending on SkipWait, but never blocks. It
+// returns false if a run was already requested and not yet started, or the job
+// has not been started.
+func (j *Job) Trigger() bool {
+	select {
+	case j.SkipWait <- true:
+		j.audit("", AuditTrigger, "")
+		return true
+	default:
+		return false
+	}
+}
+
+// TriggerWith works like Trigger and passes payload to the run it requests,
+// whose function reads it from its context with Payload.
+func (j *Job) TriggerWith(payload []byte) bool {
+	if !j.triggerWith(payload) {
+		return false
+	}
+	j.audit("", AuditTrigger, "")
+	return true
+}
+
+func (j *Job) triggerWith(payload []byte) bool {
+	j.Lock()
+	defer j.Unlock()
+
+	select {
+	case j.SkipWait <- true:
+		j.payload = payload
+		return true
+	default:
+		return false
+	}
+}
+
+type payloadKey struct{}
+
+// Payload returns the payload given to TriggerWith for the run with context
+// ctx, or nil if there is none.
+func Payload(ctx context.Context) []byte {
+	payload, _ := ctx.Value(payloadKey{}).([]byte)
+	return payload
+}
+
+type runKey struct{}
+
+// RunKey returns an identifier of the run with context ctx, made of the job's
+// ID, see WithID, or else its name, and the time the run was due, or started
+// if it was triggered or queued. It is the same for every attempt of a run,
+// see Retry, and in every process that runs it for the same time, so job
+// functions can use it as an idempotency key to deduplicate side effects. It
+// returns "" if ctx is not the context of a run.
+func RunKey(ctx context.Context) string {
+	key, _ := ctx.Value(runKey{}).(string)
+	return key
+}
+
+// withRunKey returns ctx with the key of a run due at due, or started now if
+// due is zero. Reruns of crashed runs keep their key instead, see Resume.
+func (j *Job) withRunKey(ctx context.Context, due time.Time) context.Context {
+	j.RLock()
+	defer j.RUnlock()
+
+	id := j.id
+	if id == "" {
+		id = j.name
+	}
+	if due.IsZero() {
+		due = j.startedAt
+	}
+	return context.WithValue(ctx, runKey{}, id+"@"+due.UTC().Format(time.RFC3339Nano))
+}
+
+// Reschedule replaces the schedule of a running job without stopping it or
+// interrupting a run in progress: the job goes on as if it had been started
+// now with the new schedule. The schedule may be built like any other job's,
+// e.g. j.Reschedule(scheduler.Every(2).Hours().NotImmediately()). It returns
+// once the job's next run follows the new schedule. Inline jobs cannot
+// reschedule themselves from their own function.
+func (j *Job) Reschedule(s Schedule) error {
+	if err := j.reschedule(s); err != nil {
+		return err
+	}
+	j.audit("", AuditReschedule, humanize(s))
+	return nil
+}
+
+func (j *Job) reschedule(s Schedule) error {
+	schedule, err := scheduleOf(s)
+	if err != nil {
+		return err
+	}
+	if _, err := schedule.next(j.now()); err != nil {
+		return err
+	}
+	if j.changes == nil {
+		j.Lock()
+		j.schedule = schedule
+		j.Unlock()
+		return nil
+	}
+	c := change{schedule: schedule, done: make(chan struct{})}
+	select {
+	case j.changes <- c:
+	case <-j.ctx.Done():
+		return errors.New("job stopped")
+	}
+	select {
+	case <-c.done:
+	case <-j.ctx.Done():
+	}
+	return nil
+}
+
+// change is a schedule handed to the scheduling goroutine of a job, which
+// closes done, if any, once the job's next run follows it.
+type change struct {
+	schedule scheduled
+	done     chan struct{}
+}
+
+// scheduleOf returns the schedule that On would give a job. The schedule of a
+// job given as s is copied, see cloneSchedule, so the job can go on using it.
+func scheduleOf(s Schedule) (scheduled, error) {
+	if j, ok := s.(*Job); ok {
+		j.RLock()
+		defer j.RUnlock()
+
+		if j.err != nil {
+			return nil, j.err
+		}
+		if j.schedule == nil {
+			return nil, errors.New("nil schedule")
+		}
+		return cloneSchedule(j.schedule), nil
+	}
+	j := On(s)
+	return j.schedule, j.err
+}
+
+// current returns the job's schedule, which Reschedule may change while the job
+// runs.
+func (j *Job) current() scheduled {
+	j.RLock()
+	defer j.RUnlock()
+
+	return j.schedule
+}
+
+// Stats counts what happened to the runs of a job.
+type Stats struct {
+	// Runs is the number of runs started.
+	Runs int
+	// Skipped is the number of runs not started because the job was paused,
+	// its previous run was still executing, or its scheduler was on standby
+	// or left the run to another process or node.
+	Skipped int
+	// Failed is the number of runs of RunErr functions that failed, after
+	// exhausting their attempts.
+	Failed int
+	// Drift is how late the last run started after the time it was due, and
+	// MaxDrift the most any run did. Growing drift is an early sign of an
+	// overloaded scheduler or host. Runs triggered or queued do not count.
+	Drift    time.Duration
+	MaxDrift time.Duration
+	// Finished is the number of runs whose function returned, and
+	// TotalDuration and MaxDuration how long they took in all and at most.
+	// Runs that were skipped by a Locker do not count.
+	Finished      int
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+	// P50, P95 and P99 are the median and the 95th and 99th percentiles of
+	// the durations of the finished runs, estimated within 1% of their value.
+	P50 time.Duration
+	P95 time.Duration
+	P99 time.Duration
+	// Stalled is the number of times a run went longer than StallAfter
+	// allows without a heartbeat.
+	Stalled int
+}
+
+// AvgDuration returns how long the finished runs took on average, or 0 if
+// none has finished.
+func (s Stats) AvgDuration() time.Duration {
+	if s.Finished == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Finished)
+}
+
+// SuccessRate returns the fraction of the finished runs that did not fail,
+// from 0 to 1, or 1 if none has finished.
+func (s Stats) SuccessRate() float64 {
+	if s.Finished == 0 {
+		return 1
+	}
+	return float64(s.Finished-s.Failed) / float64(s.Finished)
+}
+
+// Stats returns the job's counters so far.
+func (j *Job) Stats() Stats {
+	j.RLock()
+	defer j.RUnlock()
+	stats := j.stats
+	j.durations.percentiles(&stats)
+	return stats
+}
+
+// Checkpoint starts the stats returned by SinceCheckpoint afresh, e.g. at the
+// start of each reporting period. Stats is not affected.
+func (j *Job) Checkpoint() {
+	j.Lock()
+	defer j.Unlock()
+	j.since = Stats{}
+	j.sinceDur = sketch{}
+}
+
+// SinceCheckpoint returns the job's counters since the last call to
+// Checkpoint, or since it was created if there was none.
+func (j *Job) SinceCheckpoint() Stats {
+	j.RLock()
+	defer j.RUnlock()
+	stats := j.since
+	j.sinceDur.percentiles(&stats)
+	return stats
+}
+
+// record applies f to the job's stats, see Stats and SinceCheckpoint. The
+// caller must hold the job's lock.
+func (j *Job) record(f func(s *Stats)) {
+	f(&j.stats)
+	f(&j.since)
+}
+
 // IsRunning returns if the job is currently running
 func (j *Job) IsRunning() bool {
 	j.RLock()
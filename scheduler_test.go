@@ -1,7 +1,14 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"runtime/pprof"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -76,10 +83,33 @@ func TestCancelExecution(t *testing.T) {
 	}
 }
 
+func TestCancelCurrentRun(t *testing.T) {
+	started := make(chan bool)
+	job, err := Every(1).Hours().RunCtx(func(ctx context.Context) {
+		started <- true
+		<-ctx.Done()
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	<-started
+	assert.True(t, job.CancelCurrentRun())
+	result := <-job.Done()
+	assert.Nil(t, result.Err)
+	assert.False(t, job.CancelCurrentRun())
+	assert.Nil(t, job.StopCause())
+
+	job.Trigger()
+	<-started
+	assert.True(t, job.CancelCurrentRun())
+	<-job.Done()
+	assert.Equal(t, 2, job.Stats().Finished)
+}
+
 func testDay(t *testing.T, job *Job, err error, date time.Time, hour, min, sec int) {
 	assert.Nil(t, err)
 
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	runTime := time.Now().Add(actual)
 	assert.Equal(t, date.Day(), runTime.Day())
@@ -119,7 +149,7 @@ func TestEveryAtHour(t *testing.T) {
 	hourStr := "08"
 	job, err := Every().Day().At(hourStr).Run(test)
 	assert.Nil(t, err)
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	runTime := time.Now().Add(actual)
 	assert.Equal(t, 8, runTime.Hour())
@@ -131,7 +161,7 @@ func TestEveryAtHourMin(t *testing.T) {
 	hourStr := "08:30"
 	job, err := Every().Day().At(hourStr).Run(test)
 	assert.Nil(t, err)
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	runTime := time.Now().Add(actual)
 	assert.Equal(t, 8, runTime.Hour())
@@ -140,7 +170,7 @@ func TestEveryAtHourMin(t *testing.T) {
 }
 
 func testWeekday(t *testing.T, job *Job, weekday time.Weekday) {
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	runTime := time.Now().Add(actual)
 	assert.Equal(t, weekday, runTime.Weekday())
@@ -158,7 +188,7 @@ func TestEveryAtWeeklyAfter(t *testing.T) {
 	hourStr := fmt.Sprintf("%v:%v:%v", h, m, s)
 	job, err := Every().Monday().At(hourStr).Run(test)
 	assert.Nil(t, err)
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	runTime := time.Now().Add(actual)
 	assert.Equal(t, time.Monday, runTime.Weekday())
@@ -175,7 +205,7 @@ func TestEveryAtWeeklyBefore(t *testing.T) {
 	hourStr := fmt.Sprintf("%v:%v:%v", h, m, s)
 	job, err := Every().Monday().At(hourStr).Run(test)
 	assert.Nil(t, err)
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	runTime := time.Now().Add(actual)
 	assert.Equal(t, time.Monday, runTime.Weekday())
@@ -287,12 +317,12 @@ func TestEverySunday(t *testing.T) {
 }
 
 func testEveryX(t *testing.T, job *Job, expected time.Duration, immediate bool) {
-	actual, err := job.schedule.nextRun()
+	actual, err := job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	if immediate {
 		assert.Equal(t, time.Duration(0), actual)
 	}
-	actual, err = job.schedule.nextRun()
+	actual, err = job.schedule.nextRun(time.Now())
 	assert.Nil(t, err)
 	assert.Equal(t, expected, actual)
 }
@@ -336,3 +366,841 @@ func TestBadRecurrent(t *testing.T) {
 	assert.Nil(t, job)
 	assert.NotNil(t, err)
 }
+
+func TestRunInline(t *testing.T) {
+	var mu sync.Mutex
+	runs := 0
+	fn := func() {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+	}
+	job, err := Every(1).Seconds().RunInline(fn)
+	assert.Nil(t, err)
+	assert.NotNil(t, job)
+
+	time.Sleep(10 * time.Millisecond)
+	job.SkipWait <- true
+	time.Sleep(120 * time.Millisecond)
+	job.Quit <- true
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, runs)
+}
+
+func TestSkipWaitRearmsTimer(t *testing.T) {
+	c := make(chan time.Time, 10)
+	fn := func() {
+		c <- time.Now()
+	}
+	job, err := Every(1).Seconds().Run(fn)
+	assert.Nil(t, err)
+	<-c
+	time.Sleep(500 * time.Millisecond)
+	skipped := time.Now()
+	job.SkipWait <- true
+	<-c
+	fired := <-c
+	job.Quit <- true
+	assert.True(t, fired.Sub(skipped) >= 900*time.Millisecond)
+}
+
+func TestEveryAtFractionalSeconds(t *testing.T) {
+	job, err := Every().Day().At("08:30:15.5").Run(test)
+	assert.Nil(t, err)
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual)
+	assert.Equal(t, 15, runTime.Second())
+	assert.Equal(t, 500*time.Millisecond, time.Duration(runTime.Nanosecond()).Round(time.Millisecond))
+
+	job, err = Every().Monday().At("08:30:15.025").Run(test)
+	assert.Nil(t, err)
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2015, 6, 8, 8, 30, 15, 25000000, time.Local), job.Next(from))
+	assert.Equal(t, "every Monday at 08:30:15.025", job.Description())
+}
+
+func TestBadAtFraction(t *testing.T) {
+	for _, str := range []string{"08:30.5", "08:30:15.", "08:30:15.1234567890", "08:30:15.-5", "08:30:15.5a"} {
+		job, err := Every().Day().At(str).Run(test)
+		assert.Nil(t, job, str)
+		assert.NotNil(t, err, str)
+	}
+}
+
+type everyOtherHour struct{}
+
+func (everyOtherHour) Next(after time.Time) time.Time {
+	return after.Truncate(2 * time.Hour).Add(2 * time.Hour)
+}
+
+func TestOnCustomSchedule(t *testing.T) {
+	job, err := On(everyOtherHour{}).Run(test)
+	assert.Nil(t, err)
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual)
+	assert.Equal(t, 0, runTime.Minute())
+	assert.True(t, actual <= 2*time.Hour)
+	assert.Equal(t, "custom schedule", job.Description())
+}
+
+func TestOnOwnSchedule(t *testing.T) {
+	s, err := ParseSchedule("every 5m")
+	assert.Nil(t, err)
+	job := On(s)
+	testEveryX(t, job, 5*time.Minute, true)
+}
+
+func TestOnNil(t *testing.T) {
+	job, err := On(nil).Run(test)
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+}
+
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// NewTimer returns a timer that does not fire during a test.
+func (fixedClock) NewTimer(time.Duration) Timer {
+	return realClock{}.NewTimer(time.Hour)
+}
+
+func TestNextN(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local)}
+
+	job := Every().Day().At("08:30").WithClock(clock)
+	assert.Equal(t, []time.Time{
+		time.Date(2015, 6, 4, 8, 30, 0, 0, time.Local),
+		time.Date(2015, 6, 5, 8, 30, 0, 0, time.Local),
+		time.Date(2015, 6, 6, 8, 30, 0, 0, time.Local),
+	}, job.NextN(3))
+
+	job = Every(2).Hours().WithClock(clock)
+	assert.Equal(t, []time.Time{clock.t, clock.t.Add(2 * time.Hour)}, job.NextN(2))
+	assert.Equal(t, []time.Time{clock.t.Add(2 * time.Hour)}, Every(2).Hours().NotImmediately().WithClock(clock).NextN(1))
+
+	// NextN has no side effects: the job still runs immediately.
+	testEveryX(t, job, 2*time.Hour, true)
+}
+
+func TestNextNRunning(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local)}
+	job, err := Every(2).Hours().NotImmediately().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer func() { job.Quit <- true }()
+	assert.Equal(t, []time.Time{clock.t.Add(2 * time.Hour), clock.t.Add(4 * time.Hour)}, job.NextN(2))
+}
+
+func TestNextNEnds(t *testing.T) {
+	assert.Nil(t, Every(0).Hours().NextN(2))
+	assert.Nil(t, Every(1).Day().NextN(2))
+	assert.Nil(t, Every().Day().NextN(0))
+	assert.Len(t, On(Union()).NextN(3), 0)
+}
+
+func TestStopNotRunning(t *testing.T) {
+	job := Every(1).Hours()
+	job.Stop()
+	job.Pause()
+	assert.True(t, job.IsPaused())
+	job.Resume()
+	assert.False(t, job.IsPaused())
+}
+
+func TestStopCause(t *testing.T) {
+	job, err := Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	assert.Nil(t, job.StopCause())
+	operator := errors.New("stopped by operator")
+	job.StopWithCause(operator)
+	job.Stop()
+	assert.Equal(t, operator, job.StopCause(), "the first cause is kept")
+
+	job, err = Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	job.Stop()
+	assert.Equal(t, ErrStopped, job.StopCause())
+
+	s := NewScheduler()
+	job, err = s.Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	assert.Nil(t, s.Shutdown(time.Second))
+	assert.Equal(t, ErrShutdown, job.StopCause())
+}
+
+func TestDone(t *testing.T) {
+	fail := errors.New("boom")
+	calls := 0
+	job, err := Every(1).Hours().NotImmediately().RunErr(func(context.Context) error {
+		calls++
+		if calls == 2 {
+			return fail
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	done := job.Done()
+	assert.Equal(t, done, job.Done())
+
+	job.SkipWait <- true
+	result := <-done
+	assert.Nil(t, result.Err)
+	assert.Equal(t, job, result.Job)
+	assert.Equal(t, 1, result.Attempts)
+	assert.False(t, job.IsRunning(), "sent once the run is over")
+
+	job.SkipWait <- true
+	result = <-done
+	assert.Equal(t, fail, result.Err)
+}
+
+func TestDoneKeepsNewest(t *testing.T) {
+	job := Every(1).Hours()
+	done := job.Done()
+	job.notify(RunResult{Err: errors.New("old")})
+	job.notify(RunResult{Err: errors.New("new")})
+	assert.EqualError(t, (<-done).Err, "new")
+}
+
+func TestRunCtxCanceledOnStop(t *testing.T) {
+	started := make(chan bool)
+	canceled := make(chan bool)
+	job, err := Every(1).Hours().RunCtx(func(ctx context.Context) {
+		started <- true
+		<-ctx.Done()
+		canceled <- true
+	})
+	assert.Nil(t, err)
+	<-started
+	job.Stop()
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("context was not canceled")
+	}
+}
+
+func TestDeadlineAtNextRun(t *testing.T) {
+	deadlines := make(chan time.Time, 1)
+	job, err := Every(1).Hours().DeadlineAtNextRun().RunCtx(func(ctx context.Context) {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		deadlines <- deadline
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.WithinDuration(t, time.Now().Add(time.Hour), <-deadlines, time.Second)
+
+	job, err = Every(1).Hours().RunCtx(func(ctx context.Context) {
+		_, ok := ctx.Deadline()
+		assert.False(t, ok)
+		deadlines <- time.Time{}
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	<-deadlines
+}
+
+func TestStatsSkipped(t *testing.T) {
+	release := make(chan bool)
+	job, err := Every(1).Hours().Run(func() { <-release })
+	assert.Nil(t, err)
+	defer job.Stop()
+	for !job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	job.SkipWait <- true
+	job.Pause()
+	close(release)
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	job.SkipWait <- true
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, Stats{Runs: 1, Skipped: 2}, counters(job.Stats()))
+}
+
+// counters returns the counts of runs started, skipped and failed, without
+// the drift and durations, which vary from run to run.
+func counters(s Stats) Stats {
+	s.Drift, s.MaxDrift = 0, 0
+	s.Finished, s.TotalDuration, s.MaxDuration = 0, 0, 0
+	s.P50, s.P95, s.P99 = 0, 0, 0
+	return s
+}
+
+func TestQueue(t *testing.T) {
+	release := make(chan bool)
+	runs := make(chan bool, 10)
+	var overflows int32
+	job, err := Every(1).Hours().Queue(2, func() { atomic.AddInt32(&overflows, 1) }).Run(func() {
+		runs <- true
+		<-release
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	<-runs
+	for i := 0; i < 4; i++ {
+		job.SkipWait <- true
+	}
+	for atomic.LoadInt32(&overflows) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	<-runs
+	<-runs
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Len(t, runs, 0)
+	assert.Equal(t, Stats{Runs: 3, Skipped: 2}, counters(job.Stats()))
+}
+
+func TestSkipAfterOverrun(t *testing.T) {
+	release := make(chan bool)
+	overruns := make(chan time.Time, 10)
+	job, err := EveryDuration(50*time.Millisecond).Queue(1, nil).
+		SkipAfterOverrun(func(due time.Time) { overruns <- due }).
+		Run(func() { <-release })
+	assert.Nil(t, err)
+	defer job.Stop()
+	due := <-overruns
+	assert.False(t, due.IsZero())
+	close(release)
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 1, job.Stats().Runs)
+	assert.True(t, job.Stats().Skipped >= 1)
+}
+
+func TestBadQueue(t *testing.T) {
+	_, err := Every(1).Hours().Queue(-1, nil).Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestRetry(t *testing.T) {
+	var attempts int32
+	exhausted := make(chan error, 1)
+	job, err := Every(1).Hours().Retry(3, time.Millisecond).OnExhausted(func(err error) {
+		exhausted <- err
+	}).RunErr(func(ctx context.Context) error {
+		return fmt.Errorf("attempt %d", atomic.AddInt32(&attempts, 1))
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.EqualError(t, <-exhausted, "attempt 3")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	done := make(chan int32, 1)
+	var attempts int32
+	job, err := Every(1).Hours().Retry(5, 0).OnExhausted(func(error) {
+		t.Error("run exhausted its attempts")
+	}).RunErr(func(ctx context.Context) error {
+		if n := atomic.AddInt32(&attempts, 1); n < 2 {
+			return fmt.Errorf("attempt %d", n)
+		}
+		done <- attempts
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, int32(2), <-done)
+}
+
+func TestOnSuccessAndFailure(t *testing.T) {
+	successes := make(chan RunInfo, 1)
+	failures := make(chan error, 1)
+	var n int32
+	job, err := Every(1).Hours().NotImmediately().Retry(2, 0).
+		OnSuccess(func(info RunInfo) { successes <- info }).
+		OnFailure(func(info RunInfo, err error) {
+			assert.Equal(t, 2, info.Attempts)
+			failures <- err
+		}).
+		RunErr(func(ctx context.Context) error {
+			if atomic.AddInt32(&n, 1) <= 3 {
+				return errors.New("boom")
+			}
+			return nil
+		})
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	job.SkipWait <- true
+	assert.EqualError(t, <-failures, "boom")
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	job.SkipWait <- true
+	info := <-successes
+	assert.Equal(t, job, info.Job)
+	assert.Equal(t, 2, info.Attempts)
+	assert.False(t, info.Started.IsZero())
+	assert.Len(t, failures, 0)
+}
+
+func TestBadRetry(t *testing.T) {
+	for _, job := range []*Job{
+		Every(1).Hours().Retry(0, time.Second),
+		Every(1).Hours().Retry(1, -time.Second),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestBreakAfter(t *testing.T) {
+	broken := make(chan error, 1)
+	var runs int32
+	job, err := Every(1).Hours().BreakAfter(2, 50*time.Millisecond).OnBreak(func(err error) {
+		broken <- err
+	}).RunErr(func(ctx context.Context) error {
+		return fmt.Errorf("run %d", atomic.AddInt32(&runs, 1))
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	for atomic.LoadInt32(&runs) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	for job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, job.IsPaused())
+	job.SkipWait <- true
+	assert.EqualError(t, <-broken, "run 2")
+	assert.True(t, job.IsPaused())
+	for job.IsPaused() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBreakAfterUntilResumed(t *testing.T) {
+	job, err := Every(1).Hours().BreakAfter(1, 0).RunErr(func(ctx context.Context) error {
+		return fmt.Errorf("failed")
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	for !job.IsPaused() {
+		time.Sleep(time.Millisecond)
+	}
+	job.Resume()
+	assert.False(t, job.IsPaused())
+}
+
+func TestBadBreakAfter(t *testing.T) {
+	_, err := Every(1).Hours().BreakAfter(0, time.Second).Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestNoDrift(t *testing.T) {
+	start := time.Date(2015, 6, 3, 2, 0, 0, 0, time.UTC)
+	r := &recurrent{units: 24, period: time.Hour, anchored: true}
+	next, err := r.nextRun(start)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), next)
+	next, _ = r.nextRun(start.Add(3 * time.Second))
+	assert.Equal(t, 24*time.Hour-3*time.Second, next)
+	next, _ = r.nextRun(start.Add(24*time.Hour + 5*time.Second))
+	assert.Equal(t, 24*time.Hour-5*time.Second, next)
+	next, _ = r.nextRun(start.Add(50 * time.Hour))
+	assert.Equal(t, 22*time.Hour, next)
+
+	job := Every(1).Hours().NotImmediately().NoDrift()
+	next, _ = job.schedule.nextRun(start)
+	assert.Equal(t, time.Hour, next)
+	next, _ = job.schedule.nextRun(start.Add(time.Hour + time.Second))
+	assert.Equal(t, time.Hour-time.Second, next)
+
+	_, err = Every().Day().NoDrift().Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestAdaptiveInterval(t *testing.T) {
+	now := time.Date(2015, 6, 3, 2, 0, 0, 0, time.UTC)
+	interval := 5 * time.Second
+	job := Every(1).Minutes().AdaptiveInterval(func() time.Duration { return interval })
+	assert.Nil(t, job.err)
+	assert.Equal(t, "every 1m0s, adaptive", job.Description())
+	next, err := job.schedule.nextRun(now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), next)
+	next, _ = job.schedule.nextRun(now)
+	assert.Equal(t, 5*time.Second, next)
+	interval = 30 * time.Second
+	next, _ = job.schedule.nextRun(now)
+	assert.Equal(t, 30*time.Second, next)
+	interval = 0
+	next, _ = job.schedule.nextRun(now)
+	assert.Equal(t, time.Minute, next)
+
+	f := func() time.Duration { return time.Second }
+	assert.NotNil(t, Every(1).Minutes().AdaptiveInterval(nil).err)
+	assert.NotNil(t, Every().Day().AdaptiveInterval(f).err)
+	assert.NotNil(t, Every(1).Hours().NoDrift().AdaptiveInterval(f).err)
+	assert.NotNil(t, Every(1).Hours().AdaptiveInterval(f).AtMinute(15).err)
+}
+
+func TestOnlyOn(t *testing.T) {
+	// Friday 23:55.
+	now := time.Date(2015, 6, 5, 23, 55, 0, 0, time.UTC)
+	job := Every(10).Minutes().OnlyOn(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	assert.Nil(t, job.err)
+	assert.Equal(t, "every 10m0s on Monday, Tuesday, Wednesday, Thursday, Friday", job.Description())
+	next, err := job.schedule.nextRun(now)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), next)
+	next, _ = job.schedule.nextRun(now)
+	assert.Equal(t, 48*time.Hour+5*time.Minute, next, "resumes on Monday at midnight")
+	assert.Equal(t, time.Date(2015, 6, 8, 0, 0, 0, 0, time.UTC), job.Next(now))
+
+	// The first run waits for an allowed day too.
+	job = Every(1).Hours().OnlyOn(time.Saturday)
+	next, _ = job.schedule.nextRun(now)
+	assert.Equal(t, 5*time.Minute, next)
+
+	job = Every(1).Hours().AtMinute(30).OnlyOn(time.Monday)
+	assert.Equal(t, time.Date(2015, 6, 8, 0, 30, 0, 0, time.UTC), job.Next(now))
+
+	job = Every(1).Hours().NoDrift().OnlyOn(time.Friday)
+	start := time.Date(2015, 6, 4, 23, 20, 0, 0, time.UTC)
+	next, _ = job.schedule.nextRun(start)
+	assert.Equal(t, time.Hour, next, "first boundary on Friday")
+
+	assert.NotNil(t, Every(1).Minutes().OnlyOn().err)
+	assert.NotNil(t, Every(1).Minutes().OnlyOn(time.Weekday(7)).err)
+	assert.NotNil(t, Every().Day().OnlyOn(time.Monday).err)
+}
+
+func TestAtSecond(t *testing.T) {
+	job := Every(1).Minutes().AtSecond(30)
+	from := time.Date(2015, 6, 3, 12, 0, 10, 0, time.Local)
+	assert.Equal(t, time.Date(2015, 6, 3, 12, 0, 30, 0, time.Local), job.Next(from))
+	assert.Equal(t, time.Date(2015, 6, 3, 12, 1, 30, 0, time.Local), job.Next(from.Add(20*time.Second)))
+	next, err := job.schedule.nextRun(from)
+	assert.Nil(t, err)
+	assert.Equal(t, 20*time.Second, next)
+	assert.Equal(t, "every 1m0s at 30s past", job.Description())
+
+	job = Every(5).Minutes().AtSecond(0)
+	assert.Equal(t, time.Date(2015, 6, 3, 12, 5, 0, 0, time.Local), job.Next(from))
+}
+
+func TestAtMinute(t *testing.T) {
+	job := Every(2).Hours().AtMinute(15).AtSecond(30)
+	from := time.Date(2015, 6, 3, 12, 20, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2015, 6, 3, 14, 15, 30, 0, time.Local), job.Next(from))
+	assert.Equal(t, time.Date(2015, 6, 4, 0, 15, 30, 0, time.Local), job.Next(time.Date(2015, 6, 3, 22, 15, 30, 0, time.Local)))
+	assert.Equal(t, []time.Time{job.Next(time.Now()), job.Next(job.Next(time.Now()))}, job.NextN(2))
+}
+
+func TestBadPhase(t *testing.T) {
+	for _, job := range []*Job{
+		Every(30).Seconds().AtSecond(10),
+		Every(10).Minutes().AtMinute(5),
+		Every(1).Hours().AtSecond(60),
+		Every(1).Hours().AtMinute(-1),
+		Every(1).Hours().AtMinute(5).AtMinute(10),
+		Every().Day().AtSecond(10),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestEveryDuration(t *testing.T) {
+	job, err := EveryDuration(90 * time.Second).NotImmediately().Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	next, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 90*time.Second, next)
+	assert.Equal(t, "every 1m30s", job.Description())
+
+	_, err = EveryDuration(0).Run(test)
+	assert.NotNil(t, err)
+	_, err = EveryDuration(-time.Second).Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestEveryCount(t *testing.T) {
+	assert.Equal(t, "every 5s", Every(5).Seconds().Description())
+	assert.Equal(t, "every 27h46m40s", Every(100000).Seconds().Description())
+	assert.Equal(t, "every 555h33m20s", Every(2000000).Seconds().Description())
+	assert.EqualError(t, Every(int(5*time.Second)).Seconds().err,
+		"Every(5000000000) looks like a time.Duration, use EveryDuration(5s) instead")
+	assert.EqualError(t, Every(-1).Minutes().err, "negative interval in Every")
+	assert.EqualError(t, Every(-1).Seconds().NoDrift().err, "negative interval in Every")
+	assert.EqualError(t, Every(-1).Seconds().NotImmediately().err, "negative interval in Every")
+	assert.EqualError(t, Every(int(500*time.Millisecond)).Seconds().err,
+		"Every(500000000) looks like a time.Duration, use EveryDuration(500ms) instead")
+	assert.EqualError(t, Every(int(90*time.Minute)).Hours().err,
+		"Every(5400000000000) looks like a time.Duration, use EveryDuration(1h30m0s) instead")
+	assert.EqualError(t, Every(math.MaxInt64/60).Hours().err, "interval too long")
+	assert.EqualError(t, Every(100000).Hours().err, "interval too long")
+	assert.EqualError(t, Every().Seconds().err, "bad function chaining")
+}
+
+func TestEveryString(t *testing.T) {
+	job := EveryString("1h30m")
+	next, err := job.schedule.next(time.Time{})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Time{}.Add(90*time.Minute), next)
+
+	for _, str := range []string{"", "90", "1x", "-5m", "0s"} {
+		_, err := EveryString(str).Run(test)
+		assert.NotNil(t, err, str)
+	}
+}
+
+func TestAtTime(t *testing.T) {
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.Local)
+	job := Every().Day().AtTime(8, 35, 30)
+	assert.Equal(t, time.Date(2015, 6, 4, 8, 35, 30, 0, time.Local), job.Next(from))
+	assert.Equal(t, Every().Day().At("08:35:30").Description(), job.Description())
+
+	for _, job := range []*Job{
+		Every().Day().AtTime(24, 0, 0),
+		Every().Day().AtTime(8, -1, 0),
+		Every().Day().AtTime(8, 0, 60),
+		Every(1).Hours().AtTime(8, 0, 0),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestAtClock(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	job := Every().Monday().AtClock(time.Date(2000, 1, 1, 20, 15, 0, 500, loc))
+	from := time.Date(2015, 5, 31, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2015, 6, 1, 20, 15, 0, 500, loc), job.Next(from))
+
+	_, err := Every(1).Hours().AtClock(time.Now()).Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestTrigger(t *testing.T) {
+	assert.False(t, Every(1).Hours().Trigger())
+
+	c := make(chan bool, 2)
+	job, err := Every(1).Hours().NotImmediately().Run(func() { c <- true })
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.True(t, job.Trigger())
+	assert.True(t, <-c)
+}
+
+func TestReschedule(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)}
+	job, err := Every(1).Hours().NotImmediately().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, []time.Time{clock.t.Add(time.Hour)}, job.NextN(1))
+
+	assert.Nil(t, job.Reschedule(Every(10).Minutes().NotImmediately()))
+	assert.Equal(t, []time.Time{clock.t.Add(10 * time.Minute)}, job.NextN(1))
+	assert.Equal(t, "every 10m0s", job.Description())
+
+	assert.NotNil(t, job.Reschedule(Every(0).Minutes()))
+	assert.NotNil(t, job.Reschedule(On(Union())))
+	assert.NotNil(t, job.Reschedule(nil))
+	assert.Equal(t, "every 10m0s", job.Description())
+
+	job.Stop()
+	<-job.ctx.Done()
+	assert.EqualError(t, job.Reschedule(Every().Day()), "job stopped")
+}
+
+func TestRescheduleNotRunning(t *testing.T) {
+	job := Every(1).Hours()
+	assert.Nil(t, job.Reschedule(Every().Day().At("08:00")))
+	assert.Equal(t, "every day at 08:00:00", job.Description())
+}
+
+func TestTriggerWith(t *testing.T) {
+	payloads := make(chan []byte, 2)
+	job, err := Every(1).Hours().RunCtx(func(ctx context.Context) { payloads <- Payload(ctx) })
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Nil(t, <-payloads)
+
+	assert.True(t, job.TriggerWith([]byte("deploy 42")))
+	assert.Equal(t, []byte("deploy 42"), <-payloads)
+	assert.True(t, job.Trigger())
+	assert.Nil(t, <-payloads)
+}
+
+func TestRunKey(t *testing.T) {
+	keys := make(chan string, 4)
+	job := Every(1).Hours().WithClock(&tickingClock{}).Named("report").Retry(2, 0)
+	done := job.Done()
+	_, err := job.RunErr(func(ctx context.Context) error {
+		keys <- RunKey(ctx)
+		return errors.New("retry")
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	first := <-keys
+	assert.Regexp(t, `^report@2015-06-03T12:00:\d\dZ$`, first)
+	assert.Equal(t, first, <-keys)
+
+	<-done
+	job.Trigger()
+	second := <-keys
+	assert.Regexp(t, `^report@2015-06-03T12:00:\d\dZ$`, second)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, second, <-keys)
+
+	assert.Equal(t, "", RunKey(context.Background()))
+}
+
+func TestPprofLabels(t *testing.T) {
+	labels := make(chan [2]string, 2)
+	record := func(ctx context.Context) {
+		job, _ := pprof.Label(ctx, "job")
+		kind, _ := pprof.Label(ctx, "schedule")
+		labels <- [2]string{job, kind}
+	}
+	job, err := Every(1).Hours().Named("report").RunCtx(record)
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, [2]string{"report", "interval"}, <-labels)
+
+	job, err = Every().Day().At("08:00").RunCtx(record)
+	assert.Nil(t, err)
+	defer job.Stop()
+	job.SkipWait <- true
+	assert.Equal(t, [2]string{"every day at 08:00:00", "daily"}, <-labels)
+}
+
+func TestConcurrentUse(t *testing.T) {
+	s := NewScheduler()
+	job, err := s.Every(1).Seconds().Named("tick").Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+
+	var wg sync.WaitGroup
+	calls := []func(){
+		func() { job.Trigger() },
+		func() { job.TriggerWith([]byte("x")) },
+		func() { job.Pause(); job.Resume() },
+		func() { job.Reschedule(Every(2).Seconds()) },
+		func() { job.Named("tick") },
+		func() { _ = job.Description() + job.Name() },
+		func() { job.NextN(3); job.Stats(); job.State() },
+		func() { job.Healthy(); job.LastError(); job.Errors(5) },
+		func() { s.Job("tick"); s.Jobs(); s.Healthy() },
+		func() { s.DryRun(io.Discard, time.Second) },
+		func() { s.ExportICS(io.Discard, time.Second) },
+	}
+	for _, call := range calls {
+		wg.Add(1)
+		go func(call func()) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				call()
+			}
+		}(call)
+	}
+	wg.Wait()
+}
+
+// tickingClock moves on by a second every time it is read.
+type tickingClock struct {
+	ticks int64
+}
+
+func (c *tickingClock) Now() time.Time {
+	return time.Date(2015, 6, 3, 12, 0, int(atomic.AddInt64(&c.ticks, 1)), 0, time.UTC)
+}
+
+func (*tickingClock) NewTimer(d time.Duration) Timer {
+	return realClock{}.NewTimer(d)
+}
+
+func TestDrift(t *testing.T) {
+	infos := make(chan RunInfo, 2)
+	job, err := Every(1).Hours().WithClock(&tickingClock{}).OnSuccess(func(info RunInfo) { infos <- info }).Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.Equal(t, time.Second, (<-infos).Drift)
+	assert.Equal(t, time.Second, job.Stats().Drift)
+
+	job.SkipWait <- true
+	assert.Equal(t, time.Duration(0), (<-infos).Drift)
+	stats := job.Stats()
+	assert.Equal(t, 2, stats.Runs)
+	assert.Equal(t, time.Second, stats.Drift)
+	assert.Equal(t, time.Second, stats.MaxDrift)
+}
+
+// manualClock only moves on when told to, and its timers never fire.
+type manualClock struct {
+	elapsed int64
+}
+
+func (c *manualClock) Now() time.Time {
+	return time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC).Add(time.Duration(atomic.LoadInt64(&c.elapsed)))
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	atomic.AddInt64(&c.elapsed, int64(d))
+}
+
+func (*manualClock) NewTimer(time.Duration) Timer {
+	return realClock{}.NewTimer(time.Hour)
+}
+
+func TestStatsDurations(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Stats{}.AvgDuration())
+	assert.Equal(t, 1.0, Stats{}.SuccessRate())
+	stats := Stats{Runs: 4, Failed: 1, Finished: 4, TotalDuration: 10 * time.Second}
+	assert.Equal(t, 2500*time.Millisecond, stats.AvgDuration())
+	assert.Equal(t, 0.75, stats.SuccessRate())
+
+	durations := []time.Duration{time.Second, 3 * time.Second}
+	clock := &manualClock{}
+	fail := errors.New("boom")
+	job, err := Every(1).Hours().NotImmediately().WithClock(clock).RunErr(func(context.Context) error {
+		d := durations[0]
+		durations = durations[1:]
+		clock.Advance(d)
+		if d > time.Second {
+			return fail
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	done := job.Done()
+	job.SkipWait <- true
+	<-done
+	job.Checkpoint()
+	job.SkipWait <- true
+	<-done
+
+	stats = job.Stats()
+	assert.Equal(t, 2, stats.Finished)
+	assert.Equal(t, 4*time.Second, stats.TotalDuration)
+	assert.Equal(t, 3*time.Second, stats.MaxDuration)
+	assert.Equal(t, 2*time.Second, stats.AvgDuration())
+	assert.Equal(t, 0.5, stats.SuccessRate())
+	assert.InEpsilon(t, float64(time.Second), float64(stats.P50), 0.01)
+	assert.InEpsilon(t, float64(3*time.Second), float64(stats.P99), 0.01)
+	since := job.SinceCheckpoint()
+	assert.InEpsilon(t, float64(3*time.Second), float64(since.P50), 0.01)
+	since.P50, since.P95, since.P99 = 0, 0, 0
+	assert.Equal(t, Stats{Runs: 1, Failed: 1, Finished: 1, TotalDuration: 3 * time.Second, MaxDuration: 3 * time.Second}, since)
+}
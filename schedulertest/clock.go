@@ -0,0 +1,159 @@
+// Package schedulertest provides a virtual clock to test scheduled jobs
+// without waiting for real time to pass.
+//
+//  clock := schedulertest.NewClock(time.Date(2015, 6, 1, 0, 0, 0, 0, time.Local))
+//  job, _ := scheduler.Every().Day().At("08:30").WithClock(clock).Run(fn)
+//  clock.Advance(7 * 24 * time.Hour) // fn has run 7 times
+package schedulertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/carlescere/scheduler"
+)
+
+// Clock is a scheduler.Clock whose time only moves when Advance is called.
+type Clock struct {
+	now     time.Time
+	timers  []*timer
+	running int
+	mu      sync.Mutex
+	cond    *sync.Cond
+}
+
+// NewClock returns a virtual clock set to start.
+func NewClock(start time.Time) *Clock {
+	c := &Clock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current virtual time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// NewTimer implements scheduler.Clock.
+func (c *Clock) NewTimer(d time.Duration) scheduler.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &timer{clock: c, ch: make(chan time.Time, 1), when: c.now.Add(d), active: true, listed: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// RunStarted implements scheduler.RunTracker.
+func (c *Clock) RunStarted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running++
+}
+
+// RunFinished implements scheduler.RunTracker.
+func (c *Clock) RunFinished() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.running--
+	c.cond.Broadcast()
+}
+
+// Advance moves the clock forward by d. Every timer due within that period
+// fires in order, and Advance waits for the job to reschedule itself and for
+// the triggered run to complete before moving on, so when it returns all runs
+// due up to the new time have finished. Advance(0) fires timers that are
+// already due, such as the first run of a recurrent job.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	end := c.now.Add(d)
+	for {
+		t := c.nextDue(end)
+		if t == nil {
+			break
+		}
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		t.active = false
+		t.firing = true
+		select {
+		case t.ch <- c.now:
+		default:
+		}
+		for t.firing || c.running > 0 {
+			c.cond.Wait()
+		}
+	}
+	c.now = end
+}
+
+func (c *Clock) nextDue(end time.Time) *timer {
+	var due *timer
+	alive := c.timers[:0]
+	for _, t := range c.timers {
+		if t.stopped {
+			t.listed = false
+			continue
+		}
+		alive = append(alive, t)
+		if t.active && !t.when.After(end) && (due == nil || t.when.Before(due.when)) {
+			due = t
+		}
+	}
+	for i := len(alive); i < len(c.timers); i++ {
+		c.timers[i] = nil
+	}
+	c.timers = alive
+	return due
+}
+
+type timer struct {
+	clock   *Clock
+	ch      chan time.Time
+	when    time.Time
+	active  bool
+	firing  bool
+	stopped bool
+	listed  bool
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	was := t.active
+	t.active = false
+	t.firing = false
+	t.stopped = true
+	t.clock.cond.Broadcast()
+	return was
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	was := t.active
+	t.when = t.clock.now.Add(d)
+	t.active = true
+	t.firing = false
+	t.stopped = false
+	if !t.listed {
+		t.clock.timers = append(t.clock.timers, t)
+		t.listed = true
+	}
+	t.clock.cond.Broadcast()
+	return was
+}
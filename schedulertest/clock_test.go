@@ -0,0 +1,124 @@
+package schedulertest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	clock *Clock
+	runs  []time.Time
+	sync.Mutex
+}
+
+func (r *recorder) fn() {
+	r.Lock()
+	defer r.Unlock()
+	r.runs = append(r.runs, r.clock.Now())
+}
+
+func (r *recorder) count() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.runs)
+}
+
+var start = time.Date(2015, 6, 1, 0, 0, 0, 0, time.Local)
+
+func TestAdvanceRecurrent(t *testing.T) {
+	clock := NewClock(start)
+	r := &recorder{clock: clock}
+	job, err := scheduler.Every(1).Hours().WithClock(clock).Run(r.fn)
+	assert.Nil(t, err)
+	defer func() { job.Quit <- true }()
+
+	clock.Advance(0)
+	assert.Equal(t, 1, r.count())
+	clock.Advance(3 * time.Hour)
+	assert.Equal(t, 4, r.count())
+	assert.Equal(t, start.Add(3*time.Hour), r.runs[3])
+	assert.Equal(t, start.Add(3*time.Hour), clock.Now())
+}
+
+func TestAdvanceDaily(t *testing.T) {
+	clock := NewClock(start)
+	r := &recorder{clock: clock}
+	job, err := scheduler.Every().Day().At("08:30").WithClock(clock).Run(r.fn)
+	assert.Nil(t, err)
+	defer func() { job.Quit <- true }()
+
+	clock.Advance(8 * time.Hour)
+	assert.Equal(t, 0, r.count())
+	clock.Advance(7 * 24 * time.Hour)
+	assert.Equal(t, 7, r.count())
+	for i, run := range r.runs {
+		assert.Equal(t, time.Date(2015, 6, 1+i, 8, 30, 0, 0, time.Local), run)
+	}
+}
+
+func TestAdvanceWeekly(t *testing.T) {
+	clock := NewClock(start)
+	r := &recorder{clock: clock}
+	job, err := scheduler.Every().Sunday().At("20:00").WithClock(clock).Run(r.fn)
+	assert.Nil(t, err)
+	defer func() { job.Quit <- true }()
+
+	clock.Advance(28 * 24 * time.Hour)
+	assert.Equal(t, 4, r.count())
+	for _, run := range r.runs {
+		assert.Equal(t, time.Sunday, run.Weekday())
+		assert.Equal(t, 20, run.Hour())
+	}
+}
+
+func TestAdvanceWaitsForRun(t *testing.T) {
+	clock := NewClock(start)
+	done := false
+	fn := func() {
+		time.Sleep(20 * time.Millisecond)
+		done = true
+	}
+	job, err := scheduler.Every(1).Minutes().NotImmediately().WithClock(clock).Run(fn)
+	assert.Nil(t, err)
+	defer func() { job.Quit <- true }()
+
+	clock.Advance(time.Minute)
+	assert.True(t, done)
+}
+
+func TestAdvanceAfterQuit(t *testing.T) {
+	clock := NewClock(start)
+	r := &recorder{clock: clock}
+	job, err := scheduler.Every(1).Minutes().NotImmediately().WithClock(clock).Run(r.fn)
+	assert.Nil(t, err)
+
+	clock.Advance(time.Minute)
+	job.Quit <- true
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(time.Hour)
+	assert.Equal(t, 1, r.count())
+}
+
+func TestAdvanceBreakAfter(t *testing.T) {
+	clock := NewClock(time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC))
+	var runs int32
+	job, err := scheduler.Every(1).Minutes().BreakAfter(1, 10*time.Minute).WithClock(clock).RunErr(func(context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("failed")
+	})
+	assert.Nil(t, err)
+	defer job.Stop()
+	clock.Advance(0)
+	assert.True(t, job.IsPaused())
+	clock.Advance(10 * time.Minute)
+	assert.False(t, job.IsPaused())
+	clock.Advance(time.Minute)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&runs))
+}
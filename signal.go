@@ -0,0 +1,82 @@
+package scheduler
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// RunUntilSignal blocks until one of the given signals is received, SIGINT or
+// SIGTERM if none are given, and then shuts the scheduler down: every job is
+// stopped and runs in progress are given the scheduler's drain timeout to
+// finish. It returns the error of Shutdown.
+//
+//	s := scheduler.NewScheduler().WithDrainTimeout(time.Minute)
+//	s.Every(5).Minutes().Run(poll)
+//	if err := scheduler.RunUntilSignal(s); err != nil {
+//		log.Fatal(err)
+//	}
+func RunUntilSignal(s *Scheduler, sigs ...os.Signal) error {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	defer signal.Stop(c)
+
+	return waitAndShutdown(s, c)
+}
+
+func waitAndShutdown(s *Scheduler, c <-chan os.Signal) error {
+	<-c
+	s.Lock()
+	timeout := s.drainTimeout
+	s.Unlock()
+	return s.Shutdown(timeout)
+}
+
+// ReloadOn reloads the scheduler's jobs with the specs returned by load,
+// see Reload, whenever sig is received, following the convention of daemons
+// that re-read their configuration on SIGHUP:
+//
+//	stop := s.ReloadOn(syscall.SIGHUP, func() ([]scheduler.JobSpec, error) {
+//		return scheduler.LoadSpecs("/etc/myapp/jobs.json")
+//	})
+//	defer stop()
+//
+// Jobs whose spec did not change are left alone, and changed ones are updated
+// in place, so no run is missed or doubled by a reload. Errors of load or
+// Reload leave the jobs as they were and are logged with the scheduler's
+// default logger, see WithDefaultLogger. The returned function stops
+// listening for sig.
+func (s *Scheduler) ReloadOn(sig os.Signal, load func() ([]JobSpec, error)) func() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sig)
+	done := make(chan struct{})
+	go s.reloadOn(c, done, load)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+func (s *Scheduler) reloadOn(c <-chan os.Signal, done <-chan struct{}, load func() ([]JobSpec, error)) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-c:
+		}
+		specs, err := load()
+		if err == nil {
+			err = s.Reload(specs)
+		}
+		if err != nil {
+			s.logError("cannot reload job specs", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitAndShutdown(t *testing.T) {
+	s := NewScheduler().WithDrainTimeout(time.Second)
+	release := make(chan bool)
+	job, err := s.Every(1).Hours().Run(func() { <-release })
+	assert.Nil(t, err)
+	for !job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+
+	c := make(chan os.Signal, 1)
+	c <- os.Interrupt
+	done := make(chan error)
+	go func() { done <- waitAndShutdown(s, c) }()
+	select {
+	case <-done:
+		t.Fatal("shutdown did not wait for the running job")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	assert.Nil(t, <-done)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	s := NewScheduler()
+	release := make(chan bool)
+	defer close(release)
+	job, err := s.Every(1).Hours().Run(func() { <-release })
+	assert.Nil(t, err)
+	for !job.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NotNil(t, s.Shutdown(20*time.Millisecond))
+}
+
+func TestRunUntilSignal(t *testing.T) {
+	// Catch the signal here too, so it cannot kill the test binary before
+	// RunUntilSignal has installed its handler.
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, os.Interrupt)
+	defer signal.Stop(guard)
+
+	s := NewScheduler()
+	done := make(chan error)
+	go func() { done <- RunUntilSignal(s) }()
+	p, err := os.FindProcess(os.Getpid())
+	assert.Nil(t, err)
+	for {
+		select {
+		case err := <-done:
+			assert.Nil(t, err)
+			return
+		case <-time.After(10 * time.Millisecond):
+			p.Signal(os.Interrupt)
+		}
+	}
+}
+
+func TestReloadOn(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewScheduler(WithDefaultLogger(slog.New(slog.NewTextHandler(&buf, nil))))
+	s.RegisterFunc("sync", func(context.Context) error { return nil })
+	loads := make(chan []JobSpec)
+	load := func() ([]JobSpec, error) {
+		specs := <-loads
+		if specs == nil {
+			return nil, errors.New("bad config")
+		}
+		return specs, nil
+	}
+	c := make(chan os.Signal)
+	done := make(chan struct{})
+	defer close(done)
+	go s.reloadOn(c, done, load)
+
+	c <- syscall.SIGHUP
+	loads <- []JobSpec{{Name: "sync", Schedule: "daily 08:00", Func: "sync"}}
+	for s.Job("sync") == nil {
+		time.Sleep(time.Millisecond)
+	}
+	job := s.Job("sync")
+	defer job.Stop()
+
+	c <- syscall.SIGHUP
+	loads <- nil
+	c <- syscall.SIGHUP
+	loads <- []JobSpec{{Name: "sync", Schedule: "daily 08:00", Func: "sync"}}
+	assert.Contains(t, buf.String(), "bad config")
+	assert.Equal(t, []*Job{job}, s.Jobs())
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// sketchAccuracy is the relative error of the quantiles estimated by a sketch.
+const sketchAccuracy = 0.01
+
+// sketchGamma is the ratio between the bounds of consecutive buckets.
+var sketchGamma = (1 + sketchAccuracy) / (1 - sketchAccuracy)
+
+// sketch estimates quantiles of durations, within sketchAccuracy of their
+// value, in the manner of DDSketch: it counts durations in buckets whose
+// bounds grow geometrically, so it takes a few kilobytes at most whatever the
+// number of runs.
+type sketch struct {
+	counts map[int]uint64
+	zeros  uint64
+	n      uint64
+}
+
+func (s *sketch) add(d time.Duration) {
+	s.n++
+	if d <= 0 {
+		s.zeros++
+		return
+	}
+	if s.counts == nil {
+		s.counts = make(map[int]uint64)
+	}
+	s.counts[int(math.Ceil(math.Log(float64(d))/math.Log(sketchGamma)))]++
+}
+
+// quantile returns the estimated q-quantile, 0 <= q <= 1, of the durations
+// added by the nearest-rank method, or 0 if there are none.
+func (s *sketch) quantile(q float64) time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	rank := uint64(max(math.Ceil(q*float64(s.n))-1, 0))
+	if rank < s.zeros {
+		return 0
+	}
+	keys := make([]int, 0, len(s.counts))
+	for k := range s.counts {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	seen := s.zeros
+	for _, k := range keys {
+		seen += s.counts[k]
+		if seen > rank {
+			return time.Duration(2 * math.Pow(sketchGamma, float64(k)) / (sketchGamma + 1))
+		}
+	}
+	return 0
+}
+
+// percentiles sets the percentiles of stats from s.
+func (s *sketch) percentiles(stats *Stats) {
+	stats.P50 = s.quantile(0.50)
+	stats.P95 = s.quantile(0.95)
+	stats.P99 = s.quantile(0.99)
+}
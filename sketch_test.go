@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch(t *testing.T) {
+	var s sketch
+	assert.Equal(t, time.Duration(0), s.quantile(0.5))
+
+	r := rand.New(rand.NewSource(1))
+	durations := make([]time.Duration, 10000)
+	for i := range durations {
+		durations[i] = time.Duration(r.ExpFloat64() * float64(time.Second))
+		s.add(durations[i])
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	for _, q := range []float64{0, 0.5, 0.95, 0.99, 1} {
+		exact := durations[max(int(math.Ceil(q*float64(len(durations))))-1, 0)]
+		assert.InEpsilon(t, float64(exact), float64(s.quantile(q)), sketchAccuracy*1.01, "quantile %v", q)
+	}
+	assert.Less(t, len(s.counts), 2000)
+
+	s = sketch{}
+	s.add(0)
+	s.add(0)
+	s.add(time.Millisecond)
+	assert.Equal(t, time.Duration(0), s.quantile(0.5))
+	assert.InEpsilon(t, float64(time.Millisecond), float64(s.quantile(1)), sketchAccuracy)
+}
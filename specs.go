@@ -0,0 +1,149 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// JobSpec describes a job to be added with AddAll, as loaded from
+// configuration.
+type JobSpec struct {
+	// Name names the job, and must be unique within the scheduler.
+	Name string `json:"name"`
+	// Schedule is written as for ParseSchedule, or else for Cron.
+	Schedule string `json:"schedule"`
+	// Tags are the names of the groups the job is added to, see Group.
+	Tags []string `json:"tags,omitempty"`
+	// Func is the key of the function the job runs, see RegisterFunc.
+	Func string `json:"func"`
+}
+
+// SpecError is the error of one of the specs given to AddAll.
+type SpecError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+func (e *SpecError) Error() string {
+	return "job spec " + strconv.Itoa(e.Index) + " (" + strconv.Quote(e.Name) + "): " + e.Err.Error()
+}
+
+// Unwrap returns the error of the spec.
+func (e *SpecError) Unwrap() error {
+	return e.Err
+}
+
+// RegisterFunc makes f available to job specs under key, see AddAll.
+func (s *Scheduler) RegisterFunc(key string, f func(ctx context.Context) error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.funcs == nil {
+		s.funcs = make(map[string]func(context.Context) error)
+	}
+	s.funcs[key] = f
+}
+
+// AddAll creates and runs a named job for each spec, all or none of them: it
+// checks every spec first and returns the problems of all the bad ones, each
+// as a *SpecError, joined in a single error, without starting any job. A spec
+// is bad if its name is empty or taken, its schedule cannot be parsed or never
+// runs, or its function was not registered with RegisterFunc.
+func (s *Scheduler) AddAll(specs []JobSpec) ([]*Job, error) {
+	schedules, err := s.checkSpecs(specs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(specs))
+	for i, spec := range specs {
+		j, err := s.startSpec(spec, schedules[i])
+		if err != nil {
+			for _, j := range jobs {
+				j.Stop()
+			}
+			s.unregister(jobs...)
+			return nil, &SpecError{Index: i, Name: spec.Name, Err: err}
+		}
+		jobs = append(jobs, j)
+	}
+	for i, spec := range specs {
+		for _, tag := range spec.Tags {
+			s.Group(tag).Add(jobs[i])
+		}
+		s.manage(spec, jobs[i])
+	}
+	return jobs, nil
+}
+
+// checkSpecs parses the schedules of specs and returns the problems of the bad
+// ones, see AddAll. The names of the jobs in free may be taken again.
+func (s *Scheduler) checkSpecs(specs []JobSpec, free []*Job) ([]scheduled, error) {
+	names := make(map[string]bool)
+	for _, j := range s.snapshot() {
+		if name := j.Name(); name != "" && !contains(free, j) {
+			names[name] = true
+		}
+	}
+	s.Lock()
+	funcs := s.funcs
+	s.Unlock()
+
+	var errs []error
+	schedules := make([]scheduled, len(specs))
+	for i, spec := range specs {
+		fail := func(err error) {
+			errs = append(errs, &SpecError{Index: i, Name: spec.Name, Err: err})
+		}
+		switch {
+		case spec.Name == "":
+			fail(errors.New("empty job name"))
+		case names[spec.Name]:
+			fail(errors.New("duplicate job name"))
+		}
+		names[spec.Name] = true
+		if funcs[spec.Func] == nil {
+			fail(errors.New("unknown function " + strconv.Quote(spec.Func)))
+		}
+		schedule, err := parseSpec(spec.Schedule)
+		if err == nil {
+			_, err = schedule.next(time.Now())
+		}
+		if err != nil {
+			fail(err)
+		}
+		schedules[i] = schedule
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return schedules, nil
+}
+
+// startSpec runs the job of a checked spec, without its tags.
+func (s *Scheduler) startSpec(spec JobSpec, schedule scheduled) (*Job, error) {
+	return s.bind(&Job{schedule: schedule}).Named(spec.Name).RunErr(s.funcOf(spec.Func))
+}
+
+// funcOf returns the function registered under key.
+func (s *Scheduler) funcOf(key string) func(context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.funcs[key]
+}
+
+// parseSpec parses the schedule of a JobSpec.
+func parseSpec(spec string) (scheduled, error) {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		var cronErr error
+		if schedule, cronErr = Cron(spec); cronErr != nil {
+			return nil, err
+		}
+	}
+	return scheduleOf(schedule)
+}
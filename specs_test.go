@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAll(t *testing.T) {
+	s := NewScheduler()
+	ran := make(chan bool, 1)
+	s.RegisterFunc("sync", func(context.Context) error {
+		ran <- true
+		return nil
+	})
+	s.RegisterFunc("report", func(context.Context) error { return nil })
+
+	jobs, err := s.AddAll([]JobSpec{
+		{Name: "sync", Schedule: "every 1h", Tags: []string{"billing"}, Func: "sync"},
+		{Name: "report", Schedule: "0 8 * * mon", Tags: []string{"billing", "daily"}, Func: "report"},
+	})
+	assert.Nil(t, err)
+	defer s.Group("billing").StopAll()
+	assert.Len(t, jobs, 2)
+	assert.True(t, <-ran)
+	assert.Equal(t, jobs, s.Jobs())
+	assert.Equal(t, "cron 0 8 * * mon", s.Job("report").Description())
+	assert.Equal(t, jobs, s.Group("billing").snapshot())
+	assert.Equal(t, jobs[1:], s.Group("daily").snapshot())
+}
+
+func TestAddAllErrors(t *testing.T) {
+	s := NewScheduler()
+	s.RegisterFunc("sync", func(context.Context) error { return nil })
+	existing, err := s.Every(1).Hours().NotImmediately().Named("sync").Run(test)
+	assert.Nil(t, err)
+	defer existing.Stop()
+
+	_, err = s.AddAll([]JobSpec{
+		{Name: "ok", Schedule: "daily 08:00", Func: "sync"},
+		{Name: "sync", Schedule: "every 1h", Func: "sync"},
+		{Name: "", Schedule: "every 1h", Func: "sync"},
+		{Name: "bad", Schedule: "whenever", Func: "sync"},
+		{Name: "nofunc", Schedule: "every 1h", Func: "missing"},
+		{Name: "ok", Schedule: "every 1h", Func: "sync"},
+	})
+	var specErr *SpecError
+	assert.True(t, errors.As(err, &specErr))
+	assert.Equal(t, 1, specErr.Index)
+	var indexes []int
+	for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+		indexes = append(indexes, err.(*SpecError).Index)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, indexes)
+	assert.Contains(t, err.Error(), `job spec 4 ("nofunc"): unknown function "missing"`)
+	assert.Equal(t, []*Job{existing}, s.Jobs())
+}
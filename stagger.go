@@ -0,0 +1,59 @@
+package scheduler
+
+import "time"
+
+// WithStagger spreads the first runs of the scheduler's jobs that recur at the
+// same interval across window, or across the interval if it is shorter, so
+// that registering many Every(1).Minutes() jobs does not start them all at the
+// same instant. Each job then keeps its own phase. Jobs with other schedules,
+// or fixed to a phase with AtSecond, AtMinute or SpreadBy, are not delayed.
+func (s *Scheduler) WithStagger(window time.Duration) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.stagger = window
+	return s
+}
+
+// delay returns how long to delay the first run of the next job recurring
+// every d. The nth such job is delayed by the nth point of the van der Corput
+// sequence, 0, 1/2, 1/4, 3/4, 1/8..., of the window, which keeps the jobs
+// evenly spread however many are registered.
+func (s *Scheduler) delay(every time.Duration) time.Duration {
+	s.Lock()
+	defer s.Unlock()
+
+	window := s.stagger
+	if window <= 0 {
+		return 0
+	}
+	if window > every {
+		window = every
+	}
+	if s.staggered == nil {
+		s.staggered = make(map[time.Duration]int)
+	}
+	n := s.staggered[every]
+	s.staggered[every]++
+	var frac float64
+	for f := 0.5; n > 0; n, f = n>>1, f/2 {
+		if n&1 == 1 {
+			frac += f
+		}
+	}
+	return time.Duration(frac * float64(window))
+}
+
+// stagger returns how long to delay the job's first run, see WithStagger, and
+// moves the periods of a NoDrift job along with it.
+func (j *Job) stagger() time.Duration {
+	r, ok := j.schedule.(*recurrent)
+	if j.scheduler == nil || !ok || r.phased {
+		return 0
+	}
+	d := j.scheduler.delay(time.Duration(r.units) * r.period)
+	if r.anchored {
+		r.epoch = r.epoch.Add(d)
+	}
+	return d
+}
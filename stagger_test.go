@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStagger(t *testing.T) {
+	clock := fixedClock{time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)}
+	s := NewScheduler().WithStagger(time.Minute)
+	var next []time.Duration
+	for i := 0; i < 4; i++ {
+		job, err := s.Every(1).Minutes().WithClock(clock).Run(test)
+		assert.Nil(t, err)
+		defer job.Stop()
+		next = append(next, job.State().NextRun.Sub(clock.t))
+	}
+	assert.Equal(t, []time.Duration{0, 30 * time.Second, 15 * time.Second, 45 * time.Second}, next)
+
+	short, err := s.Every(10).Seconds().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer short.Stop()
+	short, err = s.Every(10).Seconds().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer short.Stop()
+	assert.Equal(t, clock.t.Add(5*time.Second), short.State().NextRun)
+
+	anchored, err := s.Every(1).Minutes().NotImmediately().NoDrift().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer anchored.Stop()
+	assert.Equal(t, clock.t.Add(time.Minute+7500*time.Millisecond), anchored.State().NextRun)
+	wait, _ := anchored.schedule.nextRun(clock.t.Add(time.Minute + 8*time.Second))
+	assert.Equal(t, time.Minute-500*time.Millisecond, wait)
+
+	phased, err := s.Every(1).Minutes().AtSecond(5).WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer phased.Stop()
+	assert.Equal(t, clock.t.Add(5*time.Second), phased.State().NextRun)
+}
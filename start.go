@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+)
+
+// pendingJob is a job added to a scheduler that has not been started yet, see
+// Add.
+type pendingJob struct {
+	job *Job
+	fn  func(context.Context) error
+}
+
+// Add registers j to run f once the scheduler is started with Start or
+// StartAsync, so the whole set of jobs can be checked and inspected, e.g. with
+// Jobs or DryRun, before any of them fires:
+//
+//	s := scheduler.NewScheduler()
+//	s.Add(s.Every(5).Minutes(), poll)
+//	s.Add(scheduler.Every().Day().At("03:00"), cleanup)
+//	s.DryRun(os.Stdout, 24*time.Hour)
+//	s.StartAsync()
+//
+// It returns an error, and does not add j, if j was built with a mistake or
+// its schedule never runs. Jobs added once the scheduler has started run right
+// away, as with Run.
+func (s *Scheduler) Add(j *Job, f func()) (*Job, error) {
+	if j.err != nil {
+		return nil, j.err
+	}
+	if f == nil {
+		return nil, errors.New("nil function")
+	}
+	if j.schedule == nil {
+		return nil, errors.New("nil schedule")
+	}
+	if _, err := j.schedule.next(j.now()); err != nil {
+		return nil, err
+	}
+	fn := func(context.Context) error {
+		f()
+		return nil
+	}
+	s.bind(j)
+	s.Lock()
+	started := s.started
+	if !started {
+		s.pending = append(s.pending, pendingJob{job: j, fn: fn})
+	}
+	s.Unlock()
+	if started {
+		return j.RunErr(fn)
+	}
+	s.register(j)
+	return j, nil
+}
+
+// StartAsync runs the jobs added with Add and returns the errors of those that
+// could not be started, joined in a single error; the others run anyway.
+func (s *Scheduler) StartAsync() error {
+	_, err := s.start()
+	return err
+}
+
+// Start works like StartAsync and then blocks until all the jobs it started
+// have stopped, e.g. because the context of the scheduler, see WithContext,
+// is done, or the scheduler was shut down.
+func (s *Scheduler) Start() error {
+	jobs, err := s.start()
+	for _, j := range jobs {
+		<-j.ctx.Done()
+	}
+	return err
+}
+
+// start runs the pending jobs of the scheduler and returns those that were
+// started.
+func (s *Scheduler) start() ([]*Job, error) {
+	s.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.started = true
+	s.Unlock()
+
+	var errs []error
+	jobs := make([]*Job, 0, len(pending))
+	for _, p := range pending {
+		j, err := p.job.RunErr(p.fn)
+		if err != nil {
+			s.unregister(p.job)
+			errs = append(errs, err)
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, errors.Join(errs...)
+}
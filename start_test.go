@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartAsync(t *testing.T) {
+	s := NewScheduler()
+	c := make(chan string, 10)
+	poll, err := s.Add(s.Every(1).Hours(), func() { c <- "poll" })
+	assert.Nil(t, err)
+	defer poll.Stop()
+	cleanup, err := s.Add(Every().Day().At("03:00"), func() { c <- "cleanup" })
+	assert.Nil(t, err)
+	defer cleanup.Stop()
+	assert.Equal(t, []*Job{poll, cleanup}, s.Jobs())
+	assert.Equal(t, Created, poll.Status())
+	select {
+	case name := <-c:
+		t.Error("job ran before the scheduler was started:", name)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.Nil(t, s.StartAsync())
+	assert.Equal(t, "poll", <-c)
+	assert.Equal(t, Scheduled, cleanup.Status())
+
+	late, err := s.Add(s.Every(1).Hours(), func() { c <- "late" })
+	assert.Nil(t, err)
+	defer late.Stop()
+	assert.Equal(t, "late", <-c)
+
+	for _, j := range []*Job{Every(0).Hours(), Every(1).Hours().At("08:00")} {
+		_, err = s.Add(j, test)
+		assert.NotNil(t, err)
+	}
+	_, err = s.Add(s.Every(1).Hours(), nil)
+	assert.NotNil(t, err)
+	assert.Len(t, s.Jobs(), 3)
+}
+
+func TestStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewScheduler(WithContext(ctx))
+	ran := make(chan bool, 1)
+	_, err := s.Add(s.Every(1).Hours(), func() { ran <- true })
+	assert.Nil(t, err)
+	done := make(chan error)
+	go func() { done <- s.Start() }()
+	assert.True(t, <-ran)
+	select {
+	case <-done:
+		t.Fatal("Start returned while its jobs were running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	assert.Nil(t, <-done)
+}
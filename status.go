@@ -0,0 +1,93 @@
+package scheduler
+
+// Status is where a job is in its life:
+//
+//	Created → Scheduled ⇄ Running
+//	              ↓ ↑
+//	            Paused
+//
+// and from any of them but Created to Stopped, or to Completed once its
+// schedule has no more runs. Unlike State, which is what a Store keeps of a
+// job, it is not persisted.
+type Status int
+
+const (
+	// Created jobs have not been started with Run, or failed to start.
+	Created Status = iota
+	// Scheduled jobs wait for their next run.
+	Scheduled
+	// Running jobs have a run in progress.
+	Running
+	// Paused jobs skip their runs until resumed, see Pause.
+	Paused
+	// Stopped jobs were stopped, see StopCause, and run no more.
+	Stopped
+	// Completed jobs have no more runs in their schedule, such as those
+	// defined with Once or OnDates, and none in progress.
+	Completed
+)
+
+var statusNames = [...]string{"created", "scheduled", "running", "paused", "stopped", "completed"}
+
+func (s Status) String() string {
+	if s < Created || int(s) >= len(statusNames) {
+		return "unknown"
+	}
+	return statusNames[s]
+}
+
+// StatusChange is a transition of a job from a state to another, see
+// OnStatusChange.
+type StatusChange struct {
+	Job  *Job
+	From Status
+	To   Status
+}
+
+// Status returns the state the job is in.
+func (j *Job) Status() Status {
+	j.RLock()
+	defer j.RUnlock()
+	return j.statusOf()
+}
+
+// OnStatusChange sets a function to call on each change of the job's state,
+// e.g. to log it or drive a dashboard. It is called in the goroutine that
+// caused the change, possibly from several at once, and so may see changes in
+// a different order than they happened, but From is always the state To
+// replaced.
+func (j *Job) OnStatusChange(f func(StatusChange)) *Job {
+	j.onStatus = f
+	return j
+}
+
+// statusOf works out the state of the job. The caller must hold its lock.
+func (j *Job) statusOf() Status {
+	switch {
+	case j.changes == nil:
+		return Created
+	case j.exited && j.cause != nil:
+		return Stopped
+	case j.isRunning:
+		return Running
+	case j.exited:
+		return Completed
+	case j.paused:
+		return Paused
+	}
+	return Scheduled
+}
+
+// changed calls the job's OnStatusChange function if its state has changed
+// since the last call.
+func (j *Job) changed() {
+	j.Lock()
+	from, to := j.status, j.statusOf()
+	j.status = to
+	f := j.onStatus
+	j.Unlock()
+
+	if from != to && f != nil {
+		f(StatusChange{Job: j, From: from, To: to})
+	}
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatus(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	release := make(chan bool)
+	job := Every(1).Hours().NotImmediately().OnStatusChange(func(c StatusChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, c.From.String()+"→"+c.To.String())
+	})
+	assert.Equal(t, Created, job.Status())
+
+	_, err := job.Run(func() { <-release })
+	assert.Nil(t, err)
+	assert.Equal(t, Scheduled, job.Status())
+	job.SkipWait <- true
+	for job.Status() != Running {
+		time.Sleep(time.Millisecond)
+	}
+	release <- true
+	for job.Status() != Scheduled {
+		time.Sleep(time.Millisecond)
+	}
+	job.Pause()
+	assert.Equal(t, Paused, job.Status())
+	job.Resume()
+	job.Stop()
+	for job.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		"created→scheduled",
+		"scheduled→running",
+		"running→scheduled",
+		"scheduled→paused",
+		"paused→scheduled",
+		"scheduled→stopped",
+	}, changes)
+}
+
+func TestStatusCompleted(t *testing.T) {
+	job := After(0)
+	done := job.Done()
+	_, err := job.Run(test)
+	assert.Nil(t, err)
+	<-done
+	for job.Status() != Completed {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, job.StopCause())
+
+	job = Every(0).Hours()
+	_, err = job.Run(test)
+	assert.NotNil(t, err)
+	assert.Equal(t, Created, job.Status())
+	assert.Equal(t, "unknown", Status(-1).String())
+}
+
+func TestStatusQuit(t *testing.T) {
+	job, err := Every(1).Hours().NotImmediately().Run(test)
+	assert.Nil(t, err)
+	job.Quit <- true
+	for job.Status() != Stopped {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, ErrStopped, job.StopCause())
+}
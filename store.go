@@ -0,0 +1,251 @@
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by a Store that has no state for a job.
+var ErrNotFound = errors.New("job state not found")
+
+// JobState is what a Store keeps about a job between runs and restarts.
+type JobState struct {
+	Name string
+	// ID identifies the job across restarts and renames, see WithID.
+	ID        string
+	LastRun   time.Time
+	NextRun   time.Time
+	Running   bool
+	Runs      int
+	Failed    int
+	LastError string
+}
+
+// Store persists the state of named jobs. A scheduler with a store loads the
+// state of each job when it is registered and saves it then, after each run
+// and on Shutdown. Jobs without a name are not persisted.
+type Store interface {
+	SaveJobState(state JobState) error
+	// LoadJobState returns ErrNotFound if there is no state for the job.
+	LoadJobState(name string) (JobState, error)
+	ListJobs() ([]JobState, error)
+}
+
+// Named gives the job a name, which identifies it in a Store.
+func (j *Job) Named(name string) *Job {
+	j.Lock()
+	defer j.Unlock()
+
+	j.name = name
+	return j
+}
+
+// Name returns the name of the job, if it has one.
+func (j *Job) Name() string {
+	j.RLock()
+	defer j.RUnlock()
+
+	return j.name
+}
+
+// WithID gives the job a stable ID under which its state is matched in a
+// Store, even if the job is renamed. Jobs without one get an ID when they are
+// first stored, and keep it across restarts as long as their name does not
+// change.
+func (j *Job) WithID(id string) *Job {
+	j.Lock()
+	defer j.Unlock()
+
+	if j.err != nil {
+		return j
+	}
+	if id == "" {
+		j.err = errors.New("empty job ID")
+		return j
+	}
+	j.id = id
+	return j
+}
+
+// ID returns the stable ID of the job, if it has one yet.
+func (j *Job) ID() string {
+	j.RLock()
+	defer j.RUnlock()
+
+	return j.id
+}
+
+// WithStore sets the store that persists the state of the scheduler's jobs.
+func (s *Scheduler) WithStore(st Store) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	s.store = st
+	s.stored = nil
+	return s
+}
+
+func (s *Scheduler) getStore() Store {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.store
+}
+
+// storeFailed records an error of the scheduler's store, which Healthy reports
+// until the store works again.
+func (s *Scheduler) storeFailed(err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.storeErr = err
+}
+
+// State returns the current state of the job, as it would be stored.
+func (j *Job) State() JobState {
+	j.RLock()
+	defer j.RUnlock()
+
+	state := JobState{
+		Name:    j.name,
+		ID:      j.id,
+		LastRun: j.startedAt,
+		NextRun: j.nextAt,
+		Running: j.isRunning,
+		Runs:    j.stats.Runs,
+		Failed:  j.stats.Failed,
+	}
+	if j.lastErr != nil {
+		state.LastError = j.lastErr.Error()
+	}
+	return state
+}
+
+// restore loads the job's stored state, if any, into its counters.
+func (j *Job) restore() {
+	st := j.store()
+	if st == nil {
+		return
+	}
+	state, err := j.load(st)
+	if err == ErrNotFound {
+		j.Lock()
+		if j.id == "" {
+			j.id = newID()
+		}
+		j.Unlock()
+		return
+	}
+	if err != nil {
+		j.scheduler.storeFailed(err)
+		return
+	}
+	j.Lock()
+	if j.id == "" {
+		j.id = state.ID
+	}
+	if j.id == "" {
+		j.id = newID()
+	}
+	j.startedAt = state.LastRun
+	j.stats.Runs = state.Runs
+	j.stats.Failed = state.Failed
+	if state.LastError != "" {
+		j.lastErr = errors.New(state.LastError)
+	}
+	j.Unlock()
+}
+
+// load returns the stored state of the job: the one with its ID, if it has
+// one, or else the one with its name, unless that belongs to a job with
+// another ID. A job is looked up by name first, so only the states of jobs
+// whose ID is not stored under their name, e.g. because they were renamed,
+// need the list of all states, which is read once and shared by the jobs of
+// the scheduler.
+func (j *Job) load(st Store) (JobState, error) {
+	name, id := j.Name(), j.ID()
+	state, err := st.LoadJobState(name)
+	if id == "" || err == nil && state.ID == id {
+		return state, err
+	}
+	if err != nil && err != ErrNotFound {
+		return JobState{}, err
+	}
+	moved, ok, err := j.scheduler.storedState(st, id)
+	if err != nil {
+		return JobState{}, err
+	}
+	if ok {
+		return moved, nil
+	}
+	if state.Name == "" || state.ID != "" {
+		return JobState{}, ErrNotFound
+	}
+	return state, nil
+}
+
+// storedState returns the state in the store with the given ID, if any,
+// listing the states of the store the first time.
+func (s *Scheduler) storedState(st Store, id string) (JobState, bool, error) {
+	s.Lock()
+	listed := s.stored != nil
+	s.Unlock()
+	if !listed {
+		states, err := st.ListJobs()
+		if err != nil {
+			return JobState{}, false, err
+		}
+		stored := make(map[string]JobState, len(states))
+		for _, state := range states {
+			if state.ID != "" {
+				stored[state.ID] = state
+			}
+		}
+		s.Lock()
+		if s.stored == nil {
+			s.stored = stored
+		}
+		s.Unlock()
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	state, ok := s.stored[id]
+	return state, ok, nil
+}
+
+// newID returns a random job ID.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// persist saves the job's state in its scheduler's store, if any.
+func (j *Job) persist() {
+	st := j.store()
+	if st == nil {
+		return
+	}
+	state := j.State()
+	err := st.SaveJobState(state)
+	j.scheduler.storeFailed(err)
+	if err == nil && state.ID != "" {
+		s := j.scheduler
+		s.Lock()
+		if s.stored != nil {
+			s.stored[state.ID] = state
+		}
+		s.Unlock()
+	}
+}
+
+// store returns the store the job is persisted in, if any.
+func (j *Job) store() Store {
+	if j.scheduler == nil || j.Name() == "" {
+		return nil
+	}
+	return j.scheduler.getStore()
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	states map[string]JobState
+	saves  int
+	lists  int
+	err    error
+	sync.Mutex
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{states: make(map[string]JobState)}
+}
+
+func (m *memoryStore) SaveJobState(state JobState) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.saves++
+	m.states[state.Name] = state
+	return m.err
+}
+
+func (m *memoryStore) LoadJobState(name string) (JobState, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	state, ok := m.states[name]
+	if !ok {
+		return JobState{}, ErrNotFound
+	}
+	return state, nil
+}
+
+func (m *memoryStore) ListJobs() ([]JobState, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.lists++
+	var states []JobState
+	for _, state := range m.states {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (m *memoryStore) state(name string) (JobState, int) {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.states[name], m.saves
+}
+
+func TestStore(t *testing.T) {
+	st := newMemoryStore()
+	st.states["report"] = JobState{Name: "report", Runs: 41, Failed: 2, LastError: "boom"}
+	s := NewScheduler().WithStore(st)
+
+	job, err := s.Every(1).Hours().NotImmediately().Named("report").Run(test)
+	assert.Nil(t, err)
+	state, saves := st.state("report")
+	assert.Equal(t, 1, saves)
+	assert.Equal(t, 41, state.Runs)
+	assert.Equal(t, "boom", state.LastError)
+	assert.False(t, state.NextRun.IsZero())
+	assert.Equal(t, "report", job.Name())
+
+	job.SkipWait <- true
+	for {
+		if state, saves = st.state("report"); saves == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 42, state.Runs)
+	assert.False(t, state.LastRun.IsZero())
+
+	assert.Nil(t, s.Shutdown(time.Second))
+	_, saves = st.state("report")
+	assert.Equal(t, 3, saves)
+}
+
+func TestStoreSkipsUnnamed(t *testing.T) {
+	st := newMemoryStore()
+	s := NewScheduler().WithStore(st)
+	job, err := s.Every(1).Hours().Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	time.Sleep(10 * time.Millisecond)
+	states, err := st.ListJobs()
+	assert.Nil(t, err)
+	assert.Len(t, states, 0)
+}
+
+func TestStoreError(t *testing.T) {
+	st := newMemoryStore()
+	st.err = errors.New("disk full")
+	s := NewScheduler().WithStore(st)
+	job, err := s.Every(1).Hours().NotImmediately().Named("report").Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.EqualError(t, s.Healthy(), "disk full")
+}
+
+func TestStableIDs(t *testing.T) {
+	st := newMemoryStore()
+	s := NewScheduler().WithStore(st)
+	job, err := s.Every(1).Hours().NotImmediately().Named("report").Run(test)
+	assert.Nil(t, err)
+	job.Stop()
+	id := job.ID()
+	assert.Len(t, id, 32)
+	state, _ := st.state("report")
+	assert.Equal(t, id, state.ID)
+
+	restarted, err := NewScheduler().WithStore(st).Every(1).Hours().NotImmediately().Named("report").Run(test)
+	assert.Nil(t, err)
+	restarted.Stop()
+	assert.Equal(t, id, restarted.ID())
+
+	st.states["old-report"] = JobState{Name: "old-report", ID: "report-v1", Runs: 7}
+	renamed, err := NewScheduler().WithStore(st).Every(1).Hours().NotImmediately().Named("daily-report").WithID("report-v1").Run(test)
+	assert.Nil(t, err)
+	renamed.Stop()
+	assert.Equal(t, 7, renamed.Stats().Runs)
+	state, _ = st.state("daily-report")
+	assert.Equal(t, "report-v1", state.ID)
+
+	st.states["report"] = JobState{Name: "report", ID: id, Runs: 3}
+	other, err := NewScheduler().WithStore(st).Every(1).Hours().NotImmediately().Named("report").WithID("other").Run(test)
+	assert.Nil(t, err)
+	other.Stop()
+	assert.Equal(t, 0, other.Stats().Runs)
+	assert.Equal(t, "other", other.ID())
+
+	_, err = Every(1).Hours().WithID("").Run(test)
+	assert.NotNil(t, err)
+}
+
+func TestStableIDsListOnce(t *testing.T) {
+	st := newMemoryStore()
+	st.states["old-report"] = JobState{Name: "old-report", ID: "report-v1", Runs: 7}
+	s := NewScheduler().WithStore(st)
+	for _, name := range []string{"a", "b", "c"} {
+		job, err := s.Every(1).Hours().NotImmediately().Named(name).WithID(name).Run(test)
+		assert.Nil(t, err)
+		job.Stop()
+	}
+	renamed, err := s.Every(1).Hours().NotImmediately().Named("daily-report").WithID("report-v1").Run(test)
+	assert.Nil(t, err)
+	renamed.Stop()
+	assert.Equal(t, 7, renamed.Stats().Runs)
+
+	// Jobs found under their name need no list.
+	restarted := NewScheduler().WithStore(st)
+	for _, name := range []string{"a", "b", "c"} {
+		job, err := restarted.Every(1).Hours().NotImmediately().Named(name).WithID(name).Run(test)
+		assert.Nil(t, err)
+		job.Stop()
+	}
+	st.Lock()
+	defer st.Unlock()
+	assert.Equal(t, 1, st.lists)
+}
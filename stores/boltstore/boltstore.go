@@ -0,0 +1,98 @@
+// Package boltstore implements scheduler.Store on a single bbolt database file,
+// so job state survives restarts on single-node deployments without any
+// external service.
+//
+//	store, err := boltstore.Open("/var/lib/myapp/scheduler.db")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer store.Close()
+//	s := scheduler.NewScheduler().WithStore(store)
+package boltstore
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucket = []byte("jobs")
+
+// Store keeps job states as JSON in a bucket of a bbolt database.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database file at path, creating it if needed. It fails if
+// another process holds the file for longer than a second.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	s, err := New(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New returns a store using an open database, which is left open by Close.
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SaveJobState implements scheduler.Store.
+func (s *Store) SaveJobState(state scheduler.JobState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(state.Name), data)
+	})
+}
+
+// LoadJobState implements scheduler.Store.
+func (s *Store) LoadJobState(name string) (scheduler.JobState, error) {
+	var state scheduler.JobState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucket).Get([]byte(name))
+		if data == nil {
+			return scheduler.ErrNotFound
+		}
+		return json.Unmarshal(data, &state)
+	})
+	return state, err
+}
+
+// ListJobs implements scheduler.Store. States are sorted by name.
+func (s *Store) ListJobs() ([]scheduler.JobState, error) {
+	var states []scheduler.JobState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(_, data []byte) error {
+			var state scheduler.JobState
+			if err := json.Unmarshal(data, &state); err != nil {
+				return err
+			}
+			states = append(states, state)
+			return nil
+		})
+	})
+	return states, err
+}
@@ -0,0 +1,53 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scheduler.db")
+	store, err := Open(path)
+	assert.Nil(t, err)
+
+	_, err = store.LoadJobState("report")
+	assert.Equal(t, scheduler.ErrNotFound, err)
+
+	lastRun := time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)
+	assert.Nil(t, store.SaveJobState(scheduler.JobState{Name: "report", LastRun: lastRun, Runs: 3}))
+	assert.Nil(t, store.SaveJobState(scheduler.JobState{Name: "cleanup", Failed: 1, LastError: "boom"}))
+	assert.Nil(t, store.Close())
+
+	store, err = Open(path)
+	assert.Nil(t, err)
+	defer store.Close()
+	state, err := store.LoadJobState("report")
+	assert.Nil(t, err)
+	assert.True(t, lastRun.Equal(state.LastRun))
+	assert.Equal(t, 3, state.Runs)
+
+	states, err := store.ListJobs()
+	assert.Nil(t, err)
+	assert.Len(t, states, 2)
+	assert.Equal(t, "cleanup", states[0].Name)
+	assert.Equal(t, "boom", states[0].LastError)
+}
+
+func TestStoreWithScheduler(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "scheduler.db"))
+	assert.Nil(t, err)
+	defer store.Close()
+
+	s := scheduler.NewScheduler().WithStore(store)
+	_, err = s.Every(1).Hours().NotImmediately().Named("report").Run(func() {})
+	assert.Nil(t, err)
+	assert.Nil(t, s.Shutdown(time.Second))
+
+	state, err := store.LoadJobState("report")
+	assert.Nil(t, err)
+	assert.False(t, state.NextRun.IsZero())
+}
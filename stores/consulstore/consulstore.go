@@ -0,0 +1,153 @@
+// Package consulstore implements scheduler.Locker and scheduler.Membership on
+// Consul sessions, so the replicas of a service run each named job in one
+// replica at a time or split their jobs as a scheduler.Cluster.
+//
+//	client, err := api.NewClient(api.DefaultConfig())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	s := scheduler.NewScheduler().WithLocker(consulstore.NewLocker(client, "myapp/scheduler/", 15*time.Second))
+package consulstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/hashicorp/consul/api"
+)
+
+// WaitTime is how long Lock waits for a lock held by another replica to be
+// released before skipping the run.
+const WaitTime = time.Second
+
+// Locker takes job locks on keys under a prefix. Each lock is held through a
+// session that is renewed while the run is in progress, so a replica that
+// dies releases its locks once the session expires. A run whose lock is lost
+// meanwhile, e.g. because its session could not be renewed in time, has its
+// context cancelled, see scheduler.LoseLock.
+type Locker struct {
+	client *api.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewLocker returns a locker taking locks on prefix + "locks/" + the job's
+// name, through sessions that expire ttl after the replica holding them stops
+// renewing them. Consul accepts ttls from 10 seconds to a day.
+func NewLocker(client *api.Client, prefix string, ttl time.Duration) *Locker {
+	return &Locker{client: client, prefix: prefix + "locks/", ttl: ttl}
+}
+
+// Lock implements scheduler.Locker.
+func (l *Locker) Lock(ctx context.Context, name string) (func(), error) {
+	// Without a session of our own, the lock creates one and renews it in the
+	// background until it is unlocked.
+	lock, err := l.client.LockOpts(&api.LockOptions{
+		Key:          l.prefix + name,
+		SessionName:  "scheduler job " + name,
+		SessionTTL:   l.ttl.String(),
+		LockTryOnce:  true,
+		LockWaitTime: WaitTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lost, err := lock.Lock(ctx.Done())
+	if err != nil {
+		return nil, err
+	}
+	if lost == nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, scheduler.ErrLocked
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-lost:
+			scheduler.LoseLock(ctx)
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		// Unlock destroys the session, releasing the lock even if it fails.
+		lock.Unlock()
+	}, nil
+}
+
+// Membership registers the nodes of a cluster as keys under a prefix, each
+// held by a session that is renewed while the node is a member, so a node that
+// dies leaves once its session expires.
+type Membership struct {
+	client *api.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewMembership returns a membership registering nodes under prefix +
+// "members/", through sessions that expire ttl after the node holding them
+// stops renewing them. Consul accepts ttls from 10 seconds to a day.
+func NewMembership(client *api.Client, prefix string, ttl time.Duration) *Membership {
+	return &Membership{client: client, prefix: prefix + "members/", ttl: ttl}
+}
+
+// Join implements scheduler.Membership. It destroys the node's session when
+// ctx is done, so the others see it leave at once.
+func (m *Membership) Join(ctx context.Context, self string, members func([]string)) error {
+	id, _, err := m.client.Session().Create(&api.SessionEntry{
+		Name:     "scheduler member " + self,
+		TTL:      m.ttl.String(),
+		Behavior: api.SessionBehaviorDelete,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	// RenewPeriodic destroys the session once done is closed.
+	done := make(chan struct{})
+	defer close(done)
+	lost := make(chan error, 1)
+	go func() { lost <- m.client.Session().RenewPeriodic(m.ttl.String(), id, nil, done) }()
+
+	ok, _, err := m.client.KV().Acquire(&api.KVPair{Key: m.prefix + self, Value: []byte(self), Session: id}, nil)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("member " + self + " already registered")
+	}
+	var index uint64
+	for {
+		// A blocking query returns once the members change, or after a
+		// while.
+		pairs, meta, err := m.client.KV().List(m.prefix, (&api.QueryOptions{WaitIndex: index}).WithContext(ctx))
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-lost:
+			if err == nil {
+				err = errors.New("membership session lost")
+			}
+			return err
+		default:
+		}
+		if err != nil {
+			return err
+		}
+		if meta.LastIndex == index {
+			continue
+		}
+		index = meta.LastIndex
+		names := make([]string, 0, len(pairs))
+		for _, p := range pairs {
+			names = append(names, strings.TrimPrefix(p.Key, m.prefix))
+		}
+		sort.Strings(names)
+		members(names)
+	}
+}
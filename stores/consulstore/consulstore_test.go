@@ -0,0 +1,66 @@
+package consulstore
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// client connects to the Consul agent in CONSUL_HTTP_ADDR, skipping the test
+// if there is none.
+func client(t *testing.T) *api.Client {
+	if os.Getenv("CONSUL_HTTP_ADDR") == "" {
+		t.Skip("CONSUL_HTTP_ADDR not set")
+	}
+	c, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestLocker(t *testing.T) {
+	c := client(t)
+	prefix := "scheduler-test/" + t.Name() + "/"
+	a, b := NewLocker(c, prefix, 10*time.Second), NewLocker(c, prefix, 10*time.Second)
+
+	unlock, err := a.Lock(context.Background(), "cleanup")
+	assert.NoError(t, err)
+	_, err = b.Lock(context.Background(), "cleanup")
+	assert.Equal(t, scheduler.ErrLocked, err)
+
+	unlock()
+	unlock, err = b.Lock(context.Background(), "cleanup")
+	if assert.NoError(t, err) {
+		unlock()
+	}
+}
+
+func TestMembership(t *testing.T) {
+	c := client(t)
+	prefix := "scheduler-test/" + t.Name() + "/"
+	a, b := scheduler.NewCluster("a"), scheduler.NewCluster("b")
+	ctxA, leaveA := context.WithCancel(context.Background())
+	doneA := make(chan error)
+	go func() { doneA <- a.Join(ctxA, NewMembership(c, prefix, 10*time.Second)) }()
+	ctxB, leaveB := context.WithCancel(context.Background())
+	defer leaveB()
+	go b.Join(ctxB, NewMembership(c, prefix, 10*time.Second))
+
+	for len(a.Members()) != 2 || len(b.Members()) != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"a", "b"}, a.Members())
+
+	leaveA()
+	assert.NoError(t, <-doneA)
+	for len(b.Members()) != 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"b"}, b.Members())
+}
@@ -0,0 +1,223 @@
+// Package etcdstore implements scheduler.Store, scheduler.Locker and
+// scheduler.Membership on etcd, so the replicas of a service can share job
+// state, run each named job in one replica at a time and split their jobs as
+// a scheduler.Cluster.
+//
+//	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{"localhost:2379"}})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	s := scheduler.NewScheduler().
+//		WithStore(etcdstore.New(cli, "/myapp/scheduler/")).
+//		WithLocker(etcdstore.NewLocker(cli, "/myapp/scheduler/", 10*time.Second))
+package etcdstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// Timeout bounds each request the store and locker make to etcd, other than
+// taking a lock, which is bounded by the context of the run.
+const Timeout = 5 * time.Second
+
+// Store keeps job states as JSON under a key prefix.
+type Store struct {
+	cli    *clientv3.Client
+	prefix string
+}
+
+// New returns a store keeping job states under prefix + "jobs/". The client is
+// not closed by the store.
+func New(cli *clientv3.Client, prefix string) *Store {
+	return &Store{cli: cli, prefix: prefix + "jobs/"}
+}
+
+// SaveJobState implements scheduler.Store.
+func (s *Store) SaveJobState(state scheduler.JobState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	_, err = s.cli.Put(ctx, s.prefix+state.Name, string(data))
+	return err
+}
+
+// LoadJobState implements scheduler.Store.
+func (s *Store) LoadJobState(name string) (scheduler.JobState, error) {
+	var state scheduler.JobState
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	resp, err := s.cli.Get(ctx, s.prefix+name)
+	if err != nil {
+		return state, err
+	}
+	if len(resp.Kvs) == 0 {
+		return state, scheduler.ErrNotFound
+	}
+	err = json.Unmarshal(resp.Kvs[0].Value, &state)
+	return state, err
+}
+
+// ListJobs implements scheduler.Store. States are sorted by name.
+func (s *Store) ListJobs() ([]scheduler.JobState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	resp, err := s.cli.Get(ctx, s.prefix, clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+	var states []scheduler.JobState
+	for _, kv := range resp.Kvs {
+		var state scheduler.JobState
+		if err := json.Unmarshal(kv.Value, &state); err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Locker takes job locks under a key prefix. Each lock is held through a lease
+// that is kept alive while the run is in progress, so a replica that dies
+// releases its locks once the lease expires. A run whose lease expires
+// meanwhile, e.g. because etcd could not be reached in time, has its context
+// cancelled, see scheduler.LoseLock.
+type Locker struct {
+	cli    *clientv3.Client
+	prefix string
+	ttl    int
+}
+
+// NewLocker returns a locker taking locks under prefix + "locks/", on leases
+// that expire ttl after the replica holding them stops refreshing them. The
+// ttl is rounded up to whole seconds.
+func NewLocker(cli *clientv3.Client, prefix string, ttl time.Duration) *Locker {
+	return &Locker{cli: cli, prefix: prefix + "locks/", ttl: int((ttl + time.Second - 1) / time.Second)}
+}
+
+// Lock implements scheduler.Locker.
+func (l *Locker) Lock(ctx context.Context, name string) (func(), error) {
+	// The session refreshes its lease in the background until it is closed,
+	// however long the run takes.
+	session, err := concurrency.NewSession(l.cli, concurrency.WithTTL(l.ttl))
+	if err != nil {
+		return nil, err
+	}
+	m := concurrency.NewMutex(session, l.prefix+name)
+	if err := m.TryLock(ctx); err != nil {
+		session.Close()
+		if err == concurrency.ErrLocked {
+			return nil, scheduler.ErrLocked
+		}
+		return nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-session.Done():
+			scheduler.LoseLock(ctx)
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+		m.Unlock(ctx)
+		// Closing revokes the lease, which also releases the lock if Unlock
+		// failed.
+		session.Close()
+	}, nil
+}
+
+// Membership registers the nodes of a cluster under a key prefix, each on a
+// lease kept alive while it is a member, so a node that dies leaves once its
+// lease expires.
+type Membership struct {
+	cli    *clientv3.Client
+	prefix string
+	ttl    int
+}
+
+// NewMembership returns a membership registering nodes under prefix +
+// "members/", on leases that expire ttl after the node holding them stops
+// refreshing them. The ttl is rounded up to whole seconds.
+func NewMembership(cli *clientv3.Client, prefix string, ttl time.Duration) *Membership {
+	return &Membership{cli: cli, prefix: prefix + "members/", ttl: int((ttl + time.Second - 1) / time.Second)}
+}
+
+// Join implements scheduler.Membership. It revokes the node's lease when ctx
+// is done, so the others see it leave at once.
+func (m *Membership) Join(ctx context.Context, self string, members func([]string)) error {
+	session, err := concurrency.NewSession(m.cli, concurrency.WithTTL(m.ttl))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	put, cancel := context.WithTimeout(ctx, Timeout)
+	_, err = m.cli.Put(put, m.prefix+self, self, clientv3.WithLease(session.Lease()))
+	cancel()
+	if err != nil {
+		return err
+	}
+	resp, err := m.cli.Get(ctx, m.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	nodes := make(map[string]bool)
+	for _, kv := range resp.Kvs {
+		nodes[strings.TrimPrefix(string(kv.Key), m.prefix)] = true
+	}
+	members(sorted(nodes))
+
+	watch := m.cli.Watch(ctx, m.prefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-session.Done():
+			return errors.New("membership lease lost")
+		case w, ok := <-watch:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return errors.New("membership watch closed")
+			}
+			if err := w.Err(); err != nil {
+				return err
+			}
+			for _, ev := range w.Events {
+				name := strings.TrimPrefix(string(ev.Kv.Key), m.prefix)
+				if ev.Type == clientv3.EventTypePut {
+					nodes[name] = true
+				} else {
+					delete(nodes, name)
+				}
+			}
+			members(sorted(nodes))
+		}
+	}
+}
+
+// sorted returns the names of the nodes in order.
+func sorted(nodes map[string]bool) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
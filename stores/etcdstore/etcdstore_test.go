@@ -0,0 +1,119 @@
+package etcdstore
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// client connects to the etcd cluster in ETCD_ENDPOINTS, skipping the test if
+// there is none.
+func client(t *testing.T) *clientv3.Client {
+	endpoints := os.Getenv("ETCD_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("ETCD_ENDPOINTS not set")
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: strings.Split(endpoints, ","), DialTimeout: Timeout})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cli.Close() })
+	prefix := "/scheduler-test/" + t.Name() + "/"
+	t.Cleanup(func() { cli.Delete(context.Background(), prefix, clientv3.WithPrefix()) })
+	return cli
+}
+
+func TestStore(t *testing.T) {
+	cli := client(t)
+	s := New(cli, "/scheduler-test/"+t.Name()+"/")
+
+	_, err := s.LoadJobState("cleanup")
+	assert.Equal(t, scheduler.ErrNotFound, err)
+
+	last := time.Date(2015, 6, 1, 3, 0, 0, 0, time.UTC)
+	assert.NoError(t, s.SaveJobState(scheduler.JobState{Name: "cleanup", LastRun: last, Runs: 3}))
+	assert.NoError(t, s.SaveJobState(scheduler.JobState{Name: "backup", Failed: 1, LastError: "disk full"}))
+
+	state, err := s.LoadJobState("cleanup")
+	assert.NoError(t, err)
+	assert.True(t, state.LastRun.Equal(last))
+	assert.Equal(t, 3, state.Runs)
+
+	states, err := s.ListJobs()
+	assert.NoError(t, err)
+	if assert.Len(t, states, 2) {
+		assert.Equal(t, "backup", states[0].Name)
+		assert.Equal(t, "cleanup", states[1].Name)
+	}
+}
+
+func TestLocker(t *testing.T) {
+	cli := client(t)
+	prefix := "/scheduler-test/" + t.Name() + "/"
+	a, b := NewLocker(cli, prefix, 5*time.Second), NewLocker(cli, prefix, 5*time.Second)
+
+	unlock, err := a.Lock(context.Background(), "cleanup")
+	assert.NoError(t, err)
+	_, err = b.Lock(context.Background(), "cleanup")
+	assert.Equal(t, scheduler.ErrLocked, err)
+
+	unlock()
+	unlock, err = b.Lock(context.Background(), "cleanup")
+	if assert.NoError(t, err) {
+		unlock()
+	}
+}
+
+func TestLockLost(t *testing.T) {
+	cli := client(t)
+	prefix := "/scheduler-test/" + t.Name() + "/"
+	s := scheduler.NewScheduler().WithLocker(NewLocker(cli, prefix, 5*time.Second))
+	cause := make(chan error, 1)
+	job, err := s.Every(1).Hours().Named("cleanup").RunCtx(func(ctx context.Context) {
+		// Revoking the lease closes the session holding the lock.
+		resp, err := cli.Get(ctx, prefix+"locks/cleanup", clientv3.WithPrefix())
+		if err == nil && len(resp.Kvs) == 1 {
+			_, err = cli.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
+		}
+		if err != nil {
+			cause <- err
+			return
+		}
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+	})
+	assert.NoError(t, err)
+	defer job.Stop()
+
+	assert.Equal(t, scheduler.ErrLockLost, <-cause)
+}
+
+func TestMembership(t *testing.T) {
+	cli := client(t)
+	prefix := "/scheduler-test/" + t.Name() + "/"
+	a, b := scheduler.NewCluster("a"), scheduler.NewCluster("b")
+	ctxA, leaveA := context.WithCancel(context.Background())
+	doneA := make(chan error)
+	go func() { doneA <- a.Join(ctxA, NewMembership(cli, prefix, 5*time.Second)) }()
+	ctxB, leaveB := context.WithCancel(context.Background())
+	defer leaveB()
+	go b.Join(ctxB, NewMembership(cli, prefix, 5*time.Second))
+
+	for len(a.Members()) != 2 || len(b.Members()) != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"a", "b"}, a.Members())
+
+	leaveA()
+	assert.NoError(t, <-doneA)
+	for len(b.Members()) != 1 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []string{"b"}, b.Members())
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"errors"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+var tzdata struct {
+	fsys     fs.FS
+	provider LocationProvider
+	sync.RWMutex
+}
+
+// LocationProvider resolves the name of a time zone, such as
+// "America/New_York", to its location. It returns an error for names it does
+// not know.
+type LocationProvider func(name string) (*time.Location, error)
+
+// WithLocationProvider sets the function used to resolve the names given to
+// Timezone and In, and found in parsed schedules, e.g. to map aliases or serve
+// zones from a database of the program's own. Names it fails to resolve fall
+// back to WithTZData and the system database. Passing nil restores the
+// default behaviour.
+func WithLocationProvider(p LocationProvider) {
+	tzdata.Lock()
+	defer tzdata.Unlock()
+
+	tzdata.provider = p
+}
+
+// WithTZData pins the time zone database used to resolve the names given to
+// Timezone. fsys must contain zoneinfo files laid out as in the IANA database,
+// e.g. "America/New_York", such as an embed.FS or the zip.Reader of Go's
+// lib/time/zoneinfo.zip. Zones not found in fsys fall back to the system
+// database. Passing nil restores the default behaviour.
+//
+// Programs running in containers without a zoneinfo database, such as scratch
+// images, may instead be built with "-tags scheduler_tzdata" (or "-tags
+// timetzdata", or import time/tzdata) to embed Go's copy.
+func WithTZData(fsys fs.FS) {
+	tzdata.Lock()
+	defer tzdata.Unlock()
+
+	tzdata.fsys = fsys
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	tzdata.RLock()
+	fsys, provider := tzdata.fsys, tzdata.provider
+	tzdata.RUnlock()
+
+	if provider != nil {
+		if loc, err := provider(name); err == nil && loc != nil {
+			return loc, nil
+		}
+	}
+	if fsys != nil {
+		if data, err := fs.ReadFile(fsys, name); err == nil {
+			loc, err := time.LoadLocationFromTZData(name, data)
+			if err != nil {
+				return nil, errors.New("bad time zone data for " + name)
+			}
+			return loc, nil
+		}
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("unknown time zone " + name)
+	}
+	return loc, nil
+}
+
+// Timezone sets the location used to interpret the time given to At. The name
+// is resolved as in time.LoadLocation, using the provider set with
+// WithLocationProvider and the database pinned by WithTZData if any. By
+// default jobs run in the local time zone. Does not work with recurrent jobs.
+func (j *Job) Timezone(name string) *Job {
+	if j.err != nil {
+		return j
+	}
+	loc, err := loadLocation(name)
+	if err != nil {
+		j.err = err
+		return j
+	}
+	c, ok := j.schedule.(calendar)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	c.clock().loc = loc
+	return j
+}
+
+// In works like Timezone, as in Every().Day().At("09:00").In("America/New_York").
+func (j *Job) In(name string) *Job {
+	return j.Timezone(name)
+}
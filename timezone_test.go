@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"archive/zip"
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimezone(t *testing.T) {
+	job, err := Every().Day().At("08:30").Timezone("America/New_York").Run(test)
+	assert.Nil(t, err)
+	loc, _ := time.LoadLocation("America/New_York")
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual).In(loc)
+	assert.Equal(t, 8, runTime.Hour())
+	assert.Equal(t, 30, runTime.Minute())
+}
+
+func TestTimezoneWeekly(t *testing.T) {
+	job, err := Every().Sunday().Timezone("Asia/Tokyo").At("20:00").Run(test)
+	assert.Nil(t, err)
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	actual, err := job.schedule.nextRun(time.Now())
+	assert.Nil(t, err)
+	runTime := time.Now().Add(actual).In(loc)
+	assert.Equal(t, time.Sunday, runTime.Weekday())
+	assert.Equal(t, 20, runTime.Hour())
+}
+
+func TestTimezoneUnknown(t *testing.T) {
+	job, err := Every().Day().Timezone("Mars/Olympus_Mons").Run(test)
+	assert.Nil(t, job)
+	assert.EqualError(t, err, "unknown time zone Mars/Olympus_Mons")
+}
+
+func TestTimezoneBadChain(t *testing.T) {
+	job, err := Every(1).Hours().Timezone("UTC").Run(test)
+	assert.Nil(t, job)
+	assert.NotNil(t, err)
+}
+
+func TestWithTZData(t *testing.T) {
+	z, err := zip.OpenReader(filepath.Join(runtime.GOROOT(), "lib", "time", "zoneinfo.zip"))
+	if err != nil {
+		t.Skip("zoneinfo.zip not available")
+	}
+	defer z.Close()
+	WithTZData(z)
+	defer WithTZData(nil)
+
+	loc, err := loadLocation("Europe/Madrid")
+	assert.Nil(t, err)
+	assert.Equal(t, "Europe/Madrid", loc.String())
+}
+
+func TestWithLocationProvider(t *testing.T) {
+	eastern := time.FixedZone("EST", -5*60*60)
+	WithLocationProvider(func(name string) (*time.Location, error) {
+		if name != "Office" {
+			return nil, errors.New("unknown office")
+		}
+		return eastern, nil
+	})
+	defer WithLocationProvider(nil)
+
+	job := Every().Day().At("09:00").In("Office")
+	assert.Nil(t, job.err)
+	assert.Equal(t, time.Date(2015, 6, 4, 9, 0, 0, 0, eastern), job.Next(time.Date(2015, 6, 4, 8, 0, 0, 0, eastern)))
+
+	loc, err := loadLocation("UTC")
+	assert.Nil(t, err, "falls back to the system database")
+	assert.Equal(t, time.UTC, loc)
+}
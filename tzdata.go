@@ -0,0 +1,7 @@
+//go:build scheduler_tzdata
+
+package scheduler
+
+// Building with "-tags scheduler_tzdata" embeds Go's time zone database, about
+// 450KB, so zones resolve in environments without one. See WithTZData.
+import _ "time/tzdata"
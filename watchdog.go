@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// minWatchdogInterval bounds how often a watchdog checks its jobs.
+const minWatchdogInterval = 10 * time.Millisecond
+
+// Watchdog starts watching the scheduler's jobs and calls overdue when a job
+// has not started a run within grace of the time it was due, e.g. because
+// its goroutine is starved. overdue is called once per missed run, with how
+// late the run is, and must not block. Runs skipped on purpose, because the
+// job is paused or still running, do not count, and neither does a run in
+// progress that holds up the next one, such as a long inline run: see
+// Timeout and StallAfter for those. Calling the returned function stops the
+// watchdog.
+func (s *Scheduler) Watchdog(grace time.Duration, overdue func(j *Job, late time.Duration)) (stop func(), err error) {
+	if grace <= 0 || overdue == nil {
+		return nil, errors.New("bad watchdog")
+	}
+	interval := grace / 2
+	if interval < minWatchdogInterval {
+		interval = minWatchdogInterval
+	}
+	w := &watchdog{s: s, grace: grace, overdue: overdue, reported: make(map[*Job]time.Time)}
+	quit := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+			}
+			w.check()
+		}
+	}()
+	return func() { close(quit) }, nil
+}
+
+// watchdog is the state of a Watchdog, owned by its goroutine.
+type watchdog struct {
+	s       *Scheduler
+	grace   time.Duration
+	overdue func(j *Job, late time.Duration)
+	// reported holds the due time of the run last reported for each job
+	// that is still late.
+	reported map[*Job]time.Time
+}
+
+// check reports the jobs that became overdue since the last check.
+func (w *watchdog) check() {
+	for j := range w.reported {
+		if !w.s.has(j) {
+			delete(w.reported, j)
+		}
+	}
+	for _, j := range w.s.snapshot() {
+		due, late := j.overdue(w.grace)
+		switch {
+		case late == 0:
+			delete(w.reported, j)
+		case !w.reported[j].Equal(due):
+			w.reported[j] = due
+			w.overdue(j, late)
+		}
+	}
+}
+
+// overdue returns the time the job's next run was due and how late it is, if
+// it is later than grace.
+func (j *Job) overdue(grace time.Duration) (time.Time, time.Duration) {
+	j.RLock()
+	defer j.RUnlock()
+
+	if j.exited || j.isRunning || j.nextAt.IsZero() {
+		return time.Time{}, 0
+	}
+	late := j.now().Sub(j.nextAt)
+	if late <= grace {
+		return time.Time{}, 0
+	}
+	return j.nextAt, late
+}
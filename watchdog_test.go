@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	s := NewScheduler()
+	clock := &manualClock{}
+	starved, err := s.Every(1).Seconds().NotImmediately().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer starved.Stop()
+	release := make(chan bool)
+	inline, err := s.Every(1).Seconds().RunInline(func() { <-release })
+	assert.Nil(t, err)
+	defer inline.Stop()
+	defer close(release)
+
+	overdue := make(chan *Job, 10)
+	stop, err := s.Watchdog(100*time.Millisecond, func(j *Job, late time.Duration) {
+		assert.True(t, late > 100*time.Millisecond)
+		overdue <- j
+	})
+	assert.Nil(t, err)
+	defer stop()
+
+	// The timers of the clock never fire, as if the job were starved.
+	clock.Advance(2 * time.Second)
+	select {
+	case j := <-overdue:
+		assert.Equal(t, starved, j)
+	case <-time.After(3 * time.Second):
+		t.Fatal("overdue job not reported")
+	}
+	// The inline run blocks its job past the next run, which is not overdue.
+	select {
+	case j := <-overdue:
+		t.Error("reported:", j.Description())
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
+
+func TestWatchdogForgets(t *testing.T) {
+	s := NewScheduler()
+	clock := &manualClock{}
+	job, err := s.Every(1).Seconds().NotImmediately().WithClock(clock).Run(test)
+	assert.Nil(t, err)
+	defer job.Stop()
+	var reported []*Job
+	w := &watchdog{s: s, grace: time.Millisecond, reported: make(map[*Job]time.Time),
+		overdue: func(j *Job, late time.Duration) { reported = append(reported, j) }}
+
+	clock.Advance(2 * time.Second)
+	w.check()
+	w.check()
+	assert.Equal(t, []*Job{job}, reported)
+	assert.Len(t, w.reported, 1)
+
+	s.unregister(job)
+	w.check()
+	assert.Len(t, w.reported, 0)
+}
+
+func TestOverdue(t *testing.T) {
+	job := &Job{nextAt: time.Now().Add(-time.Hour)}
+	_, late := job.overdue(time.Minute)
+	assert.True(t, late >= time.Hour)
+	_, late = job.overdue(2 * time.Hour)
+	assert.Equal(t, time.Duration(0), late)
+
+	job.isRunning = true
+	_, late = job.overdue(time.Minute)
+	assert.Equal(t, time.Duration(0), late)
+
+	job.isRunning, job.exited = false, true
+	_, late = job.overdue(time.Minute)
+	assert.Equal(t, time.Duration(0), late)
+}
+
+func TestBadWatchdog(t *testing.T) {
+	_, err := NewScheduler().Watchdog(0, func(*Job, time.Duration) {})
+	assert.NotNil(t, err)
+	_, err = NewScheduler().Watchdog(time.Second, nil)
+	assert.NotNil(t, err)
+}
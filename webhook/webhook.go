@@ -0,0 +1,89 @@
+// Package webhook triggers the named jobs of a scheduler on HTTP requests, so
+// external systems such as CI or alerting can start them on demand:
+//
+//	http.Handle("/hooks/", http.StripPrefix("/hooks/", webhook.New(s, os.Getenv("HOOK_TOKEN"))))
+//
+// A POST to /hooks/report with the header "Authorization: Bearer <token>"
+// then requests a run of the job named "report". The request body, if any, is
+// passed to the run, which reads it with scheduler.Payload. Runs are
+// requested with the scheduler's TriggerJobWith, so they are recorded in its
+// audit sink on behalf of the actor of the request, see Handler.Actor.
+package webhook
+
+import (
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/carlescere/scheduler"
+)
+
+// DefaultMaxPayload is the largest request body accepted unless MaxPayload
+// says otherwise.
+const DefaultMaxPayload = 1 << 20
+
+// Handler triggers the job named by the request path.
+type Handler struct {
+	s     *scheduler.Scheduler
+	token string
+	// MaxPayload is the largest request body accepted, in bytes.
+	MaxPayload int64
+	// Actor returns who requests a run with r, for the audit sink of the
+	// scheduler. By default it is "webhook".
+	Actor func(r *http.Request) string
+}
+
+// New returns a handler for the jobs of s that accepts requests bearing
+// token. A handler with an empty token rejects every request.
+func New(s *scheduler.Scheduler, token string) *Handler {
+	return &Handler{s: s, token: token, MaxPayload: DefaultMaxPayload}
+}
+
+// ServeHTTP answers 202 Accepted once the run is requested, or 409 Conflict if
+// a run of the job was already requested and has not started yet.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	name := strings.Trim(r.URL.Path, "/")
+	if name == "" || h.s.Job(name) == nil {
+		http.Error(w, "no job named "+name, http.StatusNotFound)
+		return
+	}
+	payload, err := io.ReadAll(io.LimitReader(r.Body, h.MaxPayload+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if int64(len(payload)) > h.MaxPayload {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if len(payload) == 0 {
+		payload = nil
+	}
+	actor := "webhook"
+	if h.Actor != nil {
+		actor = h.Actor(r)
+	}
+	if err := h.s.TriggerJobWith(scheduler.WithActor(r.Context(), actor), name, payload); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	if h.token == "" || !strings.HasPrefix(auth, "Bearer ") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(auth[len("Bearer "):]), []byte(h.token)) == 1
+}
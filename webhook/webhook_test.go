@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carlescere/scheduler"
+	"github.com/stretchr/testify/assert"
+)
+
+type audit struct {
+	actors []string
+	sync.Mutex
+}
+
+func (a *audit) Audit(e scheduler.AuditEntry) error {
+	a.Lock()
+	defer a.Unlock()
+
+	a.actors = append(a.actors, e.Actor+" "+e.Action)
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	sink := &audit{}
+	s := scheduler.NewScheduler().WithAuditSink(sink)
+	payloads := make(chan string, 1)
+	release := make(chan bool)
+	j, err := s.Every(1).Hours().NotImmediately().Named("deploy").RunCtx(func(ctx context.Context) {
+		payloads <- string(scheduler.Payload(ctx))
+		<-release
+	})
+	assert.Nil(t, err)
+	defer j.Stop()
+	h := New(s, "secret")
+	h.MaxPayload = 16
+
+	post := func(path, token, body string) int {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusAccepted, post("/deploy", "secret", "v1.2.3"))
+	assert.Equal(t, "v1.2.3", <-payloads)
+	// A run requested while the previous one is going is skipped.
+	assert.Equal(t, http.StatusAccepted, post("/deploy", "secret", ""))
+	for j.Stats().Skipped == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	assert.Equal(t, http.StatusUnauthorized, post("/deploy", "", ""))
+	assert.Equal(t, http.StatusUnauthorized, post("/deploy", "wrong", ""))
+	assert.Equal(t, http.StatusNotFound, post("/cleanup", "secret", ""))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post("/deploy", "secret", strings.Repeat("x", 17)))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/deploy", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+
+	h.Actor = func(r *http.Request) string { return r.Header.Get("X-User") }
+	r := httptest.NewRequest(http.MethodPost, "/deploy", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("X-User", "ci")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	sink.Lock()
+	defer sink.Unlock()
+	assert.Equal(t, []string{"webhook trigger", "webhook trigger", "ci trigger"}, sink.actors)
+}
+
+func TestHandlerWithoutToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/deploy", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+	New(scheduler.NewScheduler(), "").ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// everyWeeks runs on a weekday of every nth week, counted from the week of
+// anchor. Weeks start on Monday.
+type everyWeeks struct {
+	every  int
+	anchor int
+	day    time.Weekday
+	d      daily
+}
+
+// weekOf returns the number of the week of the given date, counted from the
+// week starting on Monday, January 5, 1970.
+func weekOf(year int, month time.Month, day int) int {
+	days := int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60))
+	week := (days - 4) / 7
+	if days-4 < 0 && (days-4)%7 != 0 {
+		week--
+	}
+	return week
+}
+
+func (w *everyWeeks) clock() *daily {
+	return &w.d
+}
+
+func (w *everyWeeks) nextRun(now time.Time) (time.Duration, error) {
+	date, err := w.next(now)
+	if err != nil {
+		return 0, err
+	}
+	return date.Sub(now), nil
+}
+
+func (w *everyWeeks) next(after time.Time) (time.Time, error) {
+	after = after.In(w.d.location())
+	year, month, day := after.Date()
+	for i := 0; i <= 7*(w.every+1); i++ {
+		date := w.d.on(year, month, day+i)
+		if date.Weekday() != w.day || !date.After(after) {
+			continue
+		}
+		y, m, d := date.Date()
+		if (weekOf(y, m, d)-w.anchor)%w.every == 0 {
+			return date, nil
+		}
+	}
+	return time.Time{}, errNoNextRun
+}
+
+// Next implements Schedule.
+func (w *everyWeeks) Next(after time.Time) time.Time {
+	date, _ := w.next(after)
+	return date
+}
+
+func (w *everyWeeks) description() string {
+	return "every " + strconv.Itoa(w.every) + " weeks on " + w.day.String() + " at " + w.d.timeString()
+}
+
+// Weeks sets the job to run every n weeks, where n was defined in the Every
+// function, on Monday unless OnWeekday says otherwise. Weeks start on Monday
+// and are counted from a fixed reference week, so the cycle is stable across
+// restarts; FromWeek moves it.
+func (j *Job) Weeks() *Job {
+	if j.err != nil {
+		return j
+	}
+	r, ok := j.schedule.(*recurrent)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	if r.units < 1 {
+		j.err = errors.New("cannot set recurrent time with 0")
+		return j
+	}
+	j.schedule = &everyWeeks{every: r.units, day: time.Monday}
+	return j
+}
+
+// Week sets the job to run at the start of every calendar week, at midnight
+// on Monday unless Starting and At say otherwise:
+// Every().Week().Starting(time.Sunday).At("06:00").
+func (j *Job) Week() *Job {
+	j.dayOfWeek(time.Monday)
+	if w, ok := j.schedule.(*weekly); ok {
+		w.week = true
+	}
+	return j
+}
+
+// Starting sets the day calendar weeks start on for a job set with Week or
+// Weeks, which then runs on that day.
+func (j *Job) Starting(day time.Weekday) *Job {
+	if j.err != nil {
+		return j
+	}
+	if day < time.Sunday || day > time.Saturday {
+		j.err = errors.New("bad weekday")
+		return j
+	}
+	switch s := j.schedule.(type) {
+	case *weekly:
+		if !s.week {
+			j.err = errors.New("bad function chaining")
+			return j
+		}
+		s.day = day
+	case *everyWeeks:
+		s.day = day
+	default:
+		j.err = errors.New("bad function chaining")
+	}
+	return j
+}
+
+// OnWeekday sets the day of the week a job running every n weeks runs on.
+func (j *Job) OnWeekday(day time.Weekday) *Job {
+	if j.err != nil {
+		return j
+	}
+	w, ok := j.schedule.(*everyWeeks)
+	if !ok || day < time.Sunday || day > time.Saturday {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	w.day = day
+	return j
+}
+
+// FromWeek anchors the cycle of a job running every n weeks to the week, from
+// Monday to Sunday, that contains t: Every(2).Weeks().FromWeek(t) runs in that
+// week, two weeks later and so on.
+func (j *Job) FromWeek(t time.Time) *Job {
+	if j.err != nil {
+		return j
+	}
+	w, ok := j.schedule.(*everyWeeks)
+	if !ok {
+		j.err = errors.New("bad function chaining")
+		return j
+	}
+	w.anchor = weekOf(t.Date())
+	return j
+}
+
+// rota runs on several weekdays, each at a time of its own, e.g.
+// Every().Monday().At("08:00").Friday().At("14:00"). At sets the time of the
+// last day added, and all the days run in the location of the last one.
+type rota []*weekly
+
+func (r rota) clock() *daily {
+	return &r[len(r)-1].d
+}
+
+func (r rota) nextRun(now time.Time) (time.Duration, error) {
+	date, _ := r.next(now)
+	return date.Sub(now), nil
+}
+
+func (r rota) next(after time.Time) (time.Time, error) {
+	loc := r.clock().location()
+	after = after.In(loc)
+	year, month, day := after.Date()
+	var earliest time.Time
+	for _, w := range r {
+		d := w.d
+		d.loc = loc
+		days := (int(w.day) - int(after.Weekday()) + 7) % 7
+		date := d.on(year, month, day+days)
+		if !date.After(after) {
+			date = d.on(year, month, day+days+7)
+		}
+		if earliest.IsZero() || date.Before(earliest) {
+			earliest = date
+		}
+	}
+	return earliest, nil
+}
+
+// Next implements Schedule.
+func (r rota) Next(after time.Time) time.Time {
+	date, _ := r.next(after)
+	return date
+}
+
+func (r rota) description() string {
+	days := make([]string, len(r))
+	for i, w := range r {
+		days[i] = w.day.String() + " at " + w.d.timeString()
+	}
+	return "every " + strings.Join(days, " and ")
+}
+
+// human describes the rota as humanize does.
+func (r rota) human() string {
+	days := make([]string, len(r))
+	for i, w := range r {
+		d := w.d
+		d.loc = nil
+		days[i] = w.day.String() + "s at " + d.human()
+	}
+	desc := strings.Join(days, " and ")
+	if loc := r.clock().loc; loc != nil && loc != time.Local {
+		desc += " " + loc.String()
+	}
+	return desc
+}
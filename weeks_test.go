@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeekOf(t *testing.T) {
+	assert.Equal(t, 0, weekOf(1970, time.January, 5))
+	assert.Equal(t, 0, weekOf(1970, time.January, 11))
+	assert.Equal(t, 1, weekOf(1970, time.January, 12))
+	assert.Equal(t, -1, weekOf(1970, time.January, 4))
+	assert.Equal(t, -1, weekOf(1969, time.December, 29))
+	assert.Equal(t, -2, weekOf(1969, time.December, 28))
+}
+
+func TestEveryNWeeks(t *testing.T) {
+	payday := time.Date(2015, 6, 5, 0, 0, 0, 0, time.Local)
+	job := Every(2).Weeks().OnWeekday(time.Friday).At("17:00").FromWeek(payday)
+	assert.Equal(t, date(2015, 6, 5, 17, 0), job.Next(date(2015, 6, 1, 0, 0)))
+	assert.Equal(t, date(2015, 6, 19, 17, 0), job.Next(date(2015, 6, 5, 17, 0)))
+	assert.Equal(t, date(2015, 6, 19, 17, 0), job.Next(date(2015, 6, 8, 0, 0)))
+	assert.Equal(t, date(2015, 5, 22, 17, 0), job.Next(date(2015, 5, 20, 0, 0)))
+	assert.Equal(t, "every 2 weeks on Friday at 17:00:00", job.Description())
+
+	// Sunday ends the anchored week.
+	job = Every(2).Weeks().OnWeekday(time.Sunday).FromWeek(payday)
+	assert.Equal(t, date(2015, 6, 7, 0, 0), job.Next(date(2015, 6, 1, 0, 0)))
+	assert.Equal(t, date(2015, 6, 21, 0, 0), job.Next(date(2015, 6, 7, 0, 0)))
+}
+
+func TestEveryNWeeksDefaults(t *testing.T) {
+	job := Every(3).Weeks()
+	next := []time.Time{job.Next(time.Now())}
+	next = append(next, job.Next(next[0]))
+	assert.Equal(t, time.Monday, next[0].Weekday())
+	assert.Equal(t, next[0].AddDate(0, 0, 21), next[1])
+	y, m, d := next[0].Date()
+	assert.Equal(t, 0, weekOf(y, m, d)%3)
+}
+
+func TestCalendarBoundaries(t *testing.T) {
+	job := Every().Week()
+	assert.Equal(t, date(2015, 6, 8, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	job = Every().Week().Starting(time.Sunday).At("06:00")
+	assert.Equal(t, date(2015, 6, 7, 6, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 6, 14, 6, 0), job.Next(date(2015, 6, 7, 6, 0)))
+	assert.Equal(t, "every Sunday at 06:00:00", job.Description())
+
+	job = Every(2).Weeks().Starting(time.Saturday).FromWeek(date(2015, 6, 1, 0, 0))
+	assert.Equal(t, date(2015, 6, 6, 0, 0), job.Next(date(2015, 6, 3, 12, 0)))
+
+	job = Every().Month().FirstDay().At("00:15")
+	assert.Equal(t, date(2015, 7, 1, 0, 15), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 8, 1, 0, 15), job.Next(date(2015, 7, 1, 0, 15)))
+}
+
+func TestBadWeeks(t *testing.T) {
+	for _, job := range []*Job{
+		Every(0).Weeks(),
+		Every().Weeks(),
+		Every().Day().OnWeekday(time.Friday),
+		Every(2).Weeks().OnWeekday(time.Weekday(7)),
+		Every().Monday().FromWeek(time.Now()),
+		Every(1).Week(),
+		Every().Day().Starting(time.Monday),
+		Every().Friday().Starting(time.Monday),
+		Every().Week().Starting(time.Weekday(-1)),
+	} {
+		_, err := job.Run(test)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestRota(t *testing.T) {
+	// June 3, 2015 was a Wednesday.
+	job := Every().Monday().At("08:00").Friday().At("14:00")
+	assert.Nil(t, job.err)
+	assert.Equal(t, date(2015, 6, 5, 14, 0), job.Next(date(2015, 6, 3, 12, 0)))
+	assert.Equal(t, date(2015, 6, 8, 8, 0), job.Next(date(2015, 6, 5, 14, 0)))
+	assert.Equal(t, date(2015, 6, 8, 8, 0), job.Next(date(2015, 6, 8, 7, 0)))
+	assert.Equal(t, "every Monday at 08:00:00 and Friday at 14:00:00", job.Description())
+	assert.Equal(t, "Mondays at 08:00 and Fridays at 14:00", job.String())
+
+	job = Every().Wednesday().At("09:00").Wednesday().At("17:00").Timezone("UTC")
+	from := time.Date(2015, 6, 3, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2015, 6, 3, 17, 0, 0, 0, time.UTC), job.Next(from))
+	assert.Equal(t, time.Date(2015, 6, 10, 9, 0, 0, 0, time.UTC), job.Next(from.Add(6*time.Hour)))
+	assert.Equal(t, "Wednesdays at 09:00 and Wednesdays at 17:00 UTC", job.String())
+
+	clone := job.Clone().Thursday().At("10:00")
+	assert.Len(t, job.schedule, 2)
+	assert.Len(t, clone.schedule, 3)
+
+	_, err := Every().Monday().At("08:00").Friday().At("25:00").Run(test)
+	assert.NotNil(t, err)
+}
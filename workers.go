@@ -0,0 +1,130 @@
+package scheduler
+
+import "sync"
+
+// WorkerStats describes the worker pool of a scheduler, see WithWorkers.
+type WorkerStats struct {
+	// Workers is the size of the pool.
+	Workers int
+	// Busy is how many workers are executing a run.
+	Busy int
+	// Queued is how many runs are waiting for a worker.
+	Queued int
+	// Delayed counts the runs that found every worker busy.
+	Delayed int
+}
+
+// pool executes the runs of a scheduler's jobs on a fixed number of
+// goroutines. Its queue needs no bound: a job has at most one run in flight,
+// so it never holds more runs than there are jobs.
+type pool struct {
+	size    int
+	tasks   []func()
+	busy    int
+	delayed int
+	closed  bool
+	cond    *sync.Cond
+	sync.Mutex
+}
+
+func newPool(size int) *pool {
+	p := &pool{size: size}
+	p.cond = sync.NewCond(&p.Mutex)
+	for i := 0; i < size; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// submit queues a task for the next idle worker.
+func (p *pool) submit(task func()) {
+	p.Lock()
+	if p.busy+len(p.tasks) >= p.size {
+		p.delayed++
+	}
+	p.tasks = append(p.tasks, task)
+	p.Unlock()
+	p.cond.Signal()
+}
+
+func (p *pool) work() {
+	p.Lock()
+	defer p.Unlock()
+
+	for {
+		for len(p.tasks) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if len(p.tasks) == 0 {
+			return
+		}
+		task := p.tasks[0]
+		p.tasks[0] = nil
+		p.tasks = p.tasks[1:]
+		p.busy++
+		p.Unlock()
+		task()
+		p.Lock()
+		p.busy--
+	}
+}
+
+// close lets the workers exit once the queue is empty.
+func (p *pool) close() {
+	p.Lock()
+	p.closed = true
+	p.Unlock()
+	p.cond.Broadcast()
+}
+
+func (p *pool) stats() WorkerStats {
+	p.Lock()
+	defer p.Unlock()
+
+	return WorkerStats{Workers: p.size, Busy: p.busy, Queued: len(p.tasks), Delayed: p.delayed}
+}
+
+// WithWorkers makes the scheduler execute the runs of its jobs on a pool of n
+// goroutines rather than one new goroutine per run, which bounds the memory
+// and CPU that bursts of due jobs can take. Runs wait in order for a free
+// worker; their context, and so their Timeout, starts when one picks them up.
+// Inline jobs are not affected. WithWorkers(0) goes back to a goroutine per
+// run; runs already queued still execute on the old pool.
+func (s *Scheduler) WithWorkers(n int) *Scheduler {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.workers != nil {
+		s.workers.close()
+		s.workers = nil
+	}
+	if n > 0 {
+		s.workers = newPool(n)
+	}
+	return s
+}
+
+// Workers returns the state of the scheduler's worker pool, which is zero if
+// it has none.
+func (s *Scheduler) Workers() WorkerStats {
+	s.Lock()
+	p := s.workers
+	s.Unlock()
+
+	if p == nil {
+		return WorkerStats{}
+	}
+	return p.stats()
+}
+
+// pool returns the worker pool that executes the job's runs, if any.
+func (j *Job) pool() *pool {
+	if j.scheduler == nil {
+		return nil
+	}
+	s := j.scheduler
+	s.Lock()
+	defer s.Unlock()
+
+	return s.workers
+}
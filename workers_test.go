@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithWorkers(t *testing.T) {
+	s := NewScheduler().WithWorkers(1)
+	defer s.WithWorkers(0)
+	assert.Equal(t, WorkerStats{Workers: 1}, s.Workers())
+
+	started := make(chan string, 2)
+	release := make(chan bool)
+	job := func(name string) func() {
+		return func() {
+			started <- name
+			<-release
+		}
+	}
+	a, err := s.Every(1).Hours().Run(job("a"))
+	assert.Nil(t, err)
+	defer a.Stop()
+	assert.Equal(t, "a", <-started)
+
+	b, err := s.Every(1).Hours().Run(job("b"))
+	assert.Nil(t, err)
+	defer b.Stop()
+	for s.Workers().Queued == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, WorkerStats{Workers: 1, Busy: 1, Queued: 1, Delayed: 1}, s.Workers())
+	assert.True(t, b.IsRunning())
+	assert.Len(t, started, 0)
+
+	release <- true
+	assert.Equal(t, "b", <-started)
+	release <- true
+	for b.IsRunning() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, WorkerStats{Workers: 1, Delayed: 1}, s.Workers())
+}
+
+func TestWithoutWorkers(t *testing.T) {
+	s := NewScheduler().WithWorkers(2).WithWorkers(0)
+	assert.Equal(t, WorkerStats{}, s.Workers())
+	c := make(chan bool)
+	job, err := s.Every(1).Hours().Run(func() { c <- true })
+	assert.Nil(t, err)
+	defer job.Stop()
+	assert.True(t, <-c)
+}